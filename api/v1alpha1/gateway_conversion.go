@@ -0,0 +1,232 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+// ConversionDataAnnotation holds the parts of a v1alpha2 spec that can't be represented in
+// v1alpha1, such as additional listeners, so that a round trip through v1alpha1 is lossless
+const ConversionDataAnnotation = "conversion.fetchfy.ai/v1alpha2-spec"
+
+// ConvertTo converts this Gateway to the Hub version (v1alpha2).
+func (src *Gateway) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1alpha2.Gateway)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha2 Gateway but got %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = convertSpecTo(&src.Spec)
+	dst.Status = convertStatusTo(&src.Status)
+
+	data, ok := dst.Annotations[ConversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(dst.Annotations, ConversionDataAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	var restored v1alpha2.GatewaySpec
+	if err := json.Unmarshal([]byte(data), &restored); err != nil {
+		return fmt.Errorf("failed to restore v1alpha2 spec from annotation %s: %w", ConversionDataAnnotation, err)
+	}
+	dst.Spec = mergeRestoredSpec(&restored, &dst.Spec, dst.Namespace)
+
+	return nil
+}
+
+// ConvertFrom converts the Hub version (v1alpha2) to this version.
+func (dst *Gateway) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1alpha2.Gateway)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha2 Gateway but got %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	delete(dst.Annotations, ConversionDataAnnotation)
+	dst.Spec = convertSpecFrom(&src.Spec, src.Namespace)
+	dst.Status = convertStatusFrom(&src.Status)
+
+	// Keep what v1alpha1 can't represent, so converting back restores it
+	if roundTrip := convertSpecTo(&dst.Spec); !equality.Semantic.DeepEqual(roundTrip, src.Spec) {
+		data, err := json.Marshal(src.Spec)
+		if err != nil {
+			return fmt.Errorf("failed to store v1alpha2 spec in annotation %s: %w", ConversionDataAnnotation, err)
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[ConversionDataAnnotation] = string(data)
+	}
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	return nil
+}
+
+// convertSpecTo converts a v1alpha1 spec into a v1alpha2 spec with a single listener
+func convertSpecTo(src *GatewaySpec) v1alpha2.GatewaySpec {
+	listener := v1alpha2.Listener{
+		Name:     v1alpha2.DefaultListenerName,
+		Port:     src.MCPPort,
+		Protocol: v1alpha2.ProtocolHTTP,
+	}
+	if src.EnableTLS {
+		listener.Protocol = v1alpha2.ProtocolHTTPS
+	}
+	if src.TLSSecretRef != "" {
+		listener.TLS = &v1alpha2.ListenerTLS{
+			CertificateRef: v1alpha2.SecretReference{Name: src.TLSSecretRef},
+		}
+	}
+
+	dst := v1alpha2.GatewaySpec{
+		Listeners:    []v1alpha2.Listener{listener},
+		DrainTimeout: src.DrainTimeout.DeepCopy(),
+	}
+
+	if !equality.Semantic.DeepEqual(src.ServiceSelector, metav1.LabelSelector{}) {
+		dst.Discovery = &v1alpha2.GatewayDiscovery{ServiceSelector: src.ServiceSelector.DeepCopy()}
+	}
+
+	if src.Auth != nil {
+		dst.Auth = &v1alpha2.GatewayAuth{
+			Type:                 v1alpha2.AuthType(src.Auth.Type),
+			AuthorizationServers: append([]string(nil), src.Auth.AuthorizationServers...),
+			Scopes:               append([]string(nil), src.Auth.Scopes...),
+		}
+	}
+
+	return dst
+}
+
+// convertSpecFrom converts a v1alpha2 spec into a v1alpha1 spec. Only the first listener
+// is represented; a certificate in the Gateway's own namespace is referenced by name.
+func convertSpecFrom(src *v1alpha2.GatewaySpec, namespace string) GatewaySpec {
+	dst := GatewaySpec{
+		DrainTimeout: src.DrainTimeout.DeepCopy(),
+	}
+
+	if len(src.Listeners) > 0 {
+		listener := src.Listeners[0]
+		dst.MCPPort = listener.Port
+		dst.EnableTLS = listener.Protocol == v1alpha2.ProtocolHTTPS
+		if listener.TLS != nil {
+			ref := listener.TLS.CertificateRef
+			if ref.Namespace == "" || ref.Namespace == namespace {
+				dst.TLSSecretRef = ref.Name
+			}
+		}
+	}
+
+	if src.Discovery != nil && src.Discovery.ServiceSelector != nil {
+		dst.ServiceSelector = *src.Discovery.ServiceSelector.DeepCopy()
+	}
+
+	if src.Auth != nil {
+		dst.Auth = &GatewayAuth{
+			Type:                 AuthType(src.Auth.Type),
+			AuthorizationServers: append([]string(nil), src.Auth.AuthorizationServers...),
+			Scopes:               append([]string(nil), src.Auth.Scopes...),
+		}
+	}
+
+	return dst
+}
+
+// mergeRestoredSpec applies the fields represented in v1alpha1 on top of a spec restored
+// from the conversion annotation, so that changes made through v1alpha1 take precedence
+func mergeRestoredSpec(restored, converted *v1alpha2.GatewaySpec, namespace string) v1alpha2.GatewaySpec {
+	merged := *restored.DeepCopy()
+
+	if len(merged.Listeners) == 0 {
+		merged.Listeners = converted.Listeners
+	} else {
+		first := &merged.Listeners[0]
+		convertedFirst := converted.Listeners[0]
+		first.Port = convertedFirst.Port
+		first.Protocol = convertedFirst.Protocol
+		switch {
+		case convertedFirst.TLS != nil && first.TLS != nil &&
+			first.TLS.CertificateRef.Name == convertedFirst.TLS.CertificateRef.Name:
+			// Unchanged, keep the namespace of the reference
+		case convertedFirst.TLS != nil:
+			first.TLS = convertedFirst.TLS
+		case first.TLS != nil && first.TLS.CertificateRef.Namespace != "" && first.TLS.CertificateRef.Namespace != namespace:
+			// A certificate in another namespace has no v1alpha1 representation, keep it
+		default:
+			first.TLS = nil
+		}
+	}
+
+	if converted.Discovery != nil {
+		if merged.Discovery == nil {
+			merged.Discovery = &v1alpha2.GatewayDiscovery{}
+		}
+		merged.Discovery.ServiceSelector = converted.Discovery.ServiceSelector
+	} else if merged.Discovery != nil {
+		merged.Discovery.ServiceSelector = nil
+		if equality.Semantic.DeepEqual(*merged.Discovery, v1alpha2.GatewayDiscovery{}) {
+			merged.Discovery = nil
+		}
+	}
+
+	merged.Auth = converted.Auth
+	merged.DrainTimeout = converted.DrainTimeout
+
+	return merged
+}
+
+// convertStatusTo converts a v1alpha1 status into a v1alpha2 status
+func convertStatusTo(src *GatewayStatus) v1alpha2.GatewayStatus {
+	dst := v1alpha2.GatewayStatus{
+		Conditions:        append([]metav1.Condition(nil), src.Conditions...),
+		ServicesTotal:     src.ServicesTotal,
+		ServicesAvailable: src.ServicesAvailable,
+		Address:           src.Address,
+	}
+	for _, svc := range src.MCPServices {
+		dst.MCPServices = append(dst.MCPServices, v1alpha2.MCPServiceInfo(svc))
+	}
+	return dst
+}
+
+// convertStatusFrom converts a v1alpha2 status into a v1alpha1 status
+func convertStatusFrom(src *v1alpha2.GatewayStatus) GatewayStatus {
+	dst := GatewayStatus{
+		Conditions:        append([]metav1.Condition(nil), src.Conditions...),
+		ServicesTotal:     src.ServicesTotal,
+		ServicesAvailable: src.ServicesAvailable,
+		Address:           src.Address,
+	}
+	for _, svc := range src.MCPServices {
+		dst.MCPServices = append(dst.MCPServices, MCPServiceInfo(svc))
+	}
+	return dst
+}
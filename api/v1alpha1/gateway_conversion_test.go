@@ -0,0 +1,138 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+var _ = Describe("Gateway conversion", func() {
+	var v1alpha1Gateway *Gateway
+
+	BeforeEach(func() {
+		v1alpha1Gateway = &Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+			Spec: GatewaySpec{
+				MCPPort:         8443,
+				EnableTLS:       true,
+				TLSSecretRef:    "gateway-tls",
+				ServiceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"mcp-enabled": "true"}},
+				Auth:            &GatewayAuth{Type: AuthTypeOAuth2, Scopes: []string{"mcp:tools"}},
+				DrainTimeout:    &metav1.Duration{Duration: time.Minute},
+			},
+			Status: GatewayStatus{
+				Address:           ":8443",
+				MCPServices:       []MCPServiceInfo{{Name: "calculator", Namespace: "tools", Status: "Available"}},
+				ServicesTotal:     1,
+				ServicesAvailable: 1,
+			},
+		}
+	})
+
+	It("should convert a v1alpha1 Gateway into a single listener", func() {
+		hub := &v1alpha2.Gateway{}
+		Expect(v1alpha1Gateway.ConvertTo(hub)).To(Succeed())
+
+		Expect(hub.Spec.Listeners).To(Equal([]v1alpha2.Listener{{
+			Name:     v1alpha2.DefaultListenerName,
+			Port:     8443,
+			Protocol: v1alpha2.ProtocolHTTPS,
+			TLS: &v1alpha2.ListenerTLS{
+				CertificateRef: v1alpha2.SecretReference{Name: "gateway-tls"},
+			},
+		}}))
+		Expect(hub.Spec.Discovery.ServiceSelector.MatchLabels).To(HaveKeyWithValue("mcp-enabled", "true"))
+		Expect(hub.Spec.Auth.Type).To(Equal(v1alpha2.AuthTypeOAuth2))
+		Expect(hub.Spec.DrainTimeout.Duration).To(Equal(time.Minute))
+		Expect(hub.Status.MCPServices).To(HaveLen(1))
+	})
+
+	It("should round trip a v1alpha1 Gateway without annotations", func() {
+		hub := &v1alpha2.Gateway{}
+		Expect(v1alpha1Gateway.ConvertTo(hub)).To(Succeed())
+
+		converted := &Gateway{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted).To(Equal(v1alpha1Gateway))
+	})
+
+	It("should keep v1alpha2 fields that v1alpha1 can't represent", func() {
+		hub := &v1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+			Spec: v1alpha2.GatewaySpec{
+				Listeners: []v1alpha2.Listener{
+					{
+						Name:     "public",
+						Port:     8443,
+						Protocol: v1alpha2.ProtocolHTTPS,
+						TLS: &v1alpha2.ListenerTLS{
+							CertificateRef: v1alpha2.SecretReference{Name: "wildcard", Namespace: "certs"},
+						},
+					},
+					{Name: "internal", Port: 8080, Protocol: v1alpha2.ProtocolHTTP},
+				},
+				Discovery: &v1alpha2.GatewayDiscovery{Namespaces: []string{"tools", "agents"}},
+				Routing: &v1alpha2.GatewayRouting{
+					AllowedRoutes: &v1alpha2.RouteNamespaces{From: v1alpha2.NamespacesFromAll},
+				},
+			},
+		}
+
+		converted := &Gateway{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.Spec.MCPPort).To(Equal(int32(8443)))
+		Expect(converted.Spec.EnableTLS).To(BeTrue())
+		Expect(converted.Spec.TLSSecretRef).To(BeEmpty())
+		Expect(converted.Annotations).To(HaveKey(ConversionDataAnnotation))
+
+		restored := &v1alpha2.Gateway{}
+		Expect(converted.ConvertTo(restored)).To(Succeed())
+		Expect(restored).To(Equal(hub))
+
+		By("applying changes made through v1alpha1")
+		converted.Spec.MCPPort = 9443
+		Expect(converted.ConvertTo(restored)).To(Succeed())
+		Expect(restored.Spec.Listeners[0].Port).To(Equal(int32(9443)))
+		Expect(restored.Spec.Listeners[0].TLS.CertificateRef.Namespace).To(Equal("certs"))
+		Expect(restored.Spec.Listeners[1]).To(Equal(hub.Spec.Listeners[1]))
+		Expect(restored.Spec.Routing).To(Equal(hub.Spec.Routing))
+	})
+
+	It("should drop a certificate removed through v1alpha1", func() {
+		hub := &v1alpha2.Gateway{}
+		Expect(v1alpha1Gateway.ConvertTo(hub)).To(Succeed())
+		hub.Spec.Listeners[0].TLS.CertificateRef.Namespace = "default"
+		hub.Spec.Discovery.Namespaces = []string{"tools"}
+
+		converted := &Gateway{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		converted.Spec.EnableTLS = false
+		converted.Spec.TLSSecretRef = ""
+
+		restored := &v1alpha2.Gateway{}
+		Expect(converted.ConvertTo(restored)).To(Succeed())
+		Expect(restored.Spec.Listeners[0].TLS).To(BeNil())
+		Expect(restored.Spec.Listeners[0].Protocol).To(Equal(v1alpha2.ProtocolHTTP))
+		Expect(restored.Spec.Discovery.Namespaces).To(ConsistOf("tools"))
+	})
+})
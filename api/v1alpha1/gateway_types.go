@@ -39,19 +39,62 @@ type MCPServiceInfo struct {
 
 	// LastUpdated is the timestamp of the last update
 	LastUpdated metav1.Time `json:"lastUpdated"`
+
+	// ReadyEndpoints is the number of ready endpoints of the service, unset if they aren't tracked
+	// +optional
+	ReadyEndpoints *int32 `json:"readyEndpoints,omitempty"`
+
+	// Message explains the status, e.g. why the service can't be routed to
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Cluster is the name of the remote cluster the service runs in, empty for local services
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// AuthType identifies how MCP clients authenticate against the gateway
+type AuthType string
+
+const (
+	// AuthTypeNone disables client authentication
+	AuthTypeNone AuthType = "None"
+
+	// AuthTypeOAuth2 requires clients to present an OAuth 2.0 bearer token
+	AuthTypeOAuth2 AuthType = "OAuth2"
+)
+
+// GatewayAuth configures client authentication for the MCP gateway
+type GatewayAuth struct {
+	// Type selects the authentication scheme required from MCP clients
+	// +kubebuilder:validation:Enum=None;OAuth2
+	// +kubebuilder:default=None
+	// +optional
+	Type AuthType `json:"type,omitempty"`
+
+	// AuthorizationServers lists the issuer URLs of the OAuth 2.0 authorization servers
+	// that clients should obtain tokens from
+	// +optional
+	AuthorizationServers []string `json:"authorizationServers,omitempty"`
+
+	// Scopes lists the OAuth 2.0 scopes supported by the gateway
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 // GatewaySpec defines the desired state of Gateway.
 type GatewaySpec struct {
-	// MCPPort defines the port where the MCP gateway is available
-	// +kubebuilder:validation:Required
+	// MCPPort defines the port where the MCP gateway is available. Defaults to 8080.
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=65535
-	MCPPort int32 `json:"mcpPort"`
+	// +kubebuilder:default=8080
+	// +optional
+	MCPPort int32 `json:"mcpPort,omitempty"`
 
-	// ServiceSelector defines the label selector to identify MCP-enabled services
-	// +kubebuilder:validation:Required
-	ServiceSelector metav1.LabelSelector `json:"serviceSelector"`
+	// ServiceSelector defines the label selector to identify MCP-enabled services.
+	// Defaults to selecting services labelled mcp-enabled=true.
+	// +optional
+	ServiceSelector metav1.LabelSelector `json:"serviceSelector,omitempty"`
 
 	// EnableTLS indicates whether TLS should be enabled for the MCP gateway
 	// +optional
@@ -60,6 +103,20 @@ type GatewaySpec struct {
 	// TLSSecretRef refers to the secret containing the TLS certificate and private key
 	// +optional
 	TLSSecretRef string `json:"tlsSecretRef,omitempty"`
+
+	// Auth configures how MCP clients authenticate against the gateway
+	// +optional
+	Auth *GatewayAuth `json:"auth,omitempty"`
+
+	// DrainTimeout is how long a listener replaced after a port or TLS change may keep
+	// serving in-flight requests and streaming sessions before it is closed. Defaults to 30s.
+	// +optional
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+}
+
+// AuthEnabled reports whether the gateway requires clients to authenticate
+func (in *GatewaySpec) AuthEnabled() bool {
+	return in.Auth != nil && in.Auth.Type != "" && in.Auth.Type != AuthTypeNone
 }
 
 // GatewayStatus defines the observed state of Gateway.
@@ -72,6 +129,14 @@ type GatewayStatus struct {
 	// +optional
 	MCPServices []MCPServiceInfo `json:"mcpServices,omitempty"`
 
+	// ServicesTotal is the number of registered MCP services, including those left out of mcpServices
+	// +optional
+	ServicesTotal int32 `json:"servicesTotal,omitempty"`
+
+	// ServicesAvailable is the number of available MCP services
+	// +optional
+	ServicesAvailable int32 `json:"servicesAvailable,omitempty"`
+
 	// Address where the MCP gateway is available
 	// +optional
 	Address string `json:"address,omitempty"`
@@ -81,7 +146,7 @@ type GatewayStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=mcpgw
 // +kubebuilder:printcolumn:name="Port",type="integer",JSONPath=".spec.mcpPort",description="MCP Gateway port"
-// +kubebuilder:printcolumn:name="Services",type="integer",JSONPath=".status.mcpServices",description="Number of registered services"
+// +kubebuilder:printcolumn:name="Services",type="integer",JSONPath=".status.servicesTotal",description="Number of registered services"
 // +kubebuilder:printcolumn:name="Address",type="string",JSONPath=".status.address",description="Gateway address"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
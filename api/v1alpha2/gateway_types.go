@@ -0,0 +1,496 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultListenerName is the name of the listener converted from a v1alpha1 Gateway
+const DefaultListenerName = "mcp"
+
+// MCPServiceInfo provides information about a registered MCP service
+type MCPServiceInfo struct {
+	// Name is the name of the service
+	Name string `json:"name"`
+
+	// Namespace is the namespace where the service is deployed
+	Namespace string `json:"namespace"`
+
+	// Type indicates whether this is a tool or agent
+	Type string `json:"type"`
+
+	// Endpoint is the MCP endpoint for this service
+	Endpoint string `json:"endpoint"`
+
+	// Status indicates the current status of this service
+	Status string `json:"status"`
+
+	// LastUpdated is the timestamp of the last update
+	LastUpdated metav1.Time `json:"lastUpdated"`
+
+	// ReadyEndpoints is the number of ready endpoints of the service, unset if they aren't tracked
+	// +optional
+	ReadyEndpoints *int32 `json:"readyEndpoints,omitempty"`
+
+	// Message explains the status, e.g. why the service can't be routed to
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Cluster is the name of the remote cluster the service runs in, empty for local services
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// ListenerProtocol is the protocol a listener accepts MCP connections with
+// +kubebuilder:validation:Enum=HTTP;HTTPS
+type ListenerProtocol string
+
+const (
+	// ProtocolHTTP serves MCP over plain HTTP
+	ProtocolHTTP ListenerProtocol = "HTTP"
+
+	// ProtocolHTTPS serves MCP over HTTP with TLS termination at the gateway
+	ProtocolHTTPS ListenerProtocol = "HTTPS"
+)
+
+// SecretReference refers to a Secret, optionally in another namespace
+type SecretReference struct {
+	// Name is the name of the Secret
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Secret. Defaults to the Gateway's namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ListenerTLS configures TLS termination for a listener
+type ListenerTLS struct {
+	// CertificateRef refers to a kubernetes.io/tls Secret holding the certificate and private key
+	CertificateRef SecretReference `json:"certificateRef"`
+}
+
+// Listener defines a port on which the gateway accepts MCP connections
+// +kubebuilder:validation:XValidation:rule="self.protocol != 'HTTPS' || has(self.tls)",message="tls is required when protocol is HTTPS"
+type Listener struct {
+	// Name uniquely identifies the listener within the Gateway
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Port is the port the listener binds to
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Protocol is the protocol the listener accepts. Defaults to HTTP.
+	// +kubebuilder:default=HTTP
+	// +optional
+	Protocol ListenerProtocol `json:"protocol,omitempty"`
+
+	// TLS configures TLS termination. Required when the protocol is HTTPS.
+	// +optional
+	TLS *ListenerTLS `json:"tls,omitempty"`
+
+	// Auth configures how MCP clients authenticate on this listener. Defaults to spec.auth.
+	// +optional
+	Auth *GatewayAuth `json:"auth,omitempty"`
+
+	// Services restricts the MCP services visible through this listener. All services are visible when unset.
+	// +optional
+	Services *ListenerServices `json:"services,omitempty"`
+}
+
+// ServiceType is the type of an MCP service
+// +kubebuilder:validation:Enum=tool;agent
+type ServiceType string
+
+// ListenerServices restricts the MCP services visible through a listener.
+// A service is visible if it matches all of the set fields.
+type ListenerServices struct {
+	// Types limits the listener to tools or agents
+	// +listType=set
+	// +optional
+	Types []ServiceType `json:"types,omitempty"`
+
+	// Namespaces limits the listener to services in the listed namespaces
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Selector limits the listener to services whose labels match
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// DiscoverySource is a kind of object MCP servers are discovered from
+// +kubebuilder:validation:Enum=Service;Pod;Deployment
+type DiscoverySource string
+
+const (
+	// DiscoverySourceService discovers MCP servers from Services
+	DiscoverySourceService DiscoverySource = "Service"
+
+	// DiscoverySourcePod discovers MCP servers running in Pods without a Service, e.g. as sidecars
+	DiscoverySourcePod DiscoverySource = "Pod"
+
+	// DiscoverySourceDeployment discovers MCP servers from the pods of Deployments without a Service
+	DiscoverySourceDeployment DiscoverySource = "Deployment"
+)
+
+// GatewayDiscovery selects the services exposed through the gateway
+type GatewayDiscovery struct {
+	// Sources are the kinds of objects MCP servers are discovered from. Pods and Deployments
+	// need the mcp-enabled=true label and the mcp.fetchfy.ai/port annotation. Defaults to Service.
+	// +listType=set
+	// +optional
+	Sources []DiscoverySource `json:"sources,omitempty"`
+
+	// ServiceSelector selects MCP-enabled services by label.
+	// Defaults to selecting services labelled mcp-enabled=true.
+	// +optional
+	ServiceSelector *metav1.LabelSelector `json:"serviceSelector,omitempty"`
+
+	// Namespaces restricts discovery to the listed namespaces. All namespaces are searched when empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector restricts discovery to namespaces matching the selector
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// MaxStatusServices caps the number of services listed in status.mcpServices, so that the
+	// Gateway stays small on large clusters. The counts in the status always cover all services.
+	// Defaults to 100.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	MaxStatusServices *int32 `json:"maxStatusServices,omitempty"`
+
+	// Catalog publishes the full list of services in an MCPServiceCatalog with the Gateway's name
+	// +optional
+	Catalog bool `json:"catalog,omitempty"`
+}
+
+// DefaultMaxStatusServices is the default cap of the services listed in a Gateway's status
+const DefaultMaxStatusServices = 100
+
+// StatusServiceLimit returns the maximum number of services listed in the gateway's status
+func (in *GatewaySpec) StatusServiceLimit() int {
+	if in.Discovery == nil || in.Discovery.MaxStatusServices == nil {
+		return DefaultMaxStatusServices
+	}
+	return int(*in.Discovery.MaxStatusServices)
+}
+
+// DiscoverySources returns the kinds of objects the gateway's MCP servers are discovered from
+func (in *GatewaySpec) DiscoverySources() []DiscoverySource {
+	if in.Discovery == nil || len(in.Discovery.Sources) == 0 {
+		return []DiscoverySource{DiscoverySourceService}
+	}
+	return in.Discovery.Sources
+}
+
+// CatalogEnabled reports whether the full list of services is published in an MCPServiceCatalog
+func (in *GatewaySpec) CatalogEnabled() bool {
+	return in.Discovery != nil && in.Discovery.Catalog
+}
+
+// FromNamespaces selects the namespaces routes may attach from
+// +kubebuilder:validation:Enum=All;Same;Selector
+type FromNamespaces string
+
+const (
+	// NamespacesFromAll allows routes from all namespaces
+	NamespacesFromAll FromNamespaces = "All"
+
+	// NamespacesFromSame allows routes from the Gateway's namespace only
+	NamespacesFromSame FromNamespaces = "Same"
+
+	// NamespacesFromSelector allows routes from namespaces matching a selector
+	NamespacesFromSelector FromNamespaces = "Selector"
+)
+
+// RouteNamespaces defines the namespaces routes may attach from
+// +kubebuilder:validation:XValidation:rule="self.from != 'Selector' || has(self.selector)",message="selector is required when from is Selector"
+type RouteNamespaces struct {
+	// From selects where routes may attach from. Defaults to Same.
+	// +kubebuilder:default=Same
+	// +optional
+	From FromNamespaces `json:"from,omitempty"`
+
+	// Selector selects the namespaces routes may attach from when From is Selector
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// GatewayRouting configures how requests are routed to MCP services
+type GatewayRouting struct {
+	// AllowedRoutes restricts the namespaces from which routes may attach to the Gateway
+	// +optional
+	AllowedRoutes *RouteNamespaces `json:"allowedRoutes,omitempty"`
+}
+
+// AuthType identifies how MCP clients authenticate against the gateway
+type AuthType string
+
+const (
+	// AuthTypeNone disables client authentication
+	AuthTypeNone AuthType = "None"
+
+	// AuthTypeOAuth2 requires clients to present an OAuth 2.0 bearer token
+	AuthTypeOAuth2 AuthType = "OAuth2"
+)
+
+// GatewayAuth configures client authentication for the MCP gateway
+type GatewayAuth struct {
+	// Type selects the authentication scheme required from MCP clients
+	// +kubebuilder:validation:Enum=None;OAuth2
+	// +kubebuilder:default=None
+	// +optional
+	Type AuthType `json:"type,omitempty"`
+
+	// AuthorizationServers lists the issuer URLs of the OAuth 2.0 authorization servers
+	// that clients should obtain tokens from
+	// +optional
+	AuthorizationServers []string `json:"authorizationServers,omitempty"`
+
+	// Scopes lists the OAuth 2.0 scopes supported by the gateway
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// DefaultKubeconfigKey is the key of the kubeconfig in the Secret of a federated cluster
+const DefaultKubeconfigKey = "kubeconfig"
+
+// FederatedCluster is a remote cluster whose MCP services are exposed through the gateway
+type FederatedCluster struct {
+	// Name identifies the cluster. The cluster's services are registered as <name>.<service>.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// KubeconfigSecretRef refers to a Secret holding a kubeconfig for the cluster, in the Gateway's
+	// namespace or the operator's namespace. The kubeconfig's credentials must be embedded in it.
+	KubeconfigSecretRef SecretReference `json:"kubeconfigSecretRef"`
+
+	// KubeconfigKey is the key of the kubeconfig in the Secret. Defaults to "kubeconfig".
+	// +optional
+	KubeconfigKey string `json:"kubeconfigKey,omitempty"`
+
+	// Address is the URL of the ingress that routes MCP requests into the cluster, usually a
+	// Gateway of the cluster. Requests for a service are sent to the address joined with the
+	// service's endpoint in the cluster.
+	// +kubebuilder:validation:Pattern=`^https?://[^?#]+$`
+	Address string `json:"address"`
+}
+
+// GatewayFederation exposes the MCP services of remote clusters through the gateway
+type GatewayFederation struct {
+	// Clusters are the remote clusters whose MCP-enabled Services are exposed
+	// +kubebuilder:validation:MaxItems=32
+	// +listType=map
+	// +listMapKey=name
+	Clusters []FederatedCluster `json:"clusters"`
+}
+
+// GatewaySpec defines the desired state of Gateway.
+type GatewaySpec struct {
+	// Listeners defines the ports on which the gateway accepts MCP connections
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:XValidation:rule="self.all(l, self.exists_one(m, m.port == l.port))",message="listener ports must be unique"
+	// +listType=map
+	// +listMapKey=name
+	Listeners []Listener `json:"listeners"`
+
+	// Discovery selects the services exposed through the gateway
+	// +optional
+	Discovery *GatewayDiscovery `json:"discovery,omitempty"`
+
+	// Routing configures how requests are routed to MCP services
+	// +optional
+	Routing *GatewayRouting `json:"routing,omitempty"`
+
+	// Auth configures how MCP clients authenticate against the gateway
+	// +optional
+	Auth *GatewayAuth `json:"auth,omitempty"`
+
+	// DrainTimeout is how long a listener replaced after a port or TLS change may keep
+	// serving in-flight requests and streaming sessions before it is closed. Defaults to 30s.
+	// +optional
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+
+	// Federation exposes the MCP services of remote clusters through the gateway
+	// +optional
+	Federation *GatewayFederation `json:"federation,omitempty"`
+}
+
+// FederatedClusters returns the names of the remote clusters federated by the gateway
+func (in *GatewaySpec) FederatedClusters() []string {
+	if in.Federation == nil {
+		return nil
+	}
+	names := make([]string, 0, len(in.Federation.Clusters))
+	for _, cluster := range in.Federation.Clusters {
+		names = append(names, cluster.Name)
+	}
+	return names
+}
+
+// AuthEnabled reports whether the gateway requires clients to authenticate
+func (in *GatewaySpec) AuthEnabled() bool {
+	return in.Auth != nil && in.Auth.Type != "" && in.Auth.Type != AuthTypeNone
+}
+
+// AuthEnabled reports whether the listener requires clients to authenticate, given the gateway-wide auth
+func (in *Listener) AuthEnabled(gatewayAuth *GatewayAuth) bool {
+	auth := in.EffectiveAuth(gatewayAuth)
+	return auth != nil && auth.Type != "" && auth.Type != AuthTypeNone
+}
+
+// EffectiveAuth returns the listener's auth, falling back to the gateway-wide auth
+func (in *Listener) EffectiveAuth(gatewayAuth *GatewayAuth) *GatewayAuth {
+	if in.Auth != nil {
+		return in.Auth
+	}
+	return gatewayAuth
+}
+
+// ListenerStatus describes the observed state of a listener
+type ListenerStatus struct {
+	// Name is the name of the listener
+	Name string `json:"name"`
+
+	// Address is the address the listener is bound to, empty while it isn't serving
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// Services is the number of MCP services visible through the listener
+	Services int32 `json:"services"`
+
+	// Conditions represent the latest available observations of the listener's state
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ServiceTypeSummary counts the registered MCP services of a type
+type ServiceTypeSummary struct {
+	// Type is the service type, tool or agent
+	Type string `json:"type"`
+
+	// Total is the number of registered services of the type
+	Total int32 `json:"total"`
+
+	// Available is the number of available services of the type
+	Available int32 `json:"available"`
+}
+
+// FederatedClusterStatus describes the observed state of a federated cluster
+type FederatedClusterStatus struct {
+	// Name is the name of the cluster
+	Name string `json:"name"`
+
+	// Services is the number of MCP services registered from the cluster
+	Services int32 `json:"services"`
+
+	// Conditions represent the latest available observations of the cluster's connection
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// GatewayStatus defines the observed state of Gateway.
+type GatewayStatus struct {
+	// Conditions represent the latest available observations of Gateway's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Listeners reports the state of each listener
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Listeners []ListenerStatus `json:"listeners,omitempty"`
+
+	// MCPServices contains information about registered MCP services, ordered by namespace
+	// and name and capped at spec.discovery.maxStatusServices
+	// +optional
+	MCPServices []MCPServiceInfo `json:"mcpServices,omitempty"`
+
+	// ServicesTotal is the number of registered MCP services, including those left out of mcpServices
+	ServicesTotal int32 `json:"servicesTotal"`
+
+	// ServicesAvailable is the number of available MCP services
+	ServicesAvailable int32 `json:"servicesAvailable"`
+
+	// ServicesByType counts the registered MCP services of each type
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	ServicesByType []ServiceTypeSummary `json:"servicesByType,omitempty"`
+
+	// Catalog is the name of the MCPServiceCatalog listing all registered services, set
+	// when spec.discovery.catalog is enabled
+	// +optional
+	Catalog string `json:"catalog,omitempty"`
+
+	// Clusters reports the connection to each federated cluster
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Clusters []FederatedClusterStatus `json:"clusters,omitempty"`
+
+	// Address where the MCP gateway is available
+	// +optional
+	Address string `json:"address,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:scope=Namespaced,shortName=mcpgw
+// +kubebuilder:printcolumn:name="Port",type="integer",JSONPath=".spec.listeners[0].port",description="Port of the first listener"
+// +kubebuilder:printcolumn:name="Services",type="integer",JSONPath=".status.servicesTotal",description="Number of registered services"
+// +kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.servicesAvailable",description="Number of available services"
+// +kubebuilder:printcolumn:name="Address",type="string",JSONPath=".status.address",description="Gateway address"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Gateway is the Schema for the gateways API.
+type Gateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GatewaySpec   `json:"spec,omitempty"`
+	Status GatewayStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GatewayList contains a list of Gateway.
+type GatewayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Gateway `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Gateway{}, &GatewayList{})
+}
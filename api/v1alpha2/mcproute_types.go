@@ -0,0 +1,227 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ParentReference identifies a Gateway, and optionally one of its listeners, that a route attaches to
+type ParentReference struct {
+	// Name is the name of the Gateway
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Gateway. Defaults to the route's namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the name of the listener to attach to. The route attaches to all
+	// listeners of the Gateway when unset.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// PathMatchType specifies how a path is matched
+// +kubebuilder:validation:Enum=Exact;PathPrefix
+type PathMatchType string
+
+const (
+	// PathMatchExact matches the request path exactly
+	PathMatchExact PathMatchType = "Exact"
+
+	// PathMatchPathPrefix matches the request path segment by segment
+	PathMatchPathPrefix PathMatchType = "PathPrefix"
+)
+
+// PathMatch matches the request path
+type PathMatch struct {
+	// Type specifies how the path is matched. Defaults to PathPrefix.
+	// +kubebuilder:default=PathPrefix
+	// +optional
+	Type PathMatchType `json:"type,omitempty"`
+
+	// Value is the path to match, below /mcp/
+	// +kubebuilder:validation:MaxLength=1024
+	// +kubebuilder:validation:Pattern=`^/mcp/`
+	Value string `json:"value"`
+}
+
+// HeaderMatch matches an HTTP request header exactly
+type HeaderMatch struct {
+	// Name is the case-insensitive name of the header
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	Name string `json:"name"`
+
+	// Value is the value the header must have
+	// +kubebuilder:validation:MaxLength=4096
+	Value string `json:"value"`
+}
+
+// MCPRouteMatch matches MCP requests. A request matches if it matches all of the set fields.
+// +kubebuilder:validation:XValidation:rule="!has(self.tool) || !has(self.method) || self.method == 'tools/call'",message="tool can only be matched for the tools/call method"
+type MCPRouteMatch struct {
+	// Path matches the request path
+	// +optional
+	Path *PathMatch `json:"path,omitempty"`
+
+	// Headers match HTTP request headers
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Headers []HeaderMatch `json:"headers,omitempty"`
+
+	// Method matches the JSON-RPC method of the MCP request, e.g. tools/call
+	// +kubebuilder:validation:MaxLength=256
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Tool matches the name of the tool invoked by a tools/call request
+	// +kubebuilder:validation:MaxLength=256
+	// +optional
+	Tool string `json:"tool,omitempty"`
+}
+
+// MCPBackendRef refers to a Service in the route's namespace that serves matching requests
+type MCPBackendRef struct {
+	// Name is the name of the Service
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Port is the Service port to send requests to. Defaults to the Service's first port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// Weight is the proportion of requests sent to this backend, relative to the other
+	// backends of the rule. A weight of 0 sends no requests. Defaults to 1.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000000
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// MCPRouteMirror copies requests to a shadow backend. Shadow responses are discarded
+// after they are compared with the responses of the backend that served the request.
+type MCPRouteMirror struct {
+	// Name is the name of the shadow Service in the route's namespace
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Port is the Service port to send mirrored requests to. Defaults to the Service's first port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// Percent is the percentage of MCP sessions, or of requests for backends without
+	// sessions, that are mirrored. Defaults to 100.
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percent *int32 `json:"percent,omitempty"`
+
+	// Audit writes an audit log entry for each mirrored request whose shadow response
+	// differs from the served response
+	// +optional
+	Audit bool `json:"audit,omitempty"`
+}
+
+// MCPRouteRule routes the requests matching any of its matches to its backends
+type MCPRouteRule struct {
+	// Matches are the conditions a request must meet. A request matches the rule if it
+	// matches any of them. The rule matches all MCP requests when empty.
+	// +kubebuilder:validation:MaxItems=8
+	// +optional
+	Matches []MCPRouteMatch `json:"matches,omitempty"`
+
+	// BackendRefs are the backends matching requests are split across by weight
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	BackendRefs []MCPBackendRef `json:"backendRefs"`
+
+	// Mirror copies matching requests to a shadow backend without affecting the response
+	// +optional
+	Mirror *MCPRouteMirror `json:"mirror,omitempty"`
+}
+
+// MCPRouteSpec defines the desired state of MCPRoute
+type MCPRouteSpec struct {
+	// ParentRefs are the Gateways the route attaches to
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=32
+	ParentRefs []ParentReference `json:"parentRefs"`
+
+	// Rules are the routing rules of the route
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	Rules []MCPRouteRule `json:"rules"`
+}
+
+// RouteParentStatus describes the state of a route with respect to one of its parents
+type RouteParentStatus struct {
+	// ParentRef is the parent this status applies to
+	ParentRef ParentReference `json:"parentRef"`
+
+	// Conditions describe whether the parent accepted the route and whether its backends
+	// could be resolved
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// MCPRouteStatus defines the observed state of MCPRoute
+type MCPRouteStatus struct {
+	// Parents reports the state of the route for each of its parents
+	// +optional
+	Parents []RouteParentStatus `json:"parents,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,shortName=mcpr
+// +kubebuilder:printcolumn:name="Gateway",type="string",JSONPath=".spec.parentRefs[0].name",description="First parent Gateway"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// MCPRoute is the Schema for the mcproutes API. It routes MCP requests arriving at a
+// Gateway to backend Services.
+type MCPRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MCPRouteSpec   `json:"spec,omitempty"`
+	Status MCPRouteStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MCPRouteList contains a list of MCPRoute.
+type MCPRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MCPRoute `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MCPRoute{}, &MCPRouteList{})
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=mcpcat
+// +kubebuilder:printcolumn:name="Services",type="integer",JSONPath=".servicesTotal",description="Number of registered services"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// MCPServiceCatalog lists all MCP services registered with a Gateway. It is written by the
+// operator for Gateways with spec.discovery.catalog enabled, has the Gateway's name and is
+// deleted together with the Gateway.
+type MCPServiceCatalog struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// ServicesTotal is the number of services in the catalog
+	ServicesTotal int32 `json:"servicesTotal"`
+
+	// Services lists the registered services, ordered by namespace and name
+	// +optional
+	Services []MCPServiceInfo `json:"services,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MCPServiceCatalogList contains a list of MCPServiceCatalog.
+type MCPServiceCatalogList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MCPServiceCatalog `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MCPServiceCatalog{}, &MCPServiceCatalogList{})
+}
@@ -17,10 +17,16 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -28,19 +34,28 @@ import (
 
 	"k8s.io/apimachinery/pkg/types"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	fetchfyv1alpha1 "github.com/fetchfy/fetchfy-operator/api/v1alpha1"
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
 	"github.com/fetchfy/fetchfy-operator/internal/controller"
+	webhookcorev1 "github.com/fetchfy/fetchfy-operator/internal/webhook/v1"
+	webhookfetchfyv1alpha1 "github.com/fetchfy/fetchfy-operator/internal/webhook/v1alpha1"
 
 	// Import metrics and MCP packages
 	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
@@ -58,6 +73,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(fetchfyv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(fetchfyv1alpha2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -70,13 +86,16 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var serviceWebhookMode string
+	var snapshotFile, snapshotConfigMap string
+	var shutdownGracePeriod time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
-			"Enabling this will ensure there is only one active controller manager.")
+			"Every replica serves the MCP gateways, but only the leader writes statuses and events.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
@@ -88,6 +107,15 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&serviceWebhookMode, "service-webhook-mode", "enforce",
+		"How the Service webhook handles invalid MCP annotations: enforce rejects the Service, warn only returns warnings.")
+	flag.StringVar(&snapshotFile, "registry-snapshot-file", "",
+		"The file the MCP registry is persisted to and restored from on startup.")
+	flag.StringVar(&snapshotConfigMap, "registry-snapshot-configmap", "",
+		"The ConfigMap the MCP registry is persisted to and restored from on startup, as name or namespace/name. "+
+			"The namespace defaults to $POD_NAMESPACE.")
+	flag.DurationVar(&shutdownGracePeriod, "shutdown-grace-period", mcp.DefaultShutdownGracePeriod,
+		"How long the MCP gateways drain in-flight requests on shutdown before closing their connections.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -96,6 +124,15 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if serviceWebhookMode != "enforce" && serviceWebhookMode != "warn" {
+		setupLog.Error(nil, "invalid --service-webhook-mode, must be enforce or warn", "mode", serviceWebhookMode)
+		os.Exit(1)
+	}
+	if snapshotFile != "" && snapshotConfigMap != "" {
+		setupLog.Error(nil, "--registry-snapshot-file and --registry-snapshot-configmap are mutually exclusive")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -185,13 +222,24 @@ func main() {
 		})
 	}
 
+	// Leave the MCP gateways time to close the connections that outlive their grace period
+	gracefulShutdownTimeout := shutdownGracePeriod + 5*time.Second
+	mcpEnabledSelector := labels.SelectorFromSet(labels.Set{services.MCPEnabledLabel: "true"})
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		Metrics:                metricsServerOptions,
-		WebhookServer:          webhookServer,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "03697ae0.fetchfy.ai",
+		Scheme:                  scheme,
+		Metrics:                 metricsServerOptions,
+		WebhookServer:           webhookServer,
+		HealthProbeBindAddress:  probeAddr,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        "03697ae0.fetchfy.ai",
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
+		// Only MCP-enabled Pods and Deployments are cached, all others are of no interest
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}:        {Label: mcpEnabledSelector},
+				&appsv1.Deployment{}: {Label: mcpEnabledSelector},
+			},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -211,6 +259,15 @@ func main() {
 
 	// Initialize MCP Registry
 	mcpRegistry := mcp.NewRegistry(ctrl.Log.WithName("mcp-registry"))
+	// Every replica registers the services, but only the leader records Events on them
+	if err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		mcpRegistry.SetEventRecorder(mgr.GetEventRecorderFor("mcp-registry"))
+		<-ctx.Done()
+		return nil
+	})); err != nil {
+		setupLog.Error(err, "unable to add MCP registry event recorder to manager")
+		os.Exit(1)
+	}
 
 	// Initialize service watcher
 	serviceWatcher := services.NewServiceWatcher(
@@ -220,24 +277,110 @@ func main() {
 		mgr.GetScheme(),
 	)
 
-	if err = (&controller.GatewayReconciler{
-		Client:         mgr.GetClient(),
-		Scheme:         mgr.GetScheme(),
-		MCPRegistry:    mcpRegistry,
-		ServiceWatcher: serviceWatcher,
-		MCPServers:     make(map[types.NamespacedName]*mcp.Server),
-		Log:            ctrl.Log.WithName("gateway-controller"),
-		Recorder:       mgr.GetEventRecorderFor("gateway-controller"),
-	}).SetupWithManager(mgr); err != nil {
+	mcpServers := mcp.NewServerManager(mcpRegistry, ctrl.Log.WithName("gateway-controller"))
+	mcpServers.ShutdownGracePeriod = shutdownGracePeriod
+
+	gatewayReconciler := &controller.GatewayReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		MCPRegistry:       mcpRegistry,
+		ServiceWatcher:    serviceWatcher,
+		MCPServers:        mcpServers,
+		Log:               ctrl.Log.WithName("gateway-controller"),
+		Recorder:          mgr.GetEventRecorderFor("gateway-controller"),
+		OperatorNamespace: os.Getenv("POD_NAMESPACE"),
+	}
+	if err = gatewayReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Gateway")
 		os.Exit(1)
 	}
 
+	if err = (&controller.MCPRouteReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		MCPRegistry: mcpRegistry,
+		Log:         ctrl.Log.WithName("mcproute-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MCPRoute")
+		os.Exit(1)
+	}
+
 	// Set up the service watcher
 	if err = serviceWatcher.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create service watcher", "controller", "ServiceWatcher")
 		os.Exit(1)
 	}
+
+	// Set up the watchers of MCP servers running in Pods and Deployments without a Service
+	if err = services.NewPodWatcher(mgr.GetClient(), mcpRegistry,
+		ctrl.Log).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create pod watcher", "controller", "PodWatcher")
+		os.Exit(1)
+	}
+	if err = services.NewDeploymentWatcher(mgr.GetClient(), mgr.GetAPIReader(), mcpRegistry,
+		ctrl.Log).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create deployment watcher", "controller", "DeploymentWatcher")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		reserved := reservedPorts(map[string]string{
+			"health probes": probeAddr,
+			"metrics":       metricsAddr,
+			"webhooks":      fmt.Sprintf(":%d", webhook.DefaultPort),
+		})
+		if err = webhookfetchfyv1alpha1.SetupGatewayWebhookWithManager(mgr, reserved); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Gateway")
+			os.Exit(1)
+		}
+		if err = webhookcorev1.SetupServiceWebhookWithManager(mgr, serviceWebhookMode == "warn"); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Service")
+			os.Exit(1)
+		}
+	}
+
+	// Probe registered MCP backends so gateway conditions reflect their health
+	if err = mgr.Add(mcp.NewHealthChecker(mcpRegistry, ctrl.Log)); err != nil {
+		setupLog.Error(err, "unable to add MCP health checker to manager")
+		os.Exit(1)
+	}
+	// Listen for list_changed notifications of MCP backends to forward them to clients
+	if err = mgr.Add(mcp.NewNotificationSubscriber(mcpRegistry, ctrl.Log)); err != nil {
+		setupLog.Error(err, "unable to add MCP notification subscriber to manager")
+		os.Exit(1)
+	}
+	// Keep the service count metric in line with the registry
+	if err = mgr.Add(mcp.NewServiceCountRecorder(mcpRegistry)); err != nil {
+		setupLog.Error(err, "unable to add MCP service count recorder to manager")
+		os.Exit(1)
+	}
+	// Persist the registry so that a restarted operator routes to known services right away
+	if snapshotFile != "" || snapshotConfigMap != "" {
+		store, err := newSnapshotStore(mgr, snapshotFile, snapshotConfigMap)
+		if err != nil {
+			setupLog.Error(err, "invalid registry snapshot configuration")
+			os.Exit(1)
+		}
+		persister := mcp.NewSnapshotPersister(mcpRegistry, store, ctrl.Log)
+		persister.SelectedLabels = mcpServers.SelectedLabels
+		if snapshotConfigMap != "" {
+			// All replicas restore the shared ConfigMap, but only the leader saves it
+			persister.Elected = mgr.Elected()
+		}
+		restoreCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		restored, err := persister.Restore(restoreCtx)
+		cancel()
+		if err != nil {
+			// The registry fills up again as Services are reconciled
+			setupLog.Error(err, "unable to restore MCP registry snapshot")
+		} else {
+			setupLog.Info("Restored MCP registry snapshot", "services", restored)
+		}
+		if err = mgr.Add(persister); err != nil {
+			setupLog.Error(err, "unable to add MCP registry snapshot persister to manager")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -264,6 +407,15 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// Keep the replica out of load balancing until it routes to the services and serves the gateways
+	if err := mgr.AddReadyzCheck("mcp-services", serviceWatcher.CheckSynced); err != nil {
+		setupLog.Error(err, "unable to set up MCP services ready check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("mcp-gateways", gatewayReconciler.CheckListeners); err != nil {
+		setupLog.Error(err, "unable to set up MCP gateways ready check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -271,3 +423,44 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// reservedPorts returns the ports of the manager's servers, which Gateways can't listen on as the
+// servers run in the same pods. Servers bound to no port, such as disabled metrics, are skipped.
+func reservedPorts(addrs map[string]string) map[int32]string {
+	ports := make(map[int32]string, len(addrs))
+	for server, addr := range addrs {
+		_, portStr, err := net.SplitHostPort(addr)
+		if err != nil {
+			continue
+		}
+		port, err := strconv.ParseInt(portStr, 10, 32)
+		if err != nil || port == 0 {
+			continue
+		}
+		ports[int32(port)] = server
+	}
+	return ports
+}
+
+// newSnapshotStore returns the store of the registry snapshot configured by the flags
+func newSnapshotStore(mgr ctrl.Manager, file, configMap string) (mcp.SnapshotStore, error) {
+	if file != "" {
+		return &mcp.FileSnapshotStore{Path: file}, nil
+	}
+
+	namespace, name, found := strings.Cut(configMap, "/")
+	if !found {
+		namespace, name = os.Getenv("POD_NAMESPACE"), configMap
+	}
+	if namespace == "" || name == "" {
+		return nil, fmt.Errorf("--registry-snapshot-configmap %q needs a namespace when $POD_NAMESPACE is not set",
+			configMap)
+	}
+	// The snapshot is loaded before the cache is started, and the operator may only read
+	// ConfigMaps in its own namespace
+	return &mcp.ConfigMapSnapshotStore{
+		Reader: mgr.GetAPIReader(),
+		Writer: mgr.GetClient(),
+		Name:   types.NamespacedName{Name: name, Namespace: namespace},
+	}, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+// +kubebuilder:rbac:groups=fetchfy.fetchfy.ai,resources=mcpservicecatalogs,verbs=get;list;watch;create;update;patch;delete
+
+// reconcileCatalog publishes the full list of services in the gateway's MCPServiceCatalog if the
+// catalog is enabled, and deletes the catalog otherwise. The catalog is owned by the gateway, so
+// it is garbage collected together with it.
+func (r *GatewayReconciler) reconcileCatalog(
+	ctx context.Context,
+	gateway *fetchfyv1alpha2.Gateway,
+	mcpServices []fetchfyv1alpha2.MCPServiceInfo,
+) error {
+	catalog := &fetchfyv1alpha2.MCPServiceCatalog{
+		ObjectMeta: metav1.ObjectMeta{Name: gateway.Name, Namespace: gateway.Namespace},
+	}
+
+	if !gateway.Spec.CatalogEnabled() {
+		gateway.Status.Catalog = ""
+		err := r.Get(ctx, client.ObjectKeyFromObject(catalog), catalog)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Leave catalogs of the same name that weren't created for this gateway alone
+		if !metav1.IsControlledBy(catalog, gateway) {
+			return nil
+		}
+		return client.IgnoreNotFound(r.Delete(ctx, catalog))
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, catalog, func() error {
+		catalog.ServicesTotal = int32(len(mcpServices))
+		catalog.Services = mcpServices
+		return controllerutil.SetControllerReference(gateway, catalog, r.Scheme)
+	})
+	if err != nil {
+		gateway.Status.Catalog = ""
+		return err
+	}
+
+	gateway.Status.Catalog = catalog.Name
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/services"
+)
+
+var _ = Describe("Gateway federation", Ordered, func() {
+	const gatewayName = "federated"
+
+	var (
+		ctx          context.Context
+		remoteEnv    *envtest.Environment
+		remoteClient client.Client
+		reconciler   *GatewayReconciler
+	)
+	gatewayKey := types.NamespacedName{Name: gatewayName, Namespace: "default"}
+
+	BeforeAll(func() {
+		ctx = context.Background()
+
+		By("starting a second API server as the remote cluster")
+		remoteEnv = &envtest.Environment{BinaryAssetsDirectory: getFirstFoundEnvTestBinaryDir()}
+		remoteCfg, err := remoteEnv.Start()
+		Expect(err).NotTo(HaveOccurred())
+		remoteClient, err = client.New(remoteCfg, client.Options{Scheme: k8sClient.Scheme()})
+		Expect(err).NotTo(HaveOccurred())
+
+		user, err := remoteEnv.AddUser(envtest.User{Name: "fetchfy", Groups: []string{"system:masters"}}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		kubeconfig, err := user.KubeConfig()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "east-kubeconfig", Namespace: "default"},
+			Data:       map[string][]byte{fetchfyv1alpha2.DefaultKubeconfigKey: kubeconfig},
+		})).To(Succeed())
+
+		Expect(remoteClient.Create(ctx, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "weather",
+				Namespace:   "default",
+				Labels:      map[string]string{services.MCPEnabledLabel: "true"},
+				Annotations: map[string]string{services.MCPTypeAnnotation: "tool"},
+			},
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+		})).To(Succeed())
+
+		reconciler = newTestReconciler()
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:  k8sClient.Scheme(),
+			Metrics: metricsserver.Options{BindAddress: "0"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Federation.SetupWithManager(mgr)).To(Succeed())
+
+		Expect(k8sClient.Create(ctx, &fetchfyv1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: gatewayName, Namespace: "default"},
+			Spec: fetchfyv1alpha2.GatewaySpec{
+				Listeners: []fetchfyv1alpha2.Listener{{
+					Name:     fetchfyv1alpha2.DefaultListenerName,
+					Port:     freePort(),
+					Protocol: fetchfyv1alpha2.ProtocolHTTP,
+				}},
+				Federation: &fetchfyv1alpha2.GatewayFederation{Clusters: []fetchfyv1alpha2.FederatedCluster{{
+					Name:                "east",
+					KubeconfigSecretRef: fetchfyv1alpha2.SecretReference{Name: "east-kubeconfig"},
+					Address:             "https://mcp.east.example.com",
+				}}},
+			},
+		})).To(Succeed())
+	})
+
+	AfterAll(func() {
+		gateway := &fetchfyv1alpha2.Gateway{}
+		if err := k8sClient.Get(ctx, gatewayKey, gateway); err == nil {
+			Expect(k8sClient.Delete(ctx, gateway)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: gatewayKey})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(k8sClient.Delete(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "east-kubeconfig", Namespace: "default"},
+		})).To(Succeed())
+		Expect(remoteEnv.Stop()).To(Succeed())
+	})
+
+	It("should register the remote cluster's services with qualified names", func() {
+		Eventually(func(g Gomega) {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: gatewayKey})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			gateway := &fetchfyv1alpha2.Gateway{}
+			g.Expect(k8sClient.Get(ctx, gatewayKey, gateway)).To(Succeed())
+			g.Expect(gateway.Status.Clusters).To(HaveLen(1))
+			g.Expect(services.ClusterReady(gateway.Status.Clusters[0])).To(BeTrue())
+			g.Expect(gateway.Status.Clusters[0].Services).To(Equal(int32(1)))
+		}).WithTimeout(time.Minute).Should(Succeed())
+
+		svc, ok := reconciler.MCPRegistry.GetService(types.NamespacedName{Name: "east.weather", Namespace: "default"})
+		Expect(ok).To(BeTrue())
+		Expect(svc.Endpoint).To(Equal("/mcp/default/east.weather"))
+		Expect(svc.Target.URL().String()).To(Equal("https://mcp.east.example.com:443"))
+		Expect(svc.Target.BasePath).To(Equal("/mcp/default/weather"))
+	})
+
+	It("should deregister the remote services when the Gateway is deleted", func() {
+		gateway := &fetchfyv1alpha2.Gateway{}
+		Expect(k8sClient.Get(ctx, gatewayKey, gateway)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, gateway)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: gatewayKey})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Federation.Clusters()).To(BeEmpty())
+		_, ok := reconciler.MCPRegistry.GetService(types.NamespacedName{Name: "east.weather", Namespace: "default"})
+		Expect(ok).To(BeFalse())
+	})
+})
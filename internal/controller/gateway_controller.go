@@ -18,11 +18,18 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
-	"k8s.io/apimachinery/pkg/api/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -30,9 +37,13 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	fetchfyv1alpha1 "github.com/fetchfy/fetchfy-operator/api/v1alpha1"
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
 	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
 	"github.com/fetchfy/fetchfy-operator/pkg/services"
 )
@@ -41,33 +52,73 @@ const (
 	gatewayFinalizer = "fetchfy.ai/finalizer"
 
 	// Condition types
-	conditionTypeReady     = "Ready"
-	conditionTypeAvailable = "Available"
+	conditionTypeReady           = "Ready"
+	conditionTypeAvailable       = "Available"
+	conditionTypeBackendsHealthy = "BackendsHealthy"
+	conditionTypeDegraded        = "Degraded"
+	conditionTypeProgressing     = "Progressing"
 
 	// Condition reasons
-	reasonReady       = "GatewayReady"
-	reasonConfigured  = "GatewayConfigured"
-	reasonNotReady    = "GatewayNotReady"
-	reasonServerError = "ServerError"
-	reasonConfigError = "ConfigurationError"
+	reasonReady              = "GatewayReady"
+	reasonConfigured         = "GatewayConfigured"
+	reasonNotReady           = "GatewayNotReady"
+	reasonServerError        = "ServerError"
+	reasonConfigError        = "ConfigurationError"
+	reasonListenerFailed     = "ListenerFailed"
+	reasonTLSError           = "TLSError"
+	reasonAllBackendsHealthy = "AllBackendsHealthy"
+	reasonNoBackends         = "NoBackends"
+	reasonBackendsUnhealthy  = "BackendsUnhealthy"
+	reasonAsExpected         = "AsExpected"
+	reasonRestarting         = "ListenerRestarting"
+	reasonDraining           = "ListenerDraining"
+	reasonUpToDate           = "ListenerUpToDate"
+	reasonListening          = "Listening"
+	reasonCatalogError       = "CatalogError"
+	reasonFederationError    = "FederationError"
+
+	// degradedRequeueInterval is how often a degraded gateway is re-evaluated
+	degradedRequeueInterval = 30 * time.Second
+
+	// drainingRequeueInterval is how often drain progress of a restarted gateway is reported
+	drainingRequeueInterval = 5 * time.Second
+
+	// statusDebounce is how long registry changes are collected before the gateway statuses are updated
+	statusDebounce = time.Second
+
+	// maxReportedBackends caps the number of unhealthy backends named in a condition message
+	maxReportedBackends = 5
 )
 
-// GatewayReconciler reconciles a Gateway object
+// GatewayReconciler reconciles a Gateway object. It runs on every replica, so that all
+// replicas serve the gateways, but only the leader writes statuses, finalizers, catalogs
+// and Events.
 type GatewayReconciler struct {
 	client.Client
 	Scheme         *runtime.Scheme
 	Recorder       record.EventRecorder
 	MCPRegistry    *mcp.Registry
 	ServiceWatcher *services.ServiceWatcher
-	MCPServers     map[types.NamespacedName]*mcp.Server
+	Federation     *services.Federation
+	MCPServers     *mcp.ServerManager
 	Log            logr.Logger
+
+	// OperatorNamespace is the namespace whose kubeconfig Secrets all gateways may federate
+	OperatorNamespace string
+
+	// Elected is closed once the replica is elected leader. Defaults to the manager's.
+	Elected <-chan struct{}
 }
 
 // +kubebuilder:rbac:groups=fetchfy.fetchfy.ai,resources=gateways,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=fetchfy.fetchfy.ai,resources=gateways/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=fetchfy.fetchfy.ai,resources=gateways/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile handles reconciliation of Gateway resources
@@ -75,9 +126,9 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	log := r.Log.WithValues("gateway", req.NamespacedName)
 
 	// Fetch the Gateway instance
-	gateway := &fetchfyv1alpha1.Gateway{}
+	gateway := &fetchfyv1alpha2.Gateway{}
 	if err := r.Get(ctx, req.NamespacedName, gateway); err != nil {
-		if errors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) {
 			// Gateway might have been deleted, clean up
 			r.cleanupGateway(ctx, req.NamespacedName)
 			return ctrl.Result{}, nil
@@ -85,6 +136,7 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		log.Error(err, "Unable to fetch Gateway")
 		return ctrl.Result{}, err
 	}
+	original := gateway.DeepCopy()
 
 	// Initialize status conditions if they don't exist
 	if gateway.Status.Conditions == nil {
@@ -96,90 +148,219 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return r.handleDeletion(ctx, gateway)
 	}
 
-	// Add finalizer if it doesn't exist
-	if !controllerutil.ContainsFinalizer(gateway, gatewayFinalizer) {
-		controllerutil.AddFinalizer(gateway, gatewayFinalizer)
-		if err := r.Update(ctx, gateway); err != nil {
+	// Add finalizer if it doesn't exist. Followers serve the gateway without waiting for it.
+	if !controllerutil.ContainsFinalizer(gateway, gatewayFinalizer) && r.leader() {
+		if err := setFinalizer(ctx, r.Client, gateway, gatewayFinalizer, true); err != nil {
 			log.Error(err, "Failed to add finalizer")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	// Handle MCP server setup/configuration
-	if err := r.ensureMCPServer(ctx, gateway); err != nil {
-		log.Error(err, "Failed to ensure MCP server")
-
-		// Update gateway status to reflect the error
-		r.updateGatewayCondition(ctx, gateway, conditionTypeReady, metav1.ConditionFalse, reasonServerError, err.Error())
-
+	// Resolve the namespaces the gateway discovers services in
+	namespaces, err := r.ServiceWatcher.DiscoveryNamespaces(ctx, gateway)
+	if err != nil {
+		log.Error(err, "Failed to resolve discovery namespaces")
+		r.updateGatewayCondition(ctx, gateway, conditionTypeReady, metav1.ConditionFalse, reasonConfigError,
+			"Failed to resolve discovery namespaces: "+err.Error())
+		if statusErr := r.patchStatus(ctx, gateway, original); statusErr != nil {
+			log.Error(statusErr, "Failed to update Gateway status")
+		}
 		return ctrl.Result{RequeueAfter: time.Second * 30}, err
 	}
 
-	// Configure service watcher
-	r.ServiceWatcher.AddGateway(gateway)
+	// Handle MCP server setup/configuration
+	server, certErrs := r.ensureMCPServer(ctx, gateway, namespaces)
+	if server == nil {
+		// The servers were shut down, so the gateway isn't served by this replica anymore
+		log.Info("Not serving gateway, the operator is shutting down")
+		return ctrl.Result{}, nil
+	}
 
-	// Get matching services for this gateway
-	matchingServices, err := r.ServiceWatcher.GetMatchingServices(ctx, gateway)
-	if err != nil {
-		log.Error(err, "Failed to list matching services")
-		r.updateGatewayCondition(ctx, gateway, conditionTypeReady, metav1.ConditionFalse, reasonConfigError,
-			"Failed to list matching services: "+err.Error())
-		return ctrl.Result{RequeueAfter: time.Second * 30}, err
+	// Report each listener, so one failing listener doesn't hide the state of the others
+	serving, stale, err := r.updateListenerStatuses(gateway, server, certErrs)
+	if len(gateway.Spec.Listeners) > 0 {
+		gateway.Status.Address = fmt.Sprintf(":%d", gateway.Spec.Listeners[0].Port)
 	}
+	if err != nil {
+		log.Error(err, "Failed to ensure MCP server")
 
-	// Register all matching services
-	for _, svc := range matchingServices {
-		// Check if the service has the MCP enabled label
-		if svc.Labels != nil && svc.Labels[services.MCPEnabledLabel] == "true" {
-			serviceType := mcp.ServiceTypeTool
-			if svc.Annotations != nil && svc.Annotations[services.MCPTypeAnnotation] == string(mcp.ServiceTypeAgent) {
-				serviceType = mcp.ServiceTypeAgent
-			}
-			if _, err := r.MCPRegistry.RegisterService(ctx, &svc, serviceType); err != nil {
-				log.Error(err, "Failed to register service", "service", fmt.Sprintf("%s/%s", svc.Namespace, svc.Name))
-			}
+		// Update gateway status to reflect the error
+		reason := serverErrorReason(err)
+		r.updateGatewayCondition(ctx, gateway, conditionTypeReady, metav1.ConditionFalse, reason, err.Error())
+		if serving > 0 {
+			r.updateGatewayCondition(ctx, gateway, conditionTypeAvailable, metav1.ConditionTrue, reasonConfigured,
+				fmt.Sprintf("Gateway is available on %d of %d listeners", serving, len(gateway.Spec.Listeners)))
+		} else {
+			r.updateGatewayCondition(ctx, gateway, conditionTypeAvailable, metav1.ConditionFalse, reason, err.Error())
+		}
+		if stale {
+			// Previous listeners keep serving, only the new configuration failed to apply
+			r.updateGatewayCondition(ctx, gateway, conditionTypeProgressing, metav1.ConditionFalse, reason,
+				"Failed to apply listener configuration: "+err.Error())
 		}
+		r.updateGatewayCondition(ctx, gateway, conditionTypeDegraded, metav1.ConditionTrue, reason, err.Error())
+		if statusErr := r.patchStatus(ctx, gateway, original); statusErr != nil {
+			log.Error(statusErr, "Failed to update Gateway status")
+		}
+
+		return ctrl.Result{RequeueAfter: time.Second * 30}, err
 	}
 
+	// Watch the federated clusters, whose services are registered as their caches sync
+	gateway.Status.Clusters = r.Federation.Sync(ctx, gateway)
+	clustersReady := r.recordClusterEvents(original, gateway)
+
 	// Update gateway status
-	r.MCPRegistry.UpdateRegistryStatus(gateway)
+	mcpServices := r.MCPRegistry.UpdateRegistryStatus(gateway, namespaces)
+	r.updateListenerServiceCounts(gateway, server)
+
+	// Publish the full list of services, which the status may cap
+	if !r.leader() {
+		// Keep the catalog the leader reported
+		gateway.Status.Catalog = original.Status.Catalog
+	} else if err := r.reconcileCatalog(ctx, gateway, mcpServices); err != nil {
+		log.Error(err, "Failed to update MCPServiceCatalog")
+		r.recordEvent(gateway, corev1.EventTypeWarning, reasonCatalogError,
+			"Failed to update MCPServiceCatalog: "+err.Error())
+	}
 
-	// Set address field in status
-	gateway.Status.Address = fmt.Sprintf(":%d", gateway.Spec.MCPPort)
+	// All listeners are bound, so the gateway accepts connections
+	r.setServerConditions(ctx, gateway, metav1.ConditionTrue, reasonReady,
+		fmt.Sprintf("Gateway is ready with %d services on %d listeners",
+			gateway.Status.ServicesTotal, len(gateway.Spec.Listeners)))
 
-	// Update gateway status to Ready
-	r.updateGatewayCondition(ctx, gateway, conditionTypeReady, metav1.ConditionTrue, reasonReady,
-		fmt.Sprintf("Gateway is ready with %d services", len(gateway.Status.MCPServices)))
+	// Report drain progress of listeners replaced by a restart
+	draining := r.updateProgressingCondition(ctx, gateway, server)
 
-	r.updateGatewayCondition(ctx, gateway, conditionTypeAvailable, metav1.ConditionTrue, reasonConfigured,
-		"Gateway is available")
+	// Roll up backend health
+	healthy := r.updateBackendConditions(ctx, gateway, mcpServices)
 
-	if err := r.Status().Update(ctx, gateway); err != nil {
+	if err := r.patchStatus(ctx, gateway, original); err != nil {
 		log.Error(err, "Failed to update Gateway status")
 		return ctrl.Result{RequeueAfter: time.Minute}, err
 	}
 
+	if draining {
+		return ctrl.Result{RequeueAfter: drainingRequeueInterval}, nil
+	}
+
+	if !healthy || !clustersReady {
+		// Re-evaluate soon so the conditions follow backend and cluster recovery
+		return ctrl.Result{RequeueAfter: degradedRequeueInterval}, nil
+	}
+
 	// Requeue periodically to ensure gateway status stays up to date
 	return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
 }
 
+// setServerConditions sets the Ready and Available conditions, which track whether the
+// gateway listener is serving
+func (r *GatewayReconciler) setServerConditions(
+	ctx context.Context,
+	gateway *fetchfyv1alpha2.Gateway,
+	status metav1.ConditionStatus,
+	reason, message string,
+) {
+	r.updateGatewayCondition(ctx, gateway, conditionTypeReady, status, reason, message)
+
+	if status == metav1.ConditionTrue {
+		r.updateGatewayCondition(ctx, gateway, conditionTypeAvailable, status, reasonConfigured, "Gateway is available")
+	} else {
+		r.updateGatewayCondition(ctx, gateway, conditionTypeAvailable, status, reason, message)
+	}
+}
+
+// updateProgressingCondition reports whether listeners replaced by a restart are still
+// draining. It returns true while they are.
+func (r *GatewayReconciler) updateProgressingCondition(
+	ctx context.Context,
+	gateway *fetchfyv1alpha2.Gateway,
+	server *mcp.Server,
+) bool {
+	if n := server.Draining(); n > 0 {
+		r.updateGatewayCondition(ctx, gateway, conditionTypeProgressing, metav1.ConditionTrue, reasonDraining,
+			fmt.Sprintf("Serving on %d listener(s), draining %d previous listener(s)", len(server.Listeners()), n))
+		return true
+	}
+
+	r.updateGatewayCondition(ctx, gateway, conditionTypeProgressing, metav1.ConditionFalse, reasonUpToDate,
+		"Listeners match the gateway configuration")
+	return false
+}
+
+// updateBackendConditions rolls up the health of the registered services into the
+// BackendsHealthy and Degraded conditions. It returns true if all backends are healthy.
+func (r *GatewayReconciler) updateBackendConditions(
+	ctx context.Context,
+	gateway *fetchfyv1alpha2.Gateway,
+	mcpServices []fetchfyv1alpha2.MCPServiceInfo,
+) bool {
+	var unhealthy []string
+	for _, svc := range mcpServices {
+		if svc.Status != string(mcp.ServiceStatusAvailable) {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s/%s (%s)", svc.Namespace, svc.Name, svc.Status))
+		}
+	}
+
+	if len(unhealthy) == 0 {
+		if len(mcpServices) == 0 {
+			r.updateGatewayCondition(ctx, gateway, conditionTypeBackendsHealthy, metav1.ConditionTrue, reasonNoBackends,
+				"No MCP services are registered")
+		} else {
+			r.updateGatewayCondition(ctx, gateway, conditionTypeBackendsHealthy, metav1.ConditionTrue, reasonAllBackendsHealthy,
+				fmt.Sprintf("All %d backends are healthy", len(mcpServices)))
+		}
+		r.updateGatewayCondition(ctx, gateway, conditionTypeDegraded, metav1.ConditionFalse, reasonAsExpected,
+			"Gateway is operating normally")
+		return true
+	}
+
+	sort.Strings(unhealthy)
+	listed := unhealthy
+	if len(listed) > maxReportedBackends {
+		listed = listed[:maxReportedBackends]
+	}
+	message := fmt.Sprintf("%d of %d backends are unhealthy: %s", len(unhealthy), len(mcpServices),
+		strings.Join(listed, ", "))
+	if len(unhealthy) > len(listed) {
+		message += ", ..."
+	}
+
+	r.updateGatewayCondition(ctx, gateway, conditionTypeBackendsHealthy, metav1.ConditionFalse, reasonBackendsUnhealthy, message)
+	r.updateGatewayCondition(ctx, gateway, conditionTypeDegraded, metav1.ConditionTrue, reasonBackendsUnhealthy, message)
+	return false
+}
+
+// serverErrorReason maps an MCP listener error to a condition reason
+func serverErrorReason(err error) string {
+	switch {
+	case errors.Is(err, mcp.ErrTLSConfig):
+		return reasonTLSError
+	case errors.Is(err, mcp.ErrListenFailed):
+		return reasonListenerFailed
+	case errors.Is(err, mcp.ErrListenerConfig):
+		return reasonConfigError
+	default:
+		return reasonServerError
+	}
+}
+
 // handleDeletion handles the deletion of a Gateway resource
-func (r *GatewayReconciler) handleDeletion(ctx context.Context, gateway *fetchfyv1alpha1.Gateway) (ctrl.Result, error) {
+func (r *GatewayReconciler) handleDeletion(ctx context.Context, gateway *fetchfyv1alpha2.Gateway) (ctrl.Result, error) {
 	log := r.Log.WithValues("gateway", types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace})
 	log.Info("Handling deletion of Gateway")
 
 	// Clean up resources
 	r.cleanupGateway(ctx, types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace})
 
-	// Check if the finalizer exists
-	if controllerutil.ContainsFinalizer(gateway, gatewayFinalizer) {
-		// Remove the finalizer
-		controllerutil.RemoveFinalizer(gateway, gatewayFinalizer)
-		if err := r.Update(ctx, gateway); err != nil {
-			log.Error(err, "Failed to remove finalizer")
-			return ctrl.Result{}, err
-		}
+	// The leader releases the gateway once it stopped serving it
+	if !r.leader() {
+		return ctrl.Result{}, nil
+	}
+	if err := setFinalizer(ctx, r.Client, gateway, gatewayFinalizer, false); err != nil {
+		log.Error(err, "Failed to remove finalizer")
+		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
@@ -191,46 +372,188 @@ func (r *GatewayReconciler) cleanupGateway(ctx context.Context, gatewayName type
 	log.Info("Cleaning up resources for gateway")
 
 	// Stop and remove MCP server
-	if server, exists := r.MCPServers[gatewayName]; exists {
-		if err := server.Stop(ctx); err != nil {
-			log.Error(err, "Failed to stop MCP server")
+	if _, err := r.MCPServers.Remove(ctx, gatewayName); err != nil {
+		log.Error(err, "Failed to stop MCP server")
+	}
+
+	// Stop watching the clusters only this gateway federates
+	r.Federation.RemoveGateway(ctx, gatewayName)
+}
+
+// recordClusterEvents records a Warning Event for every federated cluster that newly failed and
+// reports whether all federated clusters are connected
+func (r *GatewayReconciler) recordClusterEvents(original, gateway *fetchfyv1alpha2.Gateway) bool {
+	previous := make(map[string]string, len(original.Status.Clusters))
+	for _, cluster := range original.Status.Clusters {
+		if condition := meta.FindStatusCondition(cluster.Conditions, services.ClusterConditionReady); condition != nil {
+			previous[cluster.Name] = condition.Reason
 		}
-		delete(r.MCPServers, gatewayName)
 	}
 
-	// Remove gateway from service watcher
-	r.ServiceWatcher.RemoveGateway(gatewayName)
+	ready := true
+	for _, cluster := range gateway.Status.Clusters {
+		if services.ClusterReady(cluster) {
+			continue
+		}
+		ready = false
+		condition := meta.FindStatusCondition(cluster.Conditions, services.ClusterConditionReady)
+		if condition.Reason != services.ClusterReasonConnecting && previous[cluster.Name] != condition.Reason {
+			r.recordEvent(gateway, corev1.EventTypeWarning, reasonFederationError,
+				fmt.Sprintf("Federated cluster %s: %s", cluster.Name, condition.Message))
+		}
+	}
+	return ready
 }
 
-// ensureMCPServer ensures that an MCP server is running for the gateway
-func (r *GatewayReconciler) ensureMCPServer(ctx context.Context, gateway *fetchfyv1alpha1.Gateway) error {
+// ensureMCPServer ensures that an MCP server is configured for the gateway, discovering
+// services in the given namespaces, and that its listeners are bound. It returns the server
+// and the certificate errors of its listeners, or a nil server once the servers are shut down.
+func (r *GatewayReconciler) ensureMCPServer(
+	ctx context.Context,
+	gateway *fetchfyv1alpha2.Gateway,
+	namespaces []string,
+) (*mcp.Server, map[string]error) {
 	gatewayName := types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace}
 
-	// Check if server exists
-	server, exists := r.MCPServers[gatewayName]
-	if !exists {
-		// Create new server
-		server = mcp.NewServer(r.MCPRegistry, r.Log)
-		r.MCPServers[gatewayName] = server
+	server, _ := r.MCPServers.GetOrCreate(gatewayName)
+	if server == nil {
+		return nil, nil
 	}
 
 	// Configure server
-	server.Configure(gateway)
+	server.Configure(gateway, namespaces)
 
-	// Start server if not running
-	if !server.IsRunning() {
-		if err := server.Start(ctx); err != nil {
-			return err
+	certErrs := make(map[string]error)
+	for i := range gateway.Spec.Listeners {
+		listener := &gateway.Spec.Listeners[i]
+		if listener.Protocol != fetchfyv1alpha2.ProtocolHTTPS {
+			continue
+		}
+		cert, err := r.loadCertificate(ctx, gateway, listener)
+		if err != nil {
+			// A listener that is already serving keeps its previous certificate
+			certErrs[listener.Name] = err
+			continue
+		}
+		server.SetCertificate(listener.Name, cert)
+	}
+
+	// Restart listeners whose port or TLS mode changed
+	if server.NeedsRestart() {
+		r.updateGatewayCondition(ctx, gateway, conditionTypeProgressing, metav1.ConditionTrue, reasonRestarting,
+			"Restarting listeners to apply port and TLS changes")
+	}
+
+	// Bind listeners that aren't serving; failures are reported per listener
+	if err := server.Sync(ctx); err != nil {
+		r.Log.Info("Some MCP listeners are not serving", "gateway", gatewayName, "error", err.Error())
+	}
+
+	return server, certErrs
+}
+
+// updateListenerStatuses reports the state of each listener in the gateway status. It returns
+// the number of serving listeners, whether any of them still serves a previous configuration,
+// and the errors of all listeners that are not up to date.
+func (r *GatewayReconciler) updateListenerStatuses(
+	gateway *fetchfyv1alpha2.Gateway,
+	server *mcp.Server,
+	certErrs map[string]error,
+) (int, bool, error) {
+	previous := make(map[string]fetchfyv1alpha2.ListenerStatus, len(gateway.Status.Listeners))
+	for _, status := range gateway.Status.Listeners {
+		previous[status.Name] = status
+	}
+
+	serving := 0
+	stale := false
+	var errs []error
+	statuses := make([]fetchfyv1alpha2.ListenerStatus, 0, len(gateway.Spec.Listeners))
+	for _, listener := range server.Listeners() {
+		listenerErr := listener.Err
+		if certErr := certErrs[listener.Name]; certErr != nil {
+			listenerErr = certErr
+		}
+
+		status := previous[listener.Name]
+		status.Name = listener.Name
+		status.Address = listener.Addr
+		status.Services = int32(listener.Services)
+
+		condition := metav1.Condition{
+			Type:               conditionTypeReady,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonListening,
+			Message:            "Listening on " + listener.Addr,
+			ObservedGeneration: gateway.Generation,
 		}
+		if listenerErr != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = serverErrorReason(listenerErr)
+			condition.Message = listenerErr.Error()
+			if listener.Addr != "" {
+				condition.Message = fmt.Sprintf("Serving the previous configuration on %s: %v", listener.Addr, listenerErr)
+				stale = true
+			}
+			errs = append(errs, fmt.Errorf("listener %s: %w", listener.Name, listenerErr))
+		}
+		meta.SetStatusCondition(&status.Conditions, condition)
+
+		if listener.Addr != "" {
+			serving++
+		}
+		statuses = append(statuses, status)
+	}
+	gateway.Status.Listeners = statuses
+
+	return serving, stale, errors.Join(errs...)
+}
+
+// updateListenerServiceCounts refreshes the number of services visible through each listener
+func (r *GatewayReconciler) updateListenerServiceCounts(gateway *fetchfyv1alpha2.Gateway, server *mcp.Server) {
+	counts := make(map[string]int32)
+	for _, listener := range server.Listeners() {
+		counts[listener.Name] = int32(listener.Services)
+	}
+	for i := range gateway.Status.Listeners {
+		gateway.Status.Listeners[i].Services = counts[gateway.Status.Listeners[i].Name]
+	}
+}
+
+// loadCertificate loads the TLS certificate of a listener from its secret
+func (r *GatewayReconciler) loadCertificate(
+	ctx context.Context,
+	gateway *fetchfyv1alpha2.Gateway,
+	listener *fetchfyv1alpha2.Listener,
+) (*tls.Certificate, error) {
+	if listener.TLS == nil || listener.TLS.CertificateRef.Name == "" {
+		return nil, fmt.Errorf("%w: protocol is HTTPS but tls.certificateRef is empty", mcp.ErrTLSConfig)
+	}
+
+	ref := listener.TLS.CertificateRef
+	key := types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}
+	if key.Namespace == "" {
+		key.Namespace = gateway.Namespace
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("%w: failed to get secret %s: %v", mcp.ErrTLSConfig, key, err)
 	}
 
-	return nil
+	cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("%w: secret %s does not contain a valid key pair: %v", mcp.ErrTLSConfig, key, err)
+	}
+
+	return &cert, nil
 }
 
-// updateGatewayCondition updates a condition in the gateway status
+// updateGatewayCondition updates a condition in the gateway status and records an
+// Event when the condition transitions
 func (r *GatewayReconciler) updateGatewayCondition(
 	ctx context.Context,
-	gateway *fetchfyv1alpha1.Gateway,
+	gateway *fetchfyv1alpha2.Gateway,
 	conditionType string,
 	status metav1.ConditionStatus,
 	reason, message string,
@@ -243,15 +566,24 @@ func (r *GatewayReconciler) updateGatewayCondition(
 				return
 			}
 
+			transitionTime := condition.LastTransitionTime
+			if condition.Status != status {
+				transitionTime = metav1.NewTime(time.Now())
+			}
+
 			// Update existing condition
 			gateway.Status.Conditions[i] = metav1.Condition{
 				Type:               conditionType,
 				Status:             status,
-				LastTransitionTime: metav1.NewTime(time.Now()),
+				LastTransitionTime: transitionTime,
 				Reason:             reason,
 				Message:            message,
 				ObservedGeneration: gateway.Generation,
 			}
+
+			if condition.Status != status || condition.Reason != reason {
+				r.recordConditionEvent(gateway, conditionType, status, reason, message)
+			}
 			return
 		}
 	}
@@ -265,15 +597,87 @@ func (r *GatewayReconciler) updateGatewayCondition(
 		Message:            message,
 		ObservedGeneration: gateway.Generation,
 	})
+	r.recordConditionEvent(gateway, conditionType, status, reason, message)
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	// Initialize MCPServers map if not set
-	if r.MCPServers == nil {
-		r.MCPServers = make(map[types.NamespacedName]*mcp.Server)
+// recordConditionEvent records a Kubernetes Event for a condition transition
+func (r *GatewayReconciler) recordConditionEvent(
+	gateway *fetchfyv1alpha2.Gateway,
+	conditionType string,
+	status metav1.ConditionStatus,
+	reason, message string,
+) {
+	eventType := corev1.EventTypeNormal
+	// Degraded is the only condition where True is bad news
+	if (conditionType == conditionTypeDegraded) == (status == metav1.ConditionTrue) {
+		eventType = corev1.EventTypeWarning
+	}
+
+	r.recordEvent(gateway, eventType, reason, fmt.Sprintf("%s=%s: %s", conditionType, status, message))
+}
+
+// recordEvent records a Kubernetes Event on the gateway if a recorder is configured and the
+// replica is the leader
+func (r *GatewayReconciler) recordEvent(gateway *fetchfyv1alpha2.Gateway, eventType, reason, message string) {
+	if r.Recorder == nil || !r.leader() {
+		return
+	}
+	r.Recorder.Event(gateway, eventType, reason, message)
+}
+
+// patchStatus writes the status changes of the gateway if the replica is the leader
+func (r *GatewayReconciler) patchStatus(ctx context.Context, gateway, original *fetchfyv1alpha2.Gateway) error {
+	if !r.leader() {
+		return nil
+	}
+	return patchStatus(ctx, r.Client, gateway, original)
+}
+
+// leader reports whether the replica is the leader and may write to the API server
+func (r *GatewayReconciler) leader() bool {
+	return isLeader(r.Elected)
+}
+
+// CheckListeners is a readiness check that fails until the MCP server of every Gateway has
+// tried to bind its listeners on this replica. A listener that failed to restart but still
+// serves on its old address counts as bound. Invalid listener and TLS configurations and ports
+// that can't be bound are left to the Gateway status: they are set by the Gateway's owner, and
+// failing on them would also take the Gateway webhook that rejects the fix out of service. It
+// has the signature of healthz.Checker.
+func (r *GatewayReconciler) CheckListeners(req *http.Request) error {
+	gateways := &fetchfyv1alpha2.GatewayList{}
+	if err := r.List(req.Context(), gateways); err != nil {
+		return fmt.Errorf("failed to list Gateways: %w", err)
 	}
 
+	var errs []error
+	for _, gateway := range gateways.Items {
+		if !gateway.DeletionTimestamp.IsZero() {
+			continue
+		}
+		name := types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace}
+		server, exists := r.MCPServers.Get(name)
+		if !exists {
+			errs = append(errs, fmt.Errorf("gateway %s: MCP server isn't configured yet", name))
+			continue
+		}
+		for _, listener := range server.Listeners() {
+			switch {
+			case listener.Addr != "":
+			case errors.Is(listener.Err, mcp.ErrListenerConfig), errors.Is(listener.Err, mcp.ErrTLSConfig),
+				errors.Is(listener.Err, mcp.ErrListenFailed):
+			case listener.Err != nil:
+				errs = append(errs, fmt.Errorf("gateway %s: listener %s: %w", name, listener.Name, listener.Err))
+			default:
+				errs = append(errs, fmt.Errorf("gateway %s: listener %s isn't bound yet", name, listener.Name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Initialize logger
 	logger := logf.Log.WithName("gateway-controller")
 	if r.Log.GetSink() == nil {
@@ -285,6 +689,15 @@ func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		r.MCPRegistry = mcp.NewRegistry(r.Log)
 	}
 
+	// Create the MCP server manager if not provided
+	if r.MCPServers == nil {
+		r.MCPServers = mcp.NewServerManager(r.MCPRegistry, r.Log)
+	}
+	// Drain the servers when the manager stops
+	if err := mgr.Add(r.MCPServers); err != nil {
+		return err
+	}
+
 	// Create Service Watcher if not provided
 	if r.ServiceWatcher == nil {
 		r.ServiceWatcher = services.NewServiceWatcher(r.Client, r.MCPRegistry, r.Log, r.Scheme)
@@ -293,10 +706,149 @@ func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		}
 	}
 
+	// Create the federation of remote clusters if not provided
+	if r.Federation == nil {
+		r.Federation = services.NewFederation(mgr.GetAPIReader(), r.MCPRegistry, r.Log, r.Scheme)
+		r.Federation.SetOperatorNamespace(r.OperatorNamespace)
+		if err := r.Federation.SetupWithManager(mgr); err != nil {
+			return err
+		}
+	}
+
 	// Setup event recorder
 	r.Recorder = mgr.GetEventRecorderFor("gateway-controller")
 
+	if r.Elected == nil {
+		r.Elected = mgr.Elected()
+	}
+
+	// Follow registry changes so that the gateway statuses list the current services. The
+	// source only runs on the leader, as followers don't write statuses.
+	serviceEvents := &registryEventSource{
+		registry: r.MCPRegistry,
+		gateways: r.gatewaysForServices,
+		events:   make(chan event.GenericEvent),
+	}
+	if err := mgr.Add(serviceEvents); err != nil {
+		return err
+	}
+
+	// Every replica serves the gateways
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&fetchfyv1alpha1.Gateway{}).
+		WithOptions(services.AllReplicas()).
+		For(&fetchfyv1alpha2.Gateway{}).
+		Owns(&fetchfyv1alpha2.MCPServiceCatalog{}).
+		WatchesRawSource(source.Channel(serviceEvents.events, &handler.EnqueueRequestForObject{})).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.gatewaysForNamespace)).
 		Complete(r)
 }
+
+// gatewaysForNamespace maps a Namespace to the gateways selecting their discovery namespaces
+// by label, as the Namespace's labels may add it to or remove it from their discovery scope
+func (r *GatewayReconciler) gatewaysForNamespace(ctx context.Context, _ client.Object) []reconcile.Request {
+	gateways := &fetchfyv1alpha2.GatewayList{}
+	if err := r.List(ctx, gateways); err != nil {
+		r.Log.Error(err, "Failed to list Gateways")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, gateway := range gateways.Items {
+		if gateway.Spec.Discovery == nil || gateway.Spec.Discovery.NamespaceSelector == nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace},
+		})
+	}
+	return requests
+}
+
+// gatewaysForServices returns the Gateways whose statuses list a service of the events, before
+// or after the change. Without events, it returns all Gateways.
+func (r *GatewayReconciler) gatewaysForServices(ctx context.Context, events []mcp.ServiceEvent) []types.NamespacedName {
+	gateways := &fetchfyv1alpha2.GatewayList{}
+	if err := r.List(ctx, gateways); err != nil {
+		r.Log.Error(err, "Failed to list Gateways")
+		return nil
+	}
+
+	var names []types.NamespacedName
+	for i := range gateways.Items {
+		gateway := &gateways.Items[i]
+		if events != nil && !r.discoversAny(ctx, gateway, events) {
+			continue
+		}
+		names = append(names, types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace})
+	}
+	return names
+}
+
+// discoversAny reports whether the gateway discovers a service of the events, before or after
+// the change. Gateways whose discovery namespaces can't be resolved are assumed to discover them.
+func (r *GatewayReconciler) discoversAny(
+	ctx context.Context,
+	gateway *fetchfyv1alpha2.Gateway,
+	events []mcp.ServiceEvent,
+) bool {
+	namespaces, err := r.ServiceWatcher.DiscoveryNamespaces(ctx, gateway)
+	if err != nil {
+		return true
+	}
+	for _, serviceEvent := range events {
+		for _, svc := range []*mcp.MCPService{serviceEvent.Old, serviceEvent.New} {
+			if svc != nil && mcp.GatewayDiscovers(gateway, namespaces, svc) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// registryEventSource passes the registry's service events to the gateway controller as
+// generic events of the Gateways they concern. Bursts of events within statusDebounce are
+// collected, so that they lead to one status write per gateway. It starts with events of all
+// gateways, so that a newly elected leader writes the statuses of all gateways.
+type registryEventSource struct {
+	registry *mcp.Registry
+	gateways func(ctx context.Context, events []mcp.ServiceEvent) []types.NamespacedName
+	events   chan event.GenericEvent
+}
+
+// Start forwards service events until the context is cancelled. It implements manager.Runnable.
+func (s *registryEventSource) Start(ctx context.Context) error {
+	_, events := s.registry.Watch(ctx)
+
+	// The first flush concerns all gateways
+	all := true
+	var pending []mcp.ServiceEvent
+	flush := time.After(statusDebounce)
+	for {
+		select {
+		case serviceEvent, ok := <-events:
+			if !ok {
+				return nil
+			}
+			pending = append(pending, serviceEvent)
+			if flush == nil {
+				flush = time.After(statusDebounce)
+			}
+		case <-flush:
+			concerned := pending
+			if all {
+				concerned = nil
+			}
+			all, pending, flush = false, nil, nil
+			for _, gateway := range s.gateways(ctx, concerned) {
+				gatewayEvent := event.GenericEvent{Object: &fetchfyv1alpha2.Gateway{
+					ObjectMeta: metav1.ObjectMeta{Name: gateway.Name, Namespace: gateway.Namespace},
+				}}
+				select {
+				case s.events <- gatewayEvent:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	}
+}
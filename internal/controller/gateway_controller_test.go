@@ -18,18 +18,54 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	fetchfyv1alpha1 "github.com/fetchfy/fetchfy-operator/api/v1alpha1"
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+	"github.com/fetchfy/fetchfy-operator/pkg/services"
 )
 
+// freePort returns a TCP port that is currently free on the local host
+func freePort() int32 {
+	l, err := net.Listen("tcp", ":0")
+	Expect(err).NotTo(HaveOccurred())
+	defer l.Close()
+	return int32(l.Addr().(*net.TCPAddr).Port)
+}
+
+// newTestReconciler returns a GatewayReconciler wired with in-memory dependencies
+func newTestReconciler() *GatewayReconciler {
+	log := logf.Log.WithName("test")
+	registry := mcp.NewRegistry(log)
+	return &GatewayReconciler{
+		Client:         k8sClient,
+		Scheme:         k8sClient.Scheme(),
+		Recorder:       record.NewFakeRecorder(100),
+		MCPRegistry:    registry,
+		ServiceWatcher: services.NewServiceWatcher(k8sClient, registry, log, k8sClient.Scheme()),
+		Federation:     services.NewFederation(k8sClient, registry, log, k8sClient.Scheme()),
+		MCPServers:     mcp.NewServerManager(registry, log),
+		Log:            log,
+	}
+}
+
 var _ = Describe("Gateway Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
@@ -38,47 +74,308 @@ var _ = Describe("Gateway Controller", func() {
 
 		typeNamespacedName := types.NamespacedName{
 			Name:      resourceName,
-			Namespace: "default", // TODO(user):Modify as needed
+			Namespace: "default",
 		}
-		gateway := &fetchfyv1alpha1.Gateway{}
+		var controllerReconciler *GatewayReconciler
 
 		BeforeEach(func() {
+			controllerReconciler = newTestReconciler()
+
 			By("creating the custom resource for the Kind Gateway")
+			gateway := &fetchfyv1alpha2.Gateway{}
 			err := k8sClient.Get(ctx, typeNamespacedName, gateway)
 			if err != nil && errors.IsNotFound(err) {
-				resource := &fetchfyv1alpha1.Gateway{
+				resource := &fetchfyv1alpha2.Gateway{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: fetchfyv1alpha2.GatewaySpec{
+						Listeners: []fetchfyv1alpha2.Listener{{
+							Name:     fetchfyv1alpha2.DefaultListenerName,
+							Port:     freePort(),
+							Protocol: fetchfyv1alpha2.ProtocolHTTP,
+						}},
+						Discovery: &fetchfyv1alpha2.GatewayDiscovery{
+							ServiceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{services.MCPEnabledLabel: "true"},
+							},
+						},
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
-			resource := &fetchfyv1alpha1.Gateway{}
+			resource := &fetchfyv1alpha2.Gateway{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance Gateway")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			By("Reconciling the deletion to release the finalizer")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(controllerReconciler.MCPServers.Len()).To(BeZero())
 		})
+
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
-			controllerReconciler := &GatewayReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+			for i := 0; i < 2; i++ {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: typeNamespacedName,
+				})
+				Expect(err).NotTo(HaveOccurred())
 			}
 
-			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
-				NamespacedName: typeNamespacedName,
-			})
+			gateway := &fetchfyv1alpha2.Gateway{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gateway)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(gateway.Status.Conditions, conditionTypeReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(gateway.Status.Conditions, conditionTypeAvailable)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(gateway.Status.Conditions, conditionTypeBackendsHealthy)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(gateway.Status.Conditions, conditionTypeDegraded)).To(BeTrue())
+		})
+
+		It("should serve the gateway on followers and leave writes to the leader", func() {
+			elected := make(chan struct{})
+			controllerReconciler.Elected = elected
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			server, ok := controllerReconciler.MCPServers.Get(typeNamespacedName)
+			Expect(ok).To(BeTrue())
+			Expect(server.Addr(fetchfyv1alpha2.DefaultListenerName)).NotTo(BeEmpty())
+			gateway := &fetchfyv1alpha2.Gateway{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gateway)).To(Succeed())
+			Expect(gateway.Finalizers).To(BeEmpty())
+			Expect(gateway.Status.Conditions).To(BeEmpty())
+			recorder := controllerReconciler.Recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(BeEmpty())
+
+			By("writing the status once elected")
+			close(elected)
+			for i := 0; i < 2; i++ {
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gateway)).To(Succeed())
+			Expect(gateway.Finalizers).To(ContainElement(gatewayFinalizer))
+			Expect(meta.IsStatusConditionTrue(gateway.Status.Conditions, conditionTypeReady)).To(BeTrue())
+		})
+
+		It("should move the listener when the port changes", func() {
+			for i := 0; i < 2; i++ {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			gateway := &fetchfyv1alpha2.Gateway{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gateway)).To(Succeed())
+			newPort := freePort()
+			gateway.Spec.Listeners[0].Port = newPort
+			Expect(k8sClient.Update(ctx, gateway)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			server, _ := controllerReconciler.MCPServers.Get(typeNamespacedName)
+			Expect(server.Addr(fetchfyv1alpha2.DefaultListenerName)).To(HaveSuffix(fmt.Sprintf(":%d", newPort)))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gateway)).To(Succeed())
+			Expect(meta.FindStatusCondition(gateway.Status.Conditions, conditionTypeProgressing)).NotTo(BeNil())
+			Expect(gateway.Status.Address).To(Equal(fmt.Sprintf(":%d", newPort)))
+		})
+
+		It("should report a listener failure when the port is taken", func() {
+			gateway := &fetchfyv1alpha2.Gateway{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gateway)).To(Succeed())
+
+			By("occupying the gateway port")
+			l, err := net.Listen("tcp", net.JoinHostPort("", "0"))
+			Expect(err).NotTo(HaveOccurred())
+			defer l.Close()
+			gateway.Spec.Listeners[0].Port = int32(l.Addr().(*net.TCPAddr).Port)
+			Expect(k8sClient.Update(ctx, gateway)).To(Succeed())
+
+			for i := 0; i < 2; i++ {
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			}
+			Expect(err).To(MatchError(mcp.ErrListenFailed))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gateway)).To(Succeed())
+			ready := meta.FindStatusCondition(gateway.Status.Conditions, conditionTypeReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal(reasonListenerFailed))
+			Expect(meta.IsStatusConditionTrue(gateway.Status.Conditions, conditionTypeDegraded)).To(BeTrue())
+
+			recorder := controllerReconciler.Recorder.(*record.FakeRecorder)
+			Expect(recorder.Events).To(Receive(ContainSubstring(reasonListenerFailed)))
+		})
+
+		It("should report each listener individually", func() {
+			gateway := &fetchfyv1alpha2.Gateway{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gateway)).To(Succeed())
+
+			By("adding a second listener on an occupied port")
+			l, err := net.Listen("tcp", net.JoinHostPort("", "0"))
+			Expect(err).NotTo(HaveOccurred())
+			defer l.Close()
+			gateway.Spec.Listeners = append(gateway.Spec.Listeners, fetchfyv1alpha2.Listener{
+				Name:     "internal",
+				Port:     int32(l.Addr().(*net.TCPAddr).Port),
+				Protocol: fetchfyv1alpha2.ProtocolHTTP,
+			})
+			Expect(k8sClient.Update(ctx, gateway)).To(Succeed())
+
+			for i := 0; i < 2; i++ {
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			}
+			Expect(err).To(MatchError(mcp.ErrListenFailed))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gateway)).To(Succeed())
+			Expect(gateway.Status.Listeners).To(HaveLen(2))
+			Expect(meta.IsStatusConditionTrue(gateway.Status.Listeners[0].Conditions, conditionTypeReady)).To(BeTrue())
+			failed := meta.FindStatusCondition(gateway.Status.Listeners[1].Conditions, conditionTypeReady)
+			Expect(failed).NotTo(BeNil())
+			Expect(failed.Status).To(Equal(metav1.ConditionFalse))
+			Expect(failed.Reason).To(Equal(reasonListenerFailed))
+
+			By("keeping the gateway available on the listener that is serving")
+			Expect(meta.IsStatusConditionTrue(gateway.Status.Conditions, conditionTypeAvailable)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(gateway.Status.Conditions, conditionTypeDegraded)).To(BeTrue())
 		})
 	})
 })
+
+var _ = Describe("Gateway readiness check", func() {
+	var (
+		ctx        context.Context
+		reconciler *GatewayReconciler
+		req        *http.Request
+	)
+
+	newGateway := func(name string, protocol fetchfyv1alpha2.ListenerProtocol) *fetchfyv1alpha2.Gateway {
+		return &fetchfyv1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: fetchfyv1alpha2.GatewaySpec{
+				Listeners: []fetchfyv1alpha2.Listener{{Name: "mcp", Port: freePort(), Protocol: protocol}},
+			},
+		}
+	}
+	serve := func(gateway *fetchfyv1alpha2.Gateway) error {
+		server, _ := reconciler.MCPServers.GetOrCreate(client.ObjectKeyFromObject(gateway))
+		server.Configure(gateway, nil)
+		return server.Sync(ctx)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		log := logf.Log.WithName("test")
+		reconciler = &GatewayReconciler{
+			Client:     fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).Build(),
+			MCPServers: mcp.NewServerManager(mcp.NewRegistry(log), log),
+			Log:        log,
+		}
+	})
+
+	AfterEach(func() {
+		for _, gateway := range reconciler.MCPServers.Gateways() {
+			_, err := reconciler.MCPServers.Remove(ctx, gateway)
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("should fail until every gateway has bound its listeners", func() {
+		bound := newGateway("bound", fetchfyv1alpha2.ProtocolHTTP)
+		pending := newGateway("pending", fetchfyv1alpha2.ProtocolHTTP)
+		Expect(reconciler.Create(ctx, bound)).To(Succeed())
+		Expect(reconciler.Create(ctx, pending)).To(Succeed())
+		Expect(serve(bound)).To(Succeed())
+
+		err := reconciler.CheckListeners(req)
+		Expect(err).To(MatchError(ContainSubstring("default/pending")))
+		Expect(err).NotTo(MatchError(ContainSubstring("default/bound")))
+
+		Expect(serve(pending)).To(Succeed())
+		Expect(reconciler.CheckListeners(req)).To(Succeed())
+	})
+
+	It("should leave invalid TLS configurations to the gateway status", func() {
+		gateway := newGateway("tls", fetchfyv1alpha2.ProtocolHTTPS)
+		Expect(reconciler.Create(ctx, gateway)).To(Succeed())
+		Expect(serve(gateway)).To(MatchError(mcp.ErrTLSConfig))
+
+		Expect(reconciler.CheckListeners(req)).To(Succeed())
+	})
+
+	It("should leave ports that can't be bound to the gateway status", func() {
+		l, err := net.Listen("tcp", net.JoinHostPort("", "0"))
+		Expect(err).NotTo(HaveOccurred())
+		defer l.Close()
+		gateway := newGateway("taken", fetchfyv1alpha2.ProtocolHTTP)
+		gateway.Spec.Listeners[0].Port = int32(l.Addr().(*net.TCPAddr).Port)
+		Expect(reconciler.Create(ctx, gateway)).To(Succeed())
+		Expect(serve(gateway)).To(MatchError(mcp.ErrListenFailed))
+
+		Expect(reconciler.CheckListeners(req)).To(Succeed())
+	})
+})
+
+var _ = Describe("Gateway registry events", func() {
+	It("should only concern the gateways that discover a changed service", func() {
+		ctx := context.Background()
+		log := logf.Log.WithName("test")
+		newGateway := func(name string, discovery *fetchfyv1alpha2.GatewayDiscovery) *fetchfyv1alpha2.Gateway {
+			return &fetchfyv1alpha2.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       fetchfyv1alpha2.GatewaySpec{Discovery: discovery},
+			}
+		}
+		c := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithObjects(
+			newGateway("tools", &fetchfyv1alpha2.GatewayDiscovery{Namespaces: []string{"tools"}}),
+			newGateway("agents", &fetchfyv1alpha2.GatewayDiscovery{Namespaces: []string{"agents"}}),
+			newGateway("platform", &fetchfyv1alpha2.GatewayDiscovery{
+				ServiceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}},
+			}),
+		).Build()
+		registry := mcp.NewRegistry(log)
+		reconciler := &GatewayReconciler{
+			Client:         c,
+			MCPRegistry:    registry,
+			ServiceWatcher: services.NewServiceWatcher(c, registry, log, k8sClient.Scheme()),
+			Log:            log,
+		}
+
+		_, err := registry.RegisterService(ctx, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "weather",
+				Namespace:   "tools",
+				Labels:      map[string]string{"team": "data"},
+				Annotations: map[string]string{mcp.EndpointAnnotation: "/mcp/tools/weather"},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}, mcp.ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		weather, _ := registry.GetService(types.NamespacedName{Name: "weather", Namespace: "tools"})
+
+		gateway := func(name string) types.NamespacedName {
+			return types.NamespacedName{Name: name, Namespace: "default"}
+		}
+		Expect(reconciler.gatewaysForServices(ctx, nil)).
+			To(ConsistOf(gateway("tools"), gateway("agents"), gateway("platform")))
+		Expect(reconciler.gatewaysForServices(ctx, []mcp.ServiceEvent{{Type: mcp.EventAdded, New: weather}})).
+			To(ConsistOf(gateway("tools")))
+
+		By("concerning the gateways that discovered the service before the change")
+		previous := *weather
+		previous.Service = weather.Service.DeepCopy()
+		previous.Service.Labels = map[string]string{"team": "platform"}
+		Expect(reconciler.gatewaysForServices(ctx, []mcp.ServiceEvent{
+			{Type: mcp.EventUpdated, Old: &previous, New: weather},
+		})).To(ConsistOf(gateway("tools"), gateway("platform")))
+	})
+})
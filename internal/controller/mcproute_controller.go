@@ -0,0 +1,407 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+	"github.com/fetchfy/fetchfy-operator/pkg/services"
+)
+
+const (
+	// Route condition types
+	conditionTypeAccepted     = "Accepted"
+	conditionTypeResolvedRefs = "ResolvedRefs"
+
+	// Route condition reasons
+	reasonAccepted              = "Accepted"
+	reasonNoMatchingParent      = "NoMatchingParent"
+	reasonNotAllowedByListeners = "NotAllowedByListeners"
+	reasonResolvedRefs          = "ResolvedRefs"
+	reasonBackendNotFound       = "BackendNotFound"
+)
+
+// MCPRouteReconciler reconciles an MCPRoute object and publishes the accepted routes to the MCP registry.
+// It runs on every replica, but only the leader writes route statuses.
+type MCPRouteReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	MCPRegistry *mcp.Registry
+	Log         logr.Logger
+
+	// Elected is closed once the replica is elected leader. Defaults to the manager's.
+	Elected <-chan struct{}
+}
+
+// +kubebuilder:rbac:groups=fetchfy.fetchfy.ai,resources=mcproutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=fetchfy.fetchfy.ai,resources=mcproutes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=fetchfy.fetchfy.ai,resources=gateways,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// Reconcile handles reconciliation of MCPRoute resources
+func (r *MCPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("mcproute", req.NamespacedName)
+
+	route := &fetchfyv1alpha2.MCPRoute{}
+	if err := r.Get(ctx, req.NamespacedName, route); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Route deleted, stop routing requests with it
+			r.MCPRegistry.DeleteRoute(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Unable to fetch MCPRoute")
+		return ctrl.Result{}, err
+	}
+	original := route.DeepCopy()
+
+	if !route.DeletionTimestamp.IsZero() {
+		r.MCPRegistry.DeleteRoute(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	rules, unresolved, err := r.resolveRules(ctx, route)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	compiled := &mcp.Route{
+		Name:      req.NamespacedName,
+		CreatedAt: route.CreationTimestamp.Time,
+		Rules:     rules,
+	}
+
+	parents := make([]fetchfyv1alpha2.RouteParentStatus, 0, len(route.Spec.ParentRefs))
+	for _, parentRef := range route.Spec.ParentRefs {
+		status := fetchfyv1alpha2.RouteParentStatus{ParentRef: parentRef}
+		for _, existing := range route.Status.Parents {
+			if existing.ParentRef == parentRef {
+				status.Conditions = existing.Conditions
+			}
+		}
+
+		gateway, reason, message, err := r.acceptParent(ctx, route, parentRef)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		accepted := metav1.Condition{
+			Type:               conditionTypeAccepted,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonAccepted,
+			Message:            "Route is attached to the Gateway",
+			ObservedGeneration: route.Generation,
+		}
+		if reason != "" {
+			accepted.Status = metav1.ConditionFalse
+			accepted.Reason = reason
+			accepted.Message = message
+		} else {
+			compiled.Parents = append(compiled.Parents, mcp.RouteParent{
+				Gateway:  gateway,
+				Listener: parentRef.SectionName,
+			})
+		}
+		meta.SetStatusCondition(&status.Conditions, accepted)
+
+		resolved := metav1.Condition{
+			Type:               conditionTypeResolvedRefs,
+			Status:             metav1.ConditionTrue,
+			Reason:             reasonResolvedRefs,
+			Message:            "All backend references are resolved",
+			ObservedGeneration: route.Generation,
+		}
+		if len(unresolved) > 0 {
+			resolved.Status = metav1.ConditionFalse
+			resolved.Reason = reasonBackendNotFound
+			resolved.Message = strings.Join(unresolved, "; ")
+		}
+		meta.SetStatusCondition(&status.Conditions, resolved)
+
+		parents = append(parents, status)
+	}
+
+	r.MCPRegistry.SetRoute(compiled)
+
+	route.Status.Parents = parents
+	if !isLeader(r.Elected) {
+		return ctrl.Result{}, nil
+	}
+	if err := patchStatus(ctx, r.Client, route, original); err != nil {
+		log.Error(err, "Failed to update MCPRoute status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// resolveRules resolves the backend references of the route's rules to Service ports.
+// Backends that can't be resolved are left out and described in the returned messages.
+func (r *MCPRouteReconciler) resolveRules(
+	ctx context.Context,
+	route *fetchfyv1alpha2.MCPRoute,
+) ([]mcp.RouteRule, []string, error) {
+	var unresolved []string
+	rules := make([]mcp.RouteRule, 0, len(route.Spec.Rules))
+	for _, rule := range route.Spec.Rules {
+		compiled := mcp.RouteRule{Matches: rule.Matches}
+		for _, ref := range rule.BackendRefs {
+			backend, problem, err := r.resolveBackend(ctx, route.Namespace, ref.Name, ref.Port)
+			if err != nil {
+				return nil, nil, err
+			}
+			if problem != "" {
+				unresolved = append(unresolved, problem)
+				continue
+			}
+			if ref.Weight != nil {
+				backend.Weight = *ref.Weight
+			}
+			compiled.Backends = append(compiled.Backends, backend)
+		}
+
+		if rule.Mirror != nil {
+			backend, problem, err := r.resolveBackend(ctx, route.Namespace, rule.Mirror.Name, rule.Mirror.Port)
+			if err != nil {
+				return nil, nil, err
+			}
+			if problem != "" {
+				unresolved = append(unresolved, "mirror "+problem)
+			} else {
+				percent := int32(100)
+				if rule.Mirror.Percent != nil {
+					percent = *rule.Mirror.Percent
+				}
+				compiled.Mirror = &mcp.Mirror{Backend: backend, Percent: percent, Audit: rule.Mirror.Audit}
+			}
+		}
+		rules = append(rules, compiled)
+	}
+	return rules, unresolved, nil
+}
+
+// resolveBackend resolves a Service port in the route's namespace to a backend of weight 1.
+// It returns a description of the problem if the Service or port doesn't exist.
+func (r *MCPRouteReconciler) resolveBackend(
+	ctx context.Context,
+	namespace, name string,
+	port *int32,
+) (mcp.Backend, string, error) {
+	svc := &corev1.Service{}
+	key := types.NamespacedName{Name: name, Namespace: namespace}
+	if err := r.Get(ctx, key, svc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return mcp.Backend{}, "", err
+		}
+		return mcp.Backend{}, fmt.Sprintf("Service %s not found", key), nil
+	}
+
+	servicePort, ok := backendPort(svc, port)
+	if !ok {
+		return mcp.Backend{}, fmt.Sprintf("Service %s has no port %s", key, describePort(port)), nil
+	}
+	return mcp.Backend{Service: key, Port: servicePort, Weight: 1}, "", nil
+}
+
+// backendPort returns the requested port of the Service, or its first port if none is requested
+func backendPort(svc *corev1.Service, port *int32) (int32, bool) {
+	for _, servicePort := range svc.Spec.Ports {
+		if port == nil || servicePort.Port == *port {
+			return servicePort.Port, true
+		}
+	}
+	return 0, false
+}
+
+// describePort formats a requested backend port for condition messages
+func describePort(port *int32) string {
+	if port == nil {
+		return "to route to"
+	}
+	return fmt.Sprint(*port)
+}
+
+// acceptParent checks whether the parent Gateway accepts the route. It returns the
+// Gateway's name, or the reason and message of the rejection.
+func (r *MCPRouteReconciler) acceptParent(
+	ctx context.Context,
+	route *fetchfyv1alpha2.MCPRoute,
+	parentRef fetchfyv1alpha2.ParentReference,
+) (types.NamespacedName, string, string, error) {
+	key := types.NamespacedName{Name: parentRef.Name, Namespace: parentRef.Namespace}
+	if key.Namespace == "" {
+		key.Namespace = route.Namespace
+	}
+
+	gateway := &fetchfyv1alpha2.Gateway{}
+	if err := r.Get(ctx, key, gateway); err != nil {
+		if apierrors.IsNotFound(err) {
+			return key, reasonNoMatchingParent, fmt.Sprintf("Gateway %s not found", key), nil
+		}
+		return key, "", "", err
+	}
+
+	if parentRef.SectionName != "" {
+		found := false
+		for _, listener := range gateway.Spec.Listeners {
+			found = found || listener.Name == parentRef.SectionName
+		}
+		if !found {
+			return key, reasonNoMatchingParent,
+				fmt.Sprintf("Gateway %s has no listener %s", key, parentRef.SectionName), nil
+		}
+	}
+
+	allowed, err := r.routeAllowed(ctx, gateway, route.Namespace)
+	if err != nil {
+		return key, "", "", err
+	}
+	if !allowed {
+		return key, reasonNotAllowedByListeners,
+			fmt.Sprintf("Gateway %s doesn't allow routes from namespace %s", key, route.Namespace), nil
+	}
+
+	return key, "", "", nil
+}
+
+// routeAllowed reports whether the Gateway's routing.allowedRoutes admits routes from the namespace
+func (r *MCPRouteReconciler) routeAllowed(
+	ctx context.Context,
+	gateway *fetchfyv1alpha2.Gateway,
+	namespace string,
+) (bool, error) {
+	from := fetchfyv1alpha2.NamespacesFromSame
+	var selector *metav1.LabelSelector
+	if gateway.Spec.Routing != nil && gateway.Spec.Routing.AllowedRoutes != nil {
+		from = gateway.Spec.Routing.AllowedRoutes.From
+		selector = gateway.Spec.Routing.AllowedRoutes.Selector
+	}
+
+	switch from {
+	case fetchfyv1alpha2.NamespacesFromAll:
+		return true, nil
+	case fetchfyv1alpha2.NamespacesFromSelector:
+		if selector == nil {
+			return false, nil
+		}
+		labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return false, nil
+		}
+		ns := &corev1.Namespace{}
+		if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return labelSelector.Matches(labels.Set(ns.Labels)), nil
+	default:
+		return namespace == gateway.Namespace, nil
+	}
+}
+
+// routesForGateway maps a Gateway to the routes that reference it
+func (r *MCPRouteReconciler) routesForGateway(ctx context.Context, obj client.Object) []reconcile.Request {
+	routes := &fetchfyv1alpha2.MCPRouteList{}
+	if err := r.List(ctx, routes); err != nil {
+		r.Log.Error(err, "Failed to list MCPRoutes")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, route := range routes.Items {
+		for _, parentRef := range route.Spec.ParentRefs {
+			namespace := parentRef.Namespace
+			if namespace == "" {
+				namespace = route.Namespace
+			}
+			if parentRef.Name == obj.GetName() && namespace == obj.GetNamespace() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: route.Name, Namespace: route.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// routesForService maps a Service to the routes in its namespace that use it as a backend or mirror
+func (r *MCPRouteReconciler) routesForService(ctx context.Context, obj client.Object) []reconcile.Request {
+	routes := &fetchfyv1alpha2.MCPRouteList{}
+	if err := r.List(ctx, routes, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "Failed to list MCPRoutes")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, route := range routes.Items {
+		if routeUsesService(&route, obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: route.Name, Namespace: route.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// routeUsesService reports whether any rule of the route refers to the Service, as a backend or mirror
+func routeUsesService(route *fetchfyv1alpha2.MCPRoute, name string) bool {
+	for _, rule := range route.Spec.Rules {
+		if rule.Mirror != nil && rule.Mirror.Name == name {
+			return true
+		}
+		for _, ref := range rule.BackendRefs {
+			if ref.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MCPRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Log.GetSink() == nil {
+		r.Log = logf.Log.WithName("mcproute-controller")
+	}
+	if r.Elected == nil {
+		r.Elected = mgr.Elected()
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(services.AllReplicas()).
+		For(&fetchfyv1alpha2.MCPRoute{}).
+		Watches(&fetchfyv1alpha2.Gateway{}, handler.EnqueueRequestsFromMapFunc(r.routesForGateway)).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(r.routesForService)).
+		Complete(r)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+)
+
+var _ = Describe("MCPRoute Controller", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		routeName := types.NamespacedName{Name: "weather-route", Namespace: "default"}
+		gatewayName := types.NamespacedName{Name: "route-gateway", Namespace: "default"}
+
+		var (
+			reconciler *MCPRouteReconciler
+			route      *fetchfyv1alpha2.MCPRoute
+			gateway    *fetchfyv1alpha2.Gateway
+			service    *corev1.Service
+		)
+
+		BeforeEach(func() {
+			log := logf.Log.WithName("test")
+			reconciler = &MCPRouteReconciler{
+				Client:      k8sClient,
+				Scheme:      k8sClient.Scheme(),
+				MCPRegistry: mcp.NewRegistry(log),
+				Log:         log,
+			}
+
+			gateway = &fetchfyv1alpha2.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: gatewayName.Name, Namespace: gatewayName.Namespace},
+				Spec: fetchfyv1alpha2.GatewaySpec{
+					Listeners: []fetchfyv1alpha2.Listener{{Name: "mcp", Port: freePort()}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, gateway)).To(Succeed())
+
+			service = &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "default"},
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+			}
+			Expect(k8sClient.Create(ctx, service)).To(Succeed())
+
+			route = &fetchfyv1alpha2.MCPRoute{
+				ObjectMeta: metav1.ObjectMeta{Name: routeName.Name, Namespace: routeName.Namespace},
+				Spec: fetchfyv1alpha2.MCPRouteSpec{
+					ParentRefs: []fetchfyv1alpha2.ParentReference{{Name: gatewayName.Name, SectionName: "mcp"}},
+					Rules: []fetchfyv1alpha2.MCPRouteRule{{
+						Matches:     []fetchfyv1alpha2.MCPRouteMatch{{Method: "tools/call", Tool: "get_forecast"}},
+						BackendRefs: []fetchfyv1alpha2.MCPBackendRef{{Name: "weather"}},
+					}},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, route)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, service)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, gateway)).To(Succeed())
+
+			By("removing the deleted route from the registry")
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: routeName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciler.MCPRegistry.RoutesFor(gatewayName)).To(BeEmpty())
+		})
+
+		parentConditions := func() []metav1.Condition {
+			Expect(k8sClient.Get(ctx, routeName, route)).To(Succeed())
+			Expect(route.Status.Parents).To(HaveLen(1))
+			return route.Status.Parents[0].Conditions
+		}
+
+		It("should attach the route to the gateway and resolve its backends", func() {
+			Expect(k8sClient.Create(ctx, route)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: routeName})
+			Expect(err).NotTo(HaveOccurred())
+
+			conditions := parentConditions()
+			Expect(meta.IsStatusConditionTrue(conditions, conditionTypeAccepted)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(conditions, conditionTypeResolvedRefs)).To(BeTrue())
+
+			routes := reconciler.MCPRegistry.RoutesFor(gatewayName)
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Parents).To(ConsistOf(mcp.RouteParent{Gateway: gatewayName, Listener: "mcp"}))
+			Expect(routes[0].Rules[0].Backends).To(ConsistOf(mcp.Backend{
+				Service: types.NamespacedName{Name: "weather", Namespace: "default"},
+				Port:    8080,
+				Weight:  1,
+			}))
+		})
+
+		It("should not attach the route to a listener the gateway doesn't have", func() {
+			route.Spec.ParentRefs[0].SectionName = "internal"
+			Expect(k8sClient.Create(ctx, route)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: routeName})
+			Expect(err).NotTo(HaveOccurred())
+
+			accepted := meta.FindStatusCondition(parentConditions(), conditionTypeAccepted)
+			Expect(accepted).NotTo(BeNil())
+			Expect(accepted.Status).To(Equal(metav1.ConditionFalse))
+			Expect(accepted.Reason).To(Equal(reasonNoMatchingParent))
+			Expect(reconciler.MCPRegistry.RoutesFor(gatewayName)).To(BeEmpty())
+		})
+
+		It("should report backends that don't exist", func() {
+			route.Spec.Rules[0].BackendRefs = append(route.Spec.Rules[0].BackendRefs,
+				fetchfyv1alpha2.MCPBackendRef{Name: "weather-v2"})
+			Expect(k8sClient.Create(ctx, route)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: routeName})
+			Expect(err).NotTo(HaveOccurred())
+
+			resolved := meta.FindStatusCondition(parentConditions(), conditionTypeResolvedRefs)
+			Expect(resolved).NotTo(BeNil())
+			Expect(resolved.Status).To(Equal(metav1.ConditionFalse))
+			Expect(resolved.Message).To(ContainSubstring("default/weather-v2 not found"))
+
+			By("still routing to the backends that exist")
+			routes := reconciler.MCPRegistry.RoutesFor(gatewayName)
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Rules[0].Backends).To(HaveLen(1))
+		})
+
+		It("should resolve the mirror backend", func() {
+			route.Spec.Rules[0].Mirror = &fetchfyv1alpha2.MCPRouteMirror{Name: "weather", Audit: true}
+			Expect(k8sClient.Create(ctx, route)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: routeName})
+			Expect(err).NotTo(HaveOccurred())
+
+			routes := reconciler.MCPRegistry.RoutesFor(gatewayName)
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Rules[0].Mirror).To(Equal(&mcp.Mirror{
+				Backend: mcp.Backend{Service: types.NamespacedName{Name: "weather", Namespace: "default"}, Port: 8080, Weight: 1},
+				Percent: 100,
+				Audit:   true,
+			}))
+
+			By("dropping a mirror whose Service doesn't exist")
+			Expect(k8sClient.Get(ctx, routeName, route)).To(Succeed())
+			route.Spec.Rules[0].Mirror.Name = "weather-v2"
+			Expect(k8sClient.Update(ctx, route)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: routeName})
+			Expect(err).NotTo(HaveOccurred())
+
+			resolved := meta.FindStatusCondition(parentConditions(), conditionTypeResolvedRefs)
+			Expect(resolved).NotTo(BeNil())
+			Expect(resolved.Status).To(Equal(metav1.ConditionFalse))
+			Expect(resolved.Message).To(ContainSubstring("mirror Service default/weather-v2 not found"))
+			Expect(reconciler.MCPRegistry.RoutesFor(gatewayName)[0].Rules[0].Mirror).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,118 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// fieldManager is the field manager of all writes of the operator's controllers
+const fieldManager = "fetchfy-operator"
+
+// patchStatus writes the status changes made to obj since it was read as original. The status
+// is patched with an optimistic lock, so a replica working on an outdated object doesn't
+// overwrite a newer status. On conflict, the changes are replayed on the latest object and
+// written again. Nothing is written when the status is unchanged.
+func patchStatus(ctx context.Context, c client.Client, obj, original client.Object) error {
+	changes, err := client.MergeFrom(original).Data(obj)
+	if err != nil {
+		return err
+	}
+	if string(changes) == "{}" {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		patch := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+		err := c.Status().Patch(ctx, obj, patch, client.FieldOwner(fieldManager))
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+		latest := original.DeepCopyObject().(client.Object)
+		if getErr := c.Get(ctx, client.ObjectKeyFromObject(obj), latest); getErr != nil {
+			return getErr
+		}
+		if replayErr := replayChanges(latest, changes, obj); replayErr != nil {
+			return replayErr
+		}
+		original = latest
+		return err
+	})
+}
+
+// replayChanges sets obj to the latest object with the merge patch of changes applied
+func replayChanges(latest client.Object, changes []byte, obj client.Object) error {
+	data, err := json.Marshal(latest)
+	if err != nil {
+		return err
+	}
+	merged, err := jsonpatch.MergePatch(data, changes)
+	if err != nil {
+		return err
+	}
+	reflect.ValueOf(obj).Elem().SetZero()
+	return json.Unmarshal(merged, obj)
+}
+
+// setFinalizer adds or removes the finalizer of obj. The finalizers are patched with an
+// optimistic lock, so finalizers added concurrently by others are kept, and the write is
+// retried on the latest object on conflict.
+func setFinalizer(ctx context.Context, c client.Client, obj client.Object, finalizer string, present bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if controllerutil.ContainsFinalizer(obj, finalizer) == present {
+			return nil
+		}
+		original := obj.DeepCopyObject().(client.Object)
+		if present {
+			controllerutil.AddFinalizer(obj, finalizer)
+		} else {
+			controllerutil.RemoveFinalizer(obj, finalizer)
+		}
+
+		patch := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+		err := c.Patch(ctx, obj, patch, client.FieldOwner(fieldManager))
+		if apierrors.IsConflict(err) {
+			if getErr := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); getErr != nil {
+				return getErr
+			}
+		}
+		return err
+	})
+}
+
+// isLeader reports whether the replica won the leader election, i.e. whether elected is closed.
+// Controllers that run on every replica only write to the API server on the leader. Without a
+// channel, e.g. in tests, the replica is the leader.
+func isLeader(elected <-chan struct{}) bool {
+	if elected == nil {
+		return true
+	}
+	select {
+	case <-elected:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+)
+
+var _ = Describe("Status writes", func() {
+	var (
+		ctx           context.Context
+		c             client.Client
+		statusPatches int
+		key           client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		gateway := &fetchfyv1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "status", Namespace: "default", Finalizers: []string{"example.com/other"}},
+		}
+		key = client.ObjectKeyFromObject(gateway)
+		statusPatches = 0
+		c = fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).
+			WithObjects(gateway).WithStatusSubresource(gateway).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object,
+					patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					statusPatches++
+					return c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+				},
+			}).Build()
+	})
+
+	It("should only write changed statuses", func() {
+		gateway := &fetchfyv1alpha2.Gateway{}
+		Expect(c.Get(ctx, key, gateway)).To(Succeed())
+		original := gateway.DeepCopy()
+
+		Expect(patchStatus(ctx, c, gateway, original)).To(Succeed())
+		Expect(statusPatches).To(BeZero())
+
+		gateway.Status.Address = ":8080"
+		Expect(patchStatus(ctx, c, gateway, original)).To(Succeed())
+		Expect(statusPatches).To(Equal(1))
+		Expect(c.Get(ctx, key, gateway)).To(Succeed())
+		Expect(gateway.Status.Address).To(Equal(":8080"))
+	})
+
+	It("should replay status changes on the latest object on conflict", func() {
+		gateway := &fetchfyv1alpha2.Gateway{}
+		Expect(c.Get(ctx, key, gateway)).To(Succeed())
+		original := gateway.DeepCopy()
+
+		newer := gateway.DeepCopy()
+		newer.Status.Address = ":9090"
+		Expect(c.Status().Update(ctx, newer)).To(Succeed())
+
+		meta.SetStatusCondition(&gateway.Status.Conditions, metav1.Condition{
+			Type: conditionTypeReady, Status: metav1.ConditionTrue, Reason: "Ready", Message: "Serving",
+		})
+		Expect(patchStatus(ctx, c, gateway, original)).To(Succeed())
+		Expect(statusPatches).To(Equal(2))
+
+		Expect(c.Get(ctx, key, gateway)).To(Succeed())
+		Expect(gateway.Status.Address).To(Equal(":9090"))
+		Expect(meta.IsStatusConditionTrue(gateway.Status.Conditions, conditionTypeReady)).To(BeTrue())
+	})
+
+	It("should keep finalizers added concurrently by others", func() {
+		gateway := &fetchfyv1alpha2.Gateway{}
+		Expect(c.Get(ctx, key, gateway)).To(Succeed())
+		outdated := gateway.DeepCopy()
+
+		Expect(setFinalizer(ctx, c, gateway, gatewayFinalizer, true)).To(Succeed())
+		Expect(setFinalizer(ctx, c, outdated, "example.com/third", true)).To(Succeed())
+
+		Expect(c.Get(ctx, key, gateway)).To(Succeed())
+		Expect(gateway.Finalizers).To(ConsistOf("example.com/other", gatewayFinalizer, "example.com/third"))
+
+		Expect(setFinalizer(ctx, c, gateway, gatewayFinalizer, false)).To(Succeed())
+		Expect(c.Get(ctx, key, gateway)).To(Succeed())
+		Expect(gateway.Finalizers).To(ConsistOf("example.com/other", "example.com/third"))
+	})
+})
+
+var _ = Describe("Catalog writes", func() {
+	It("should not rewrite an unchanged catalog", func() {
+		ctx := context.Background()
+		gateway := &fetchfyv1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "catalog", Namespace: "default", UID: "catalog-uid"},
+			Spec:       fetchfyv1alpha2.GatewaySpec{Discovery: &fetchfyv1alpha2.GatewayDiscovery{Catalog: true}},
+		}
+		reconciler := &GatewayReconciler{
+			Client: fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithObjects(gateway).Build(),
+			Scheme: k8sClient.Scheme(),
+		}
+
+		registry := mcp.NewRegistry(logf.Log.WithName("test"))
+		_, err := registry.RegisterService(ctx, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "weather",
+				Namespace:   "tools",
+				Annotations: map[string]string{mcp.EndpointAnnotation: "/mcp/tools/weather"},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}, mcp.ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		mcpServices := registry.UpdateRegistryStatus(gateway, nil)
+		Expect(mcpServices).To(HaveLen(1))
+
+		catalog := &fetchfyv1alpha2.MCPServiceCatalog{}
+		key := client.ObjectKeyFromObject(gateway)
+		Expect(reconciler.reconcileCatalog(ctx, gateway, mcpServices)).To(Succeed())
+		Expect(reconciler.Get(ctx, key, catalog)).To(Succeed())
+		resourceVersion := catalog.ResourceVersion
+
+		Expect(reconciler.reconcileCatalog(ctx, gateway, registry.UpdateRegistryStatus(gateway, nil))).To(Succeed())
+		Expect(reconciler.Get(ctx, key, catalog)).To(Succeed())
+		Expect(catalog.ResourceVersion).To(Equal(resourceVersion))
+	})
+})
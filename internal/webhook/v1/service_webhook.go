@@ -0,0 +1,337 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+	"github.com/fetchfy/fetchfy-operator/pkg/services"
+)
+
+// mcpAnnotationPrefix is the prefix of the annotations interpreted on MCP-enabled Services
+const mcpAnnotationPrefix = "mcp.fetchfy.ai/"
+
+// knownAnnotations are the mcp.fetchfy.ai annotations supported on Services
+var knownAnnotations = []string{
+	services.MCPTypeAnnotation,
+	services.MCPEndpointAnnotation,
+	services.MCPDescriptionAnnotation,
+	services.MCPVersionAnnotation,
+	services.MCPTimeoutAnnotation,
+	services.MCPCacheTTLAnnotation,
+	services.MCPCacheResourcesAnnotation,
+	services.MCPPortAnnotation,
+	services.MCPSchemeAnnotation,
+	services.MCPBasePathAnnotation,
+}
+
+// nolint:unused
+// log is for logging in this package.
+var servicelog = logf.Log.WithName("service-resource")
+
+// SetupServiceWebhookWithManager registers the webhook for Service in the manager. In warn-only
+// mode, problems are returned as admission warnings instead of rejecting the Service.
+func SetupServiceWebhookWithManager(mgr ctrl.Manager, warnOnly bool) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&corev1.Service{}).
+		WithValidator(&ServiceCustomValidator{Client: mgr.GetClient(), WarnOnly: warnOnly}).
+		Complete()
+}
+
+// The webhook only receives Services labelled mcp-enabled=true (see config/webhook/service_webhook_patch.yaml)
+// and fails open so that an unavailable operator never blocks Service changes.
+// +kubebuilder:webhook:path=/validate--v1-service,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=services,verbs=create;update,versions=v1,name=vservice-v1.kb.io,admissionReviewVersions=v1
+
+// ServiceCustomValidator struct is responsible for validating the mcp.fetchfy.ai annotations
+// of MCP-enabled Services when they are created or updated.
+type ServiceCustomValidator struct {
+	// Client is used to find endpoint conflicts with other MCP-enabled Services
+	Client client.Reader
+
+	// WarnOnly returns problems as warnings instead of rejecting the Service
+	WarnOnly bool
+}
+
+var _ webhook.CustomValidator = &ServiceCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Service.
+func (v *ServiceCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	service, ok := obj.(*corev1.Service)
+	if !ok {
+		return nil, fmt.Errorf("expected a Service object but got %T", obj)
+	}
+	servicelog.Info("Validation for Service upon creation", "name", service.GetName())
+
+	return v.validateService(ctx, service)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Service.
+func (v *ServiceCustomValidator) ValidateUpdate(
+	ctx context.Context,
+	oldObj, newObj runtime.Object,
+) (admission.Warnings, error) {
+	service, ok := newObj.(*corev1.Service)
+	if !ok {
+		return nil, fmt.Errorf("expected a Service object for the newObj but got %T", newObj)
+	}
+	servicelog.Info("Validation for Service upon update", "name", service.GetName())
+
+	return v.validateService(ctx, service)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Service.
+func (v *ServiceCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateService validates the mcp.fetchfy.ai annotations of an MCP-enabled Service
+func (v *ServiceCustomValidator) validateService(
+	ctx context.Context,
+	service *corev1.Service,
+) (admission.Warnings, error) {
+	// The webhook is scoped by an object selector, but a Service that just lost the
+	// label is sent too, and it no longer needs to be valid
+	if !services.IsMCPEnabledService(service) {
+		return nil, nil
+	}
+
+	allErrs := validateAnnotations(service.Annotations)
+	allErrs = append(allErrs, validatePort(service)...)
+
+	conflictErr, err := v.validateEndpointConflict(ctx, service)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	if conflictErr != nil {
+		allErrs = append(allErrs, conflictErr)
+	}
+
+	if len(allErrs) == 0 {
+		return nil, nil
+	}
+
+	if v.WarnOnly {
+		warnings := make(admission.Warnings, 0, len(allErrs))
+		for _, fieldErr := range allErrs {
+			warnings = append(warnings, fieldErr.Error())
+		}
+		return warnings, nil
+	}
+
+	return nil, apierrors.NewInvalid(corev1.SchemeGroupVersion.WithKind("Service").GroupKind(), service.Name, allErrs)
+}
+
+// validateAnnotations checks the mcp.fetchfy.ai annotation keys and values
+func validateAnnotations(annotations map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+	annotationsPath := field.NewPath("metadata", "annotations")
+
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		if strings.HasPrefix(key, mcpAnnotationPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := annotations[key]
+		path := annotationsPath.Key(key)
+
+		switch key {
+		case services.MCPTypeAnnotation:
+			allErrs = append(allErrs, validateType(path, value)...)
+		case services.MCPEndpointAnnotation:
+			allErrs = append(allErrs, validateEndpoint(path, value)...)
+		case services.MCPTimeoutAnnotation:
+			if seconds, err := strconv.Atoi(value); err != nil || seconds <= 0 {
+				allErrs = append(allErrs, field.Invalid(path, value, "timeout must be a positive number of seconds, e.g. \"60\""))
+			}
+		case services.MCPCacheTTLAnnotation:
+			if ttl, err := time.ParseDuration(value); err != nil || ttl <= 0 {
+				allErrs = append(allErrs, field.Invalid(path, value, "cache TTL must be a positive duration, e.g. \"30s\""))
+			}
+		case services.MCPCacheResourcesAnnotation:
+			if _, ok := annotations[services.MCPCacheTTLAnnotation]; !ok {
+				allErrs = append(allErrs, field.Invalid(path, value,
+					fmt.Sprintf("resource caching requires the %s annotation", services.MCPCacheTTLAnnotation)))
+			} else if strings.TrimSpace(strings.ReplaceAll(value, ",", "")) == "" {
+				allErrs = append(allErrs, field.Invalid(path, value, "must list at least one resource URI prefix"))
+			}
+		case services.MCPSchemeAnnotation:
+			if value != "http" && value != "https" {
+				allErrs = append(allErrs, field.NotSupported(path, value, []string{"http", "https"}))
+			}
+		case services.MCPBasePathAnnotation:
+			if !strings.HasPrefix(value, "/") || strings.ContainsAny(value, "?# \t\n") {
+				allErrs = append(allErrs, field.Invalid(path, value,
+					"base path must be an absolute path without query or fragment, e.g. \"/mcp\""))
+			}
+		case services.MCPDescriptionAnnotation, services.MCPVersionAnnotation, services.MCPPortAnnotation:
+		default:
+			detail := fmt.Sprintf("unknown annotation, supported annotations are %s", strings.Join(knownAnnotations, ", "))
+			if suggestion := closest(key, knownAnnotations); suggestion != "" {
+				detail = fmt.Sprintf("unknown annotation, did you mean %q?", suggestion)
+			}
+			allErrs = append(allErrs, field.Invalid(path, value, detail))
+		}
+	}
+
+	return allErrs
+}
+
+// validateType checks the value of the type annotation
+func validateType(path *field.Path, value string) field.ErrorList {
+	supported := []string{string(mcp.ServiceTypeTool), string(mcp.ServiceTypeAgent)}
+	for _, serviceType := range supported {
+		if value == serviceType {
+			return nil
+		}
+	}
+
+	if suggestion := closest(value, supported); suggestion != "" {
+		return field.ErrorList{field.NotSupported(path, value, []string{suggestion})}
+	}
+	return field.ErrorList{field.NotSupported(path, value, supported)}
+}
+
+// validateEndpoint checks that the endpoint annotation is a clean path served by the gateway
+func validateEndpoint(path *field.Path, value string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !strings.HasPrefix(value, mcp.MCPBasePath) || len(value) == len(mcp.MCPBasePath) {
+		allErrs = append(allErrs, field.Invalid(path, value,
+			fmt.Sprintf("endpoint must be a path below %s, e.g. %stools/my-tool", mcp.MCPBasePath, mcp.MCPBasePath)))
+	}
+
+	parsed, err := url.Parse(value)
+	switch {
+	case err != nil:
+		allErrs = append(allErrs, field.Invalid(path, value, "endpoint is not a valid URL path: "+err.Error()))
+	case parsed.RawQuery != "" || parsed.Fragment != "" || strings.ContainsAny(value, "?#"):
+		allErrs = append(allErrs, field.Invalid(path, value, "endpoint must not contain a query or fragment"))
+	case strings.ContainsAny(value, " \t\n"):
+		allErrs = append(allErrs, field.Invalid(path, value, "endpoint must not contain whitespace"))
+	case strings.Contains(value, "//") || strings.Contains(value, "/./") || strings.Contains(value, "/../") ||
+		strings.HasSuffix(value, "/.") || strings.HasSuffix(value, "/.."):
+		allErrs = append(allErrs, field.Invalid(path, value, "endpoint must not contain empty, '.' or '..' segments"))
+	}
+
+	return allErrs
+}
+
+// validatePort checks that the Service port MCP requests are sent to can be selected
+func validatePort(service *corev1.Service) field.ErrorList {
+	_, annotated := service.Annotations[services.MCPPortAnnotation]
+	// ExternalName Services without ports use the default port of the scheme
+	if service.Spec.Type == corev1.ServiceTypeExternalName && len(service.Spec.Ports) == 0 && !annotated {
+		return nil
+	}
+
+	if _, err := mcp.ServicePort(service); err != nil {
+		if annotated {
+			path := field.NewPath("metadata", "annotations").Key(services.MCPPortAnnotation)
+			return field.ErrorList{field.Invalid(path, service.Annotations[services.MCPPortAnnotation], err.Error())}
+		}
+		return field.ErrorList{field.Invalid(field.NewPath("spec", "ports"), len(service.Spec.Ports), err.Error())}
+	}
+	return nil
+}
+
+// validateEndpointConflict rejects an endpoint that overlaps the endpoint of another MCP-enabled Service
+func (v *ServiceCustomValidator) validateEndpointConflict(
+	ctx context.Context,
+	service *corev1.Service,
+) (*field.Error, error) {
+	if v.Client == nil {
+		return nil, nil
+	}
+
+	serviceList := &corev1.ServiceList{}
+	if err := v.Client.List(ctx, serviceList, client.MatchingLabels{services.MCPEnabledLabel: "true"}); err != nil {
+		return nil, fmt.Errorf("failed to list MCP-enabled Services: %w", err)
+	}
+
+	endpoint := mcp.ServiceEndpoint(service)
+	for _, other := range serviceList.Items {
+		if other.Namespace == service.Namespace && other.Name == service.Name {
+			continue
+		}
+		if otherEndpoint := mcp.ServiceEndpoint(&other); mcp.EndpointsOverlap(endpoint, otherEndpoint) {
+			detail := fmt.Sprintf("endpoint is already used by Service %s/%s, set a unique %s",
+				other.Namespace, other.Name, services.MCPEndpointAnnotation)
+			if otherEndpoint != endpoint {
+				detail = fmt.Sprintf("endpoint overlaps endpoint %s of Service %s/%s, endpoints must not be path "+
+					"prefixes of each other", otherEndpoint, other.Namespace, other.Name)
+			}
+			return field.Invalid(field.NewPath("metadata", "annotations").Key(services.MCPEndpointAnnotation), endpoint,
+				detail), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// closest returns the candidate within two edits of s, or an empty string if there is none
+func closest(s string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if d := editDistance(s, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
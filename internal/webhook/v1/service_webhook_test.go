@@ -0,0 +1,180 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/services"
+)
+
+var _ = Describe("Service Webhook", func() {
+	var (
+		obj       *corev1.Service
+		oldObj    *corev1.Service
+		validator ServiceCustomValidator
+	)
+
+	BeforeEach(func() {
+		obj = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "weather",
+				Namespace: "default",
+				Labels:    map[string]string{services.MCPEnabledLabel: "true"},
+				Annotations: map[string]string{
+					services.MCPTypeAnnotation:     "tool",
+					services.MCPEndpointAnnotation: "/mcp/tools/weather",
+				},
+			},
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+		}
+		oldObj = obj.DeepCopy()
+		validator = ServiceCustomValidator{
+			Client: fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).Build(),
+		}
+	})
+
+	Context("When creating or updating Service under Validating Webhook", func() {
+		It("Should admit a valid MCP-enabled Service", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should ignore Services that are not MCP-enabled", func() {
+			obj.Labels = nil
+			obj.Annotations[services.MCPTypeAnnotation] = "database"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny an unsupported type and suggest the closest one", func() {
+			obj.Annotations[services.MCPTypeAnnotation] = "agnet"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring(`supported values: "agent"`)))
+		})
+
+		It("Should deny an endpoint outside of the MCP base path", func() {
+			obj.Annotations[services.MCPEndpointAnnotation] = "/tools/weather"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("endpoint must be a path below /mcp/")))
+		})
+
+		It("Should deny an endpoint with a query or dot segments", func() {
+			obj.Annotations[services.MCPEndpointAnnotation] = "/mcp/tools?name=weather"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("must not contain a query")))
+
+			obj.Annotations[services.MCPEndpointAnnotation] = "/mcp/../admin"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("'..' segments")))
+		})
+
+		It("Should deny a misspelled annotation", func() {
+			obj.Annotations["mcp.fetchfy.ai/endpont"] = "/mcp/tools/weather"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring(`did you mean "mcp.fetchfy.ai/endpoint"?`)))
+		})
+
+		It("Should validate the documented optional annotations", func() {
+			obj.Annotations[services.MCPDescriptionAnnotation] = "Weather forecasts"
+			obj.Annotations[services.MCPTimeoutAnnotation] = "30"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Annotations[services.MCPTimeoutAnnotation] = "30s"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("timeout must be a positive number of seconds")))
+		})
+
+		It("Should validate the cache annotations", func() {
+			obj.Annotations[services.MCPCacheResourcesAnnotation] = "docs://"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("resource caching requires the mcp.fetchfy.ai/cache-ttl annotation")))
+
+			obj.Annotations[services.MCPCacheTTLAnnotation] = "30"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("cache TTL must be a positive duration")))
+
+			obj.Annotations[services.MCPCacheTTLAnnotation] = "30s"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny an ambiguous or unknown port", func() {
+			obj.Spec.Ports = append(obj.Spec.Ports, corev1.ServicePort{Name: "metrics", Port: 9090})
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring(`service has 2 ports, name one "mcp"`)))
+
+			obj.Annotations[services.MCPPortAnnotation] = "grpc"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring(`mcp.fetchfy.ai/port "grpc" matches no port`)))
+
+			obj.Annotations[services.MCPPortAnnotation] = "80"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should validate the scheme and base path annotations", func() {
+			obj.Annotations[services.MCPSchemeAnnotation] = "grpc"
+			obj.Annotations[services.MCPBasePathAnnotation] = "mcp"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring(`supported values: "http", "https"`)))
+			Expect(err).To(MatchError(ContainSubstring("base path must be an absolute path")))
+
+			obj.Annotations[services.MCPSchemeAnnotation] = "https"
+			obj.Annotations[services.MCPBasePathAnnotation] = "/mcp"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should admit an ExternalName Service without ports", func() {
+			obj.Spec = corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "mcp.example.com"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny an endpoint used by another Service", func() {
+			other := obj.DeepCopy()
+			other.Name = "forecast"
+			other.Namespace = "team-a"
+			validator.Client = fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithObjects(other).Build()
+
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("endpoint is already used by Service team-a/forecast")))
+
+			By("admitting an update of the Service that owns the endpoint")
+			Expect(validator.ValidateUpdate(ctx, other, other)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny an endpoint nested below another Service's endpoint", func() {
+			other := obj.DeepCopy()
+			other.Name = "tools"
+			other.Annotations[services.MCPEndpointAnnotation] = "/mcp/tools"
+			validator.Client = fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithObjects(other).Build()
+
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("endpoint overlaps endpoint /mcp/tools of Service default/tools")))
+		})
+
+		It("Should only warn in warn-only mode", func() {
+			validator.WarnOnly = true
+			obj.Annotations[services.MCPTypeAnnotation] = "database"
+
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring(services.MCPTypeAnnotation)))
+		})
+	})
+})
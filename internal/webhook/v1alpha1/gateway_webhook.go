@@ -0,0 +1,294 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	fetchfyv1alpha1 "github.com/fetchfy/fetchfy-operator/api/v1alpha1"
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/services"
+)
+
+const (
+	// DefaultMCPPort is the port a Gateway listens on when spec.mcpPort is not set
+	DefaultMCPPort int32 = 8080
+
+	// annotationDomain is the domain of the annotations interpreted by the operator
+	annotationDomain = "fetchfy.ai"
+)
+
+// serviceAnnotations are the operator annotations that only apply to Services
+var serviceAnnotations = []string{
+	services.MCPTypeAnnotation,
+	services.MCPEndpointAnnotation,
+	services.MCPDescriptionAnnotation,
+	services.MCPVersionAnnotation,
+	services.MCPTimeoutAnnotation,
+	services.MCPCacheTTLAnnotation,
+	services.MCPCacheResourcesAnnotation,
+	services.MCPPortAnnotation,
+	services.MCPSchemeAnnotation,
+	services.MCPBasePathAnnotation,
+}
+
+// nolint:unused
+// log is for logging in this package.
+var gatewaylog = logf.Log.WithName("gateway-resource")
+
+// SetupGatewayWebhookWithManager registers the webhook for Gateway in the manager. Gateways may not
+// listen on the reserved ports, the ports of the manager's own servers keyed by the server's name.
+func SetupGatewayWebhookWithManager(mgr ctrl.Manager, reservedPorts map[int32]string) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&fetchfyv1alpha1.Gateway{}).
+		WithValidator(&GatewayCustomValidator{Client: mgr.GetClient(), ReservedPorts: reservedPorts}).
+		WithDefaulter(&GatewayCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-fetchfy-fetchfy-ai-v1alpha1-gateway,mutating=true,failurePolicy=fail,sideEffects=None,groups=fetchfy.fetchfy.ai,resources=gateways,verbs=create;update,versions=v1alpha1,name=mgateway-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// GatewayCustomDefaulter struct is responsible for setting default values on the custom resource of the
+// Kind Gateway when those are created or updated.
+type GatewayCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &GatewayCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind Gateway.
+func (d *GatewayCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	gateway, ok := obj.(*fetchfyv1alpha1.Gateway)
+	if !ok {
+		return fmt.Errorf("expected a Gateway object but got %T", obj)
+	}
+	gatewaylog.Info("Defaulting for Gateway", "name", gateway.GetName())
+
+	if gateway.Spec.MCPPort == 0 {
+		gateway.Spec.MCPPort = DefaultMCPPort
+	}
+
+	// An empty selector would match every Service in the cluster
+	selector := &gateway.Spec.ServiceSelector
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		selector.MatchLabels = map[string]string{services.MCPEnabledLabel: "true"}
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-fetchfy-fetchfy-ai-v1alpha1-gateway,mutating=false,failurePolicy=fail,sideEffects=None,groups=fetchfy.fetchfy.ai,resources=gateways,verbs=create;update,versions=v1alpha1,name=vgateway-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// GatewayCustomValidator struct is responsible for validating the Gateway resource
+// when it is created, updated, or deleted.
+type GatewayCustomValidator struct {
+	// Client is used to find other Gateways served by the same data plane
+	Client client.Reader
+
+	// ReservedPorts are the ports the data plane pods serve other endpoints on, such as health
+	// probes, webhooks and metrics, mapped to the name of the endpoint
+	ReservedPorts map[int32]string
+}
+
+var _ webhook.CustomValidator = &GatewayCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Gateway.
+func (v *GatewayCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	gateway, ok := obj.(*fetchfyv1alpha1.Gateway)
+	if !ok {
+		return nil, fmt.Errorf("expected a Gateway object but got %T", obj)
+	}
+	gatewaylog.Info("Validation for Gateway upon creation", "name", gateway.GetName())
+
+	return nil, v.validateGateway(ctx, gateway)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Gateway.
+func (v *GatewayCustomValidator) ValidateUpdate(
+	ctx context.Context,
+	oldObj, newObj runtime.Object,
+) (admission.Warnings, error) {
+	gateway, ok := newObj.(*fetchfyv1alpha1.Gateway)
+	if !ok {
+		return nil, fmt.Errorf("expected a Gateway object for the newObj but got %T", newObj)
+	}
+	gatewaylog.Info("Validation for Gateway upon update", "name", gateway.GetName())
+
+	return nil, v.validateGateway(ctx, gateway)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Gateway.
+func (v *GatewayCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateGateway validates the Gateway spec and annotations
+func (v *GatewayCustomValidator) validateGateway(ctx context.Context, gateway *fetchfyv1alpha1.Gateway) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(&gateway.Spec.ServiceSelector,
+		metav1validation.LabelSelectorValidationOptions{}, specPath.Child("serviceSelector"))...)
+
+	hub := &fetchfyv1alpha2.Gateway{}
+	if err := gateway.ConvertTo(hub); err != nil {
+		return apierrors.NewInternalError(fmt.Errorf("failed to convert Gateway: %w", err))
+	}
+	allErrs = append(allErrs, validateHubSelectors(hub)...)
+
+	if gateway.Spec.EnableTLS && gateway.Spec.TLSSecretRef == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("tlsSecretRef"),
+			"a secret with tls.crt and tls.key is required when enableTls is true"))
+	}
+
+	if gateway.Spec.AuthEnabled() && len(gateway.Spec.Auth.AuthorizationServers) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("auth", "authorizationServers"),
+			"the issuers of the accepted bearer tokens are required when auth is enabled"))
+	}
+
+	portErr, err := v.validatePort(ctx, gateway)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if portErr != nil {
+		allErrs = append(allErrs, portErr)
+	}
+
+	allErrs = append(allErrs, validateAnnotations(gateway.Annotations)...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(fetchfyv1alpha1.GroupVersion.WithKind("Gateway").GroupKind(), gateway.Name, allErrs)
+}
+
+// validateHubSelectors validates the selectors only the hub version represents, as an invalid
+// listener selector would leave the listener without services
+func validateHubSelectors(hub *fetchfyv1alpha2.Gateway) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	options := metav1validation.LabelSelectorValidationOptions{}
+
+	if hub.Spec.Discovery != nil && hub.Spec.Discovery.NamespaceSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(hub.Spec.Discovery.NamespaceSelector,
+			options, specPath.Child("discovery", "namespaceSelector"))...)
+	}
+	for i, listener := range hub.Spec.Listeners {
+		if listener.Services != nil && listener.Services.Selector != nil {
+			allErrs = append(allErrs, metav1validation.ValidateLabelSelector(listener.Services.Selector,
+				options, specPath.Child("listeners").Index(i).Child("services", "selector"))...)
+		}
+	}
+	return allErrs
+}
+
+// validatePort rejects listener ports reserved by the manager or already used by another
+// Gateway. All Gateways are served by the operator's data plane pods, so two Gateways can't
+// share a port. The Gateway is compared in its hub version, which includes listeners v1alpha1
+// can't represent.
+func (v *GatewayCustomValidator) validatePort(ctx context.Context, gateway *fetchfyv1alpha1.Gateway) (*field.Error, error) {
+	hub := &fetchfyv1alpha2.Gateway{}
+	if err := gateway.ConvertTo(hub); err != nil {
+		return nil, fmt.Errorf("failed to convert Gateway: %w", err)
+	}
+
+	gateways := &fetchfyv1alpha2.GatewayList{}
+	if v.Client != nil {
+		if err := v.Client.List(ctx, gateways); err != nil {
+			return nil, fmt.Errorf("failed to list Gateways: %w", err)
+		}
+	}
+
+	for i, listener := range hub.Spec.Listeners {
+		port := listener.Port
+		path := field.NewPath("spec", "listeners").Index(i).Child("port")
+		if i == 0 {
+			port = effectivePort(gateway)
+			path = field.NewPath("spec", "mcpPort")
+		}
+		if server, reserved := v.ReservedPorts[port]; reserved {
+			return field.Invalid(path, port, fmt.Sprintf("port is reserved for the operator's %s", server)), nil
+		}
+		for _, other := range gateways.Items {
+			if other.Namespace == gateway.Namespace && other.Name == gateway.Name {
+				continue
+			}
+			for _, otherListener := range other.Spec.Listeners {
+				if otherListener.Port != port {
+					continue
+				}
+				return field.Invalid(path, port, fmt.Sprintf("port is already used by listener %s of Gateway %s/%s",
+					otherListener.Name, other.Namespace, other.Name)), nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// validateAnnotations rejects operator annotations that have no meaning on a Gateway.
+// Apart from the conversion annotation, Gateways don't support any annotations in the
+// fetchfy.ai domain, so these are either typos or annotations meant for a Service.
+func validateAnnotations(annotations map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+	annotationsPath := field.NewPath("metadata", "annotations")
+
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		domain, _, found := strings.Cut(key, "/")
+		if !found || (domain != annotationDomain && !strings.HasSuffix(domain, "."+annotationDomain)) {
+			continue
+		}
+		// Set by the API server when a v1alpha2 Gateway is converted for this webhook
+		if key == fetchfyv1alpha1.ConversionDataAnnotation {
+			continue
+		}
+
+		detail := "unknown annotation, Gateways don't support any fetchfy.ai annotations"
+		for _, serviceAnnotation := range serviceAnnotations {
+			if key == serviceAnnotation {
+				detail = "this annotation only applies to MCP-enabled Services"
+			}
+		}
+		allErrs = append(allErrs, field.Invalid(annotationsPath.Key(key), annotations[key], detail))
+	}
+
+	return allErrs
+}
+
+// effectivePort returns the port a Gateway listens on after defaulting
+func effectivePort(gateway *fetchfyv1alpha1.Gateway) int32 {
+	if gateway.Spec.MCPPort == 0 {
+		return DefaultMCPPort
+	}
+	return gateway.Spec.MCPPort
+}
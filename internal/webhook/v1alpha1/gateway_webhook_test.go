@@ -0,0 +1,207 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fetchfyv1alpha1 "github.com/fetchfy/fetchfy-operator/api/v1alpha1"
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/services"
+	// TODO (user): Add any additional imports if needed
+)
+
+var _ = Describe("Gateway Webhook", func() {
+	var (
+		obj       *fetchfyv1alpha1.Gateway
+		oldObj    *fetchfyv1alpha1.Gateway
+		validator GatewayCustomValidator
+		defaulter GatewayCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		obj = &fetchfyv1alpha1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+			Spec: fetchfyv1alpha1.GatewaySpec{
+				MCPPort: 9000,
+				ServiceSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{services.MCPEnabledLabel: "true"},
+				},
+			},
+		}
+		oldObj = obj.DeepCopy()
+		validator = GatewayCustomValidator{
+			Client: fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).Build(),
+		}
+		Expect(validator).NotTo(BeNil(), "Expected validator to be initialized")
+		defaulter = GatewayCustomDefaulter{}
+		Expect(defaulter).NotTo(BeNil(), "Expected defaulter to be initialized")
+		Expect(oldObj).NotTo(BeNil(), "Expected oldObj to be initialized")
+		Expect(obj).NotTo(BeNil(), "Expected obj to be initialized")
+	})
+
+	Context("When creating Gateway under Defaulting Webhook", func() {
+		It("Should apply defaults when a required field is empty", func() {
+			By("simulating a scenario where defaults should be applied")
+			obj.Spec.MCPPort = 0
+			obj.Spec.ServiceSelector = metav1.LabelSelector{}
+
+			By("calling the Default method to apply defaults")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			By("checking that the default values are set")
+			Expect(obj.Spec.MCPPort).To(Equal(DefaultMCPPort))
+			Expect(obj.Spec.ServiceSelector.MatchLabels).To(HaveKeyWithValue(services.MCPEnabledLabel, "true"))
+		})
+
+		It("Should keep a user-provided selector", func() {
+			obj.Spec.ServiceSelector = metav1.LabelSelector{MatchLabels: map[string]string{"team": "ai"}}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.ServiceSelector.MatchLabels).To(Equal(map[string]string{"team": "ai"}))
+		})
+	})
+
+	Context("When creating or updating Gateway under Validating Webhook", func() {
+		It("Should admit a valid Gateway", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny creation if TLS is enabled without a secret", func() {
+			obj.Spec.EnableTLS = true
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.tlsSecretRef")))
+		})
+
+		It("Should deny OAuth2 without authorization servers", func() {
+			obj.Spec.Auth = &fetchfyv1alpha1.GatewayAuth{Type: fetchfyv1alpha1.AuthTypeOAuth2}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.auth.authorizationServers")))
+
+			obj.Spec.Auth.AuthorizationServers = []string{"https://issuer.example.com"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny creation with an invalid selector", func() {
+			obj.Spec.ServiceSelector.MatchExpressions = []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpIn},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.serviceSelector")))
+		})
+
+		It("Should deny invalid listener and namespace selectors", func() {
+			invalid := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpIn},
+			}}
+			hub := &fetchfyv1alpha2.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+				Spec: fetchfyv1alpha2.GatewaySpec{
+					Listeners: []fetchfyv1alpha2.Listener{
+						{Name: "mcp", Port: 9000, Protocol: fetchfyv1alpha2.ProtocolHTTP},
+						{Name: "internal", Port: 9001, Protocol: fetchfyv1alpha2.ProtocolHTTP,
+							Services: &fetchfyv1alpha2.ListenerServices{Selector: invalid}},
+					},
+					Discovery: &fetchfyv1alpha2.GatewayDiscovery{NamespaceSelector: invalid},
+				},
+			}
+			Expect(obj.ConvertFrom(hub)).To(Succeed())
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.listeners[1].services.selector")))
+			Expect(err).To(MatchError(ContainSubstring("spec.discovery.namespaceSelector")))
+		})
+
+		It("Should deny a port used by another Gateway", func() {
+			other := &fetchfyv1alpha1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-a"},
+				Spec:       fetchfyv1alpha1.GatewaySpec{MCPPort: 9000},
+			}
+			stored := &fetchfyv1alpha2.Gateway{}
+			Expect(other.ConvertTo(stored)).To(Succeed())
+			validator.Client = fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithObjects(stored).Build()
+
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("port is already used by listener mcp of Gateway team-a/other")))
+
+			By("admitting an update of the Gateway that owns the port")
+			Expect(validator.ValidateUpdate(ctx, other, other)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a port used by any listener of another Gateway", func() {
+			other := &fetchfyv1alpha2.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-a"},
+				Spec: fetchfyv1alpha2.GatewaySpec{
+					Listeners: []fetchfyv1alpha2.Listener{
+						{Name: "public", Port: 8443},
+						{Name: "internal", Port: 9000},
+					},
+				},
+			}
+			validator.Client = fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithObjects(other).Build()
+
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("port is already used by listener internal of Gateway team-a/other")))
+		})
+
+		It("Should deny the ports of the operator's own servers", func() {
+			validator.ReservedPorts = map[int32]string{8081: "health probes", 9443: "webhooks"}
+			obj.Spec.MCPPort = 8081
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(
+				ContainSubstring("spec.mcpPort: Invalid value: 8081: port is reserved for the operator's health probes")))
+
+			hub := &fetchfyv1alpha2.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+				Spec: fetchfyv1alpha2.GatewaySpec{
+					Listeners: []fetchfyv1alpha2.Listener{
+						{Name: "mcp", Port: 9000, Protocol: fetchfyv1alpha2.ProtocolHTTP},
+						{Name: "internal", Port: 9443, Protocol: fetchfyv1alpha2.ProtocolHTTP},
+					},
+				},
+			}
+			Expect(obj.ConvertFrom(hub)).To(Succeed())
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.listeners[1].port: Invalid value: 9443")))
+		})
+
+		DescribeTable("Should deny Service annotations on a Gateway",
+			func(key, value string) {
+				obj.Annotations = map[string]string{key: value}
+				Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+					MatchError(ContainSubstring("only applies to MCP-enabled Services")))
+			},
+			Entry("type", services.MCPTypeAnnotation, "agent"),
+			Entry("port", services.MCPPortAnnotation, "mcp"),
+			Entry("scheme", services.MCPSchemeAnnotation, "https"),
+			Entry("base path", services.MCPBasePathAnnotation, "/v1/mcp"),
+		)
+
+		It("Should deny unknown fetchfy.ai annotations", func() {
+			obj.Annotations = map[string]string{"fetchfy.ai/tls-mode": "strict"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("unknown annotation")))
+		})
+
+		It("Should ignore annotations from other domains", func() {
+			obj.Annotations = map[string]string{"example.com/owner": "ai-team"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SessionIDHeader is the header carrying the MCP session ID assigned by a backend
+	SessionIDHeader = "Mcp-Session-Id"
+
+	// sessionAffinityTTL is how long the gateway remembers an idle session
+	sessionAffinityTTL = 30 * time.Minute
+)
+
+// sessionEntry is a value kept for an MCP session
+type sessionEntry[V any] struct {
+	value    V
+	lastSeen time.Time
+}
+
+// sessionTable keeps a value per MCP session. Sessions that are idle for longer than
+// sessionAffinityTTL are forgotten.
+type sessionTable[V any] struct {
+	mutex     sync.Mutex
+	entries   map[string]sessionEntry[V]
+	lastPrune time.Time
+}
+
+// newSessionTable creates an empty session table
+func newSessionTable[V any]() *sessionTable[V] {
+	return &sessionTable[V]{entries: make(map[string]sessionEntry[V])}
+}
+
+// get returns the value of the session and marks the session as active
+func (t *sessionTable[V]) get(sessionID string) (V, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	entry, ok := t.entries[sessionID]
+	if ok {
+		entry.lastSeen = time.Now()
+		t.entries[sessionID] = entry
+	}
+	return entry.value, ok
+}
+
+// set stores the value of the session
+func (t *sessionTable[V]) set(sessionID string, value V) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	t.entries[sessionID] = sessionEntry[V]{value: value, lastSeen: now}
+
+	if now.Sub(t.lastPrune) < sessionAffinityTTL/2 {
+		return
+	}
+	t.lastPrune = now
+	for id, entry := range t.entries {
+		if now.Sub(entry.lastSeen) > sessionAffinityTTL {
+			delete(t.entries, id)
+		}
+	}
+}
+
+// remove forgets the session, after the client terminated it
+func (t *sessionTable[V]) remove(sessionID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.entries, sessionID)
+}
+
+// sessionBackendSeparator separates the backend key from the backend's own session ID in the
+// session IDs the gateway passes to clients
+const sessionBackendSeparator = "."
+
+// sessionBackendKey identifies a backend in a session ID. It is a hash, so that session IDs
+// don't reveal the services behind a route.
+func sessionBackendKey(backend Backend) string {
+	h := fnv.New32a()
+	h.Write([]byte(fmt.Sprintf("%s:%d", backend.Service, backend.Port)))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// pinSession returns the session ID a client receives for a session created by the backend.
+// It carries the backend, so that every gateway replica sends the session's requests there
+// without sharing state.
+func pinSession(sessionID string, backend Backend) string {
+	return sessionBackendKey(backend) + sessionBackendSeparator + sessionID
+}
+
+// pickSessionBackend returns the backend for a request of the session and the backend's own
+// session ID. Sessions created through a split stay on their backend as long as it is one of
+// the candidates, even if its weight dropped to 0, so ramping down a version only affects new
+// sessions. Other sessions are assigned by hashing the session ID, so that every gateway
+// replica picks the same backend, and requests without a session are assigned at random.
+func pickSessionBackend(sessionID string, backends []Backend) (Backend, string, bool) {
+	if sessionID == "" {
+		backend, ok := pickBackend(backends)
+		return backend, "", ok
+	}
+
+	if key, backendSessionID, found := strings.Cut(sessionID, sessionBackendSeparator); found {
+		for _, backend := range backends {
+			if sessionBackendKey(backend) == key {
+				return backend, backendSessionID, true
+			}
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(sessionID))
+	backend, ok := pickWeighted(backends, int(h.Sum32()&0x7fffffff))
+	return backend, sessionID, ok
+}
@@ -0,0 +1,436 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/metrics"
+)
+
+const (
+	// CacheTTLAnnotation enables caching of a service's list method responses for the given duration, e.g. "30s"
+	CacheTTLAnnotation = "mcp.fetchfy.ai/cache-ttl"
+
+	// CacheResourcesAnnotation is a comma-separated list of URI prefixes of the resources whose
+	// resources/read responses are cached as well
+	CacheResourcesAnnotation = "mcp.fetchfy.ai/cache-resources"
+
+	// maxCacheEntries bounds the number of responses a gateway caches
+	maxCacheEntries = 10000
+)
+
+const (
+	// CacheResultHit means the response was served from the cache
+	CacheResultHit = "hit"
+
+	// CacheResultMiss means the request was forwarded and its response cached
+	CacheResultMiss = "miss"
+)
+
+const (
+	// cacheInvalidationListChanged means the backend sent a list_changed notification
+	cacheInvalidationListChanged = "list_changed"
+
+	// cacheInvalidationResourceUpdated means the backend sent a resources/updated notification
+	cacheInvalidationResourceUpdated = "resource_updated"
+
+	// cacheInvalidationReregistered means the backend's Service changed since the response was cached
+	cacheInvalidationReregistered = "reregistered"
+
+	// cacheInvalidationDeregistered means the backend's Service was removed from the registry
+	cacheInvalidationDeregistered = "deregistered"
+)
+
+// cachedListMethods are the methods whose responses are cached for services with a cache TTL
+var cachedListMethods = map[string]bool{
+	"tools/list":               true,
+	"prompts/list":             true,
+	"resources/list":           true,
+	"resources/templates/list": true,
+}
+
+// listChangedMethods maps the list_changed notifications to the methods whose responses they invalidate
+var listChangedMethods = map[string][]string{
+	ToolsListChangedNotification:     {"tools/list"},
+	PromptsListChangedNotification:   {"prompts/list"},
+	ResourcesListChangedNotification: {"resources/list", "resources/templates/list"},
+}
+
+// CachePolicy is the response caching configured by a Service's cache annotations
+type CachePolicy struct {
+	// TTL is how long responses are cached, caching is disabled when 0
+	TTL time.Duration
+
+	// ResourcePrefixes are the URI prefixes of the resources whose reads are cached
+	ResourcePrefixes []string
+}
+
+// ParseCachePolicy returns the cache policy configured by the annotations
+func ParseCachePolicy(annotations map[string]string) (CachePolicy, error) {
+	var policy CachePolicy
+	if value, ok := annotations[CacheTTLAnnotation]; ok {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return CachePolicy{}, fmt.Errorf("%s must be a positive duration, e.g. \"30s\"", CacheTTLAnnotation)
+		}
+		policy.TTL = ttl
+	}
+
+	if value, ok := annotations[CacheResourcesAnnotation]; ok {
+		if policy.TTL == 0 {
+			return CachePolicy{}, fmt.Errorf("%s requires %s", CacheResourcesAnnotation, CacheTTLAnnotation)
+		}
+		for _, prefix := range strings.Split(value, ",") {
+			if prefix = strings.TrimSpace(prefix); prefix != "" {
+				policy.ResourcePrefixes = append(policy.ResourcePrefixes, prefix)
+			}
+		}
+		if len(policy.ResourcePrefixes) == 0 {
+			return CachePolicy{}, fmt.Errorf("%s must list at least one URI prefix", CacheResourcesAnnotation)
+		}
+	}
+	return policy, nil
+}
+
+// caches reports whether responses to the method are cached, for resources/read the read resource's URI
+func (p CachePolicy) caches(method, uri string) bool {
+	if p.TTL == 0 {
+		return false
+	}
+	if method != "resources/read" {
+		return cachedListMethods[method]
+	}
+	for _, prefix := range p.ResourcePrefixes {
+		if uri != "" && strings.HasPrefix(uri, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheBackend identifies the backend a response was cached for, independent of its weight
+type cacheBackend struct {
+	service types.NamespacedName
+	port    int32
+}
+
+// cacheEntry is a cached JSON-RPC result
+type cacheEntry struct {
+	result  json.RawMessage
+	method  string
+	uri     string
+	version string
+	expires time.Time
+}
+
+// responseCache caches the JSON-RPC results of backends by caller, method and params
+type responseCache struct {
+	mutex   sync.Mutex
+	entries map[cacheBackend]map[string]*cacheEntry
+	size    int
+}
+
+// newResponseCache creates an empty response cache
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[cacheBackend]map[string]*cacheEntry)}
+}
+
+// get returns the cached result. Results cached for an older version of the backend's
+// Service are dropped, and reported as invalidated.
+func (c *responseCache) get(backend cacheBackend, key, version string) (result json.RawMessage, invalidated bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[backend][key]
+	switch {
+	case !ok:
+		return nil, false
+	case entry.version != version:
+		c.delete(backend, key)
+		return nil, true
+	case time.Now().After(entry.expires):
+		c.delete(backend, key)
+		return nil, false
+	}
+	return entry.result, false
+}
+
+// set caches the result. It isn't cached if the cache is full of unexpired results.
+func (c *responseCache) set(backend cacheBackend, key string, entry *cacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.entries[backend][key] == nil && c.size >= maxCacheEntries {
+		c.pruneExpired()
+		if c.size >= maxCacheEntries {
+			return
+		}
+	}
+	if c.entries[backend] == nil {
+		c.entries[backend] = make(map[string]*cacheEntry)
+	}
+	if c.entries[backend][key] == nil {
+		c.size++
+	}
+	c.entries[backend][key] = entry
+}
+
+// invalidate drops the cached results of the method from all ports of the Service, for
+// resources/read only those of the resource's URI. It returns whether anything was dropped.
+func (c *responseCache) invalidate(service types.NamespacedName, method, uri string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	dropped := false
+	for backend, entries := range c.entries {
+		if backend.service != service {
+			continue
+		}
+		for key, entry := range entries {
+			if entry.method == method && (uri == "" || entry.uri == uri) {
+				c.delete(backend, key)
+				dropped = true
+			}
+		}
+	}
+	return dropped
+}
+
+// forget drops the cached results of all ports of the Service. It returns whether anything was dropped.
+func (c *responseCache) forget(service types.NamespacedName) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	dropped := false
+	for backend, entries := range c.entries {
+		if backend.service != service {
+			continue
+		}
+		for key := range entries {
+			c.delete(backend, key)
+			dropped = true
+		}
+	}
+	return dropped
+}
+
+// delete drops a cached result, the caller must hold the mutex
+func (c *responseCache) delete(backend cacheBackend, key string) {
+	delete(c.entries[backend], key)
+	if len(c.entries[backend]) == 0 {
+		delete(c.entries, backend)
+	}
+	c.size--
+}
+
+// pruneExpired drops all expired results, the caller must hold the mutex
+func (c *responseCache) pruneExpired() {
+	now := time.Now()
+	for backend, entries := range c.entries {
+		for key, entry := range entries {
+			if now.After(entry.expires) {
+				c.delete(backend, key)
+			}
+		}
+	}
+}
+
+// rpcMessage is the part of a JSON-RPC request or notification the gateway looks at
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// resourceURI returns the uri param of a resources/read request or resources/updated notification
+func (m *rpcMessage) resourceURI() string {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if json.Unmarshal(m.Params, &params) != nil {
+		return ""
+	}
+	return params.URI
+}
+
+// cacheKey returns the key of the request's response, its method and its params
+// without the _meta field, which carries per-request data like progress tokens
+func (m *rpcMessage) cacheKey() (string, bool) {
+	params := map[string]json.RawMessage{}
+	if len(m.Params) > 0 && string(m.Params) != "null" {
+		if json.Unmarshal(m.Params, &params) != nil {
+			return "", false
+		}
+	}
+	delete(params, "_meta")
+	canonical, err := json.Marshal(params)
+	if err != nil {
+		return "", false
+	}
+	return m.Method + "\x00" + string(canonical), true
+}
+
+// cacheCaller identifies the caller a response is cached for, by the MCP session and the
+// credentials of the request, so that the responses of one caller aren't served to another
+func cacheCaller(r *http.Request) string {
+	caller := r.Header.Get(SessionIDHeader)
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		sum := sha256.Sum256([]byte(authorization))
+		caller += "\x00" + hex.EncodeToString(sum[:])
+	}
+	return caller
+}
+
+// cachedRequest is a request whose response is cached when the backend returns a result
+type cachedRequest struct {
+	backend cacheBackend
+	key     string
+	method  string
+	uri     string
+	version string
+	ttl     time.Duration
+}
+
+// cacheVersion identifies the registered version of a Service, it changes when the Service does
+func cacheVersion(svc *MCPService) string {
+	if svc.Service == nil {
+		return ""
+	}
+	return string(svc.Service.UID) + "/" + svc.Service.ResourceVersion
+}
+
+// cachePolicyFor returns the cache policy of the backend's Service if it is a registered MCP service
+func (s *Server) cachePolicyFor(backend Backend) (CachePolicy, *MCPService, bool) {
+	svc, ok := s.registry.GetService(backend.Service)
+	if !ok || svc.Service == nil {
+		return CachePolicy{}, nil, false
+	}
+	policy, err := ParseCachePolicy(svc.Service.Annotations)
+	if err != nil || policy.TTL == 0 {
+		return CachePolicy{}, nil, false
+	}
+	return policy, svc, true
+}
+
+// serveCached answers the request from the cache. On a miss, it returns the request
+// for forward to cache the response; it returns nil if the response can't be cached.
+func (s *Server) serveCached(w http.ResponseWriter, r *http.Request, backend Backend) (bool, *cachedRequest) {
+	if r.Method != http.MethodPost {
+		return false, nil
+	}
+	policy, svc, ok := s.cachePolicyFor(backend)
+	if !ok {
+		return false, nil
+	}
+
+	body, ok := readBody(r)
+	if !ok {
+		return false, nil
+	}
+	var msg rpcMessage
+	if json.Unmarshal(body, &msg) != nil || len(msg.ID) == 0 {
+		return false, nil
+	}
+	uri := ""
+	if msg.Method == "resources/read" {
+		uri = msg.resourceURI()
+	}
+	if !policy.caches(msg.Method, uri) {
+		return false, nil
+	}
+	key, ok := msg.cacheKey()
+	if !ok {
+		return false, nil
+	}
+	key = cacheCaller(r) + "\x00" + key
+
+	req := &cachedRequest{
+		backend: cacheBackend{service: backend.Service, port: backend.Port},
+		key:     key,
+		method:  msg.Method,
+		uri:     uri,
+		version: cacheVersion(svc),
+		ttl:     policy.TTL,
+	}
+	gateway := s.gatewayName().String()
+	backendName := backend.Service.String()
+	result, invalidated := s.cache.get(req.backend, key, req.version)
+	if invalidated {
+		metrics.CacheInvalidationCount.WithLabelValues(gateway, backendName, cacheInvalidationReregistered).Inc()
+	}
+	if result == nil {
+		metrics.CacheRequestCount.WithLabelValues(gateway, backendName, msg.Method, CacheResultMiss).Inc()
+		return false, req
+	}
+
+	response, err := json.Marshal(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  json.RawMessage `json:"result"`
+	}{"2.0", msg.ID, result})
+	if err != nil {
+		return false, req
+	}
+	metrics.CacheRequestCount.WithLabelValues(gateway, backendName, msg.Method, CacheResultHit).Inc()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+	return true, nil
+}
+
+// storeCached caches the result of a successful response
+func (s *Server) storeCached(req *cachedRequest, contentType string, body []byte) {
+	resp, ok := responseMessage(contentType, body)
+	if !ok || resp.Result == nil {
+		return
+	}
+	s.cache.set(req.backend, req.key, &cacheEntry{
+		result:  resp.Result,
+		method:  req.method,
+		uri:     req.uri,
+		version: req.version,
+		expires: time.Now().Add(req.ttl),
+	})
+}
+
+// invalidateCached drops the cached results a list change or resources/updated notification makes stale
+func (s *Server) invalidateCached(service types.NamespacedName, notification, uri string) {
+	reason := cacheInvalidationListChanged
+	dropped := false
+	if notification == ResourceUpdatedNotification {
+		reason = cacheInvalidationResourceUpdated
+		if uri != "" {
+			dropped = s.cache.invalidate(service, "resources/read", uri)
+		}
+	}
+	for _, method := range listChangedMethods[notification] {
+		dropped = s.cache.invalidate(service, method, "") || dropped
+	}
+	if dropped {
+		metrics.CacheInvalidationCount.WithLabelValues(s.gatewayName().String(), service.String(), reason).Inc()
+	}
+}
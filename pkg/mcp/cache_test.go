@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/metrics"
+)
+
+var _ = Describe("Response cache", func() {
+	var (
+		registry *Registry
+		server   *Server
+		upstream *httptest.Server
+		calls    atomic.Int32
+	)
+
+	register := func(resourceVersion string, annotations map[string]string) {
+		annotations[EndpointAnnotation] = "/mcp/tools/docs"
+		_, err := registry.RegisterService(context.Background(), &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "docs",
+				Namespace:       "tools",
+				UID:             "docs-uid",
+				ResourceVersion: resourceVersion,
+				Annotations:     annotations,
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	// call sends a JSON-RPC request with the given header names and values and returns the response
+	call := func(id int, method, params string, headers ...string) map[string]json.RawMessage {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":%s}`, id, method, params)
+		req := httptest.NewRequest(http.MethodPost, "http://gw.example.com/mcp/tools/docs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		req = req.WithContext(context.WithValue(req.Context(), listenerKey{}, "mcp"))
+
+		rec := httptest.NewRecorder()
+		server.handleMCPRequest(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var resp map[string]json.RawMessage
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		return resp
+	}
+
+	BeforeEach(func() {
+		calls.Store(0)
+		upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}\n\n")
+				return
+			}
+			var msg struct {
+				ID json.RawMessage `json:"id"`
+			}
+			body, _ := io.ReadAll(r.Body)
+			Expect(json.Unmarshal(body, &msg)).To(Succeed())
+			n := calls.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"call":%d}}`, msg.ID, n)
+		}))
+		target, err := url.Parse(upstream.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		registry = NewRegistry(logr.Discard())
+		server = NewServer(registry, logr.Discard())
+		server.Configure(newTestGateway(8080), nil)
+		server.SetBackendURLFunc(func(Backend) *url.URL { return target })
+	})
+
+	AfterEach(func() {
+		upstream.Close()
+	})
+
+	It("should answer list methods from the cache with the request's ID", func() {
+		register("1", map[string]string{CacheTTLAnnotation: "1m"})
+		hits := metrics.CacheRequestCount.WithLabelValues("default/gw", "tools/docs", "tools/list", CacheResultHit)
+		before := testutil.ToFloat64(hits)
+
+		Expect(call(1, "tools/list", `{}`)["result"]).To(MatchJSON(`{"call":1}`))
+		resp := call(2, "tools/list", `{"_meta":{"progressToken":7}}`)
+		Expect(resp["id"]).To(MatchJSON(`2`))
+		Expect(resp["result"]).To(MatchJSON(`{"call":1}`))
+		Expect(testutil.ToFloat64(hits)).To(Equal(before + 1))
+
+		By("keying the cache by params")
+		Expect(call(3, "tools/list", `{"cursor":"page-2"}`)["result"]).To(MatchJSON(`{"call":2}`))
+
+		By("forwarding methods that aren't cached")
+		Expect(call(4, "tools/call", `{"name":"search"}`)["result"]).To(MatchJSON(`{"call":3}`))
+		Expect(call(5, "tools/call", `{"name":"search"}`)["result"]).To(MatchJSON(`{"call":4}`))
+	})
+
+	It("should not share cached responses between sessions and callers", func() {
+		register("1", map[string]string{CacheTTLAnnotation: "1m"})
+		alice := []string{SessionIDHeader, "session-1", "Authorization", "Bearer alice"}
+		Expect(call(1, "tools/list", `{}`, alice...)["result"]).To(MatchJSON(`{"call":1}`))
+		Expect(call(2, "tools/list", `{}`, alice...)["result"]).To(MatchJSON(`{"call":1}`))
+
+		By("keying the cache by session")
+		Expect(call(3, "tools/list", `{}`, SessionIDHeader, "session-2", "Authorization", "Bearer alice")["result"]).
+			To(MatchJSON(`{"call":2}`))
+
+		By("keying the cache by credentials")
+		Expect(call(4, "tools/list", `{}`, SessionIDHeader, "session-1", "Authorization", "Bearer bob")["result"]).
+			To(MatchJSON(`{"call":3}`))
+		Expect(call(5, "tools/list", `{}`, SessionIDHeader, "session-1")["result"]).To(MatchJSON(`{"call":4}`))
+	})
+
+	It("should not cache services without a cache TTL", func() {
+		register("1", map[string]string{})
+		call(1, "tools/list", `{}`)
+		Expect(call(2, "tools/list", `{}`)["result"]).To(MatchJSON(`{"call":2}`))
+	})
+
+	It("should only cache reads of the annotated resources", func() {
+		register("1", map[string]string{CacheTTLAnnotation: "1m", CacheResourcesAnnotation: "docs://static/"})
+		call(1, "resources/read", `{"uri":"docs://static/intro"}`)
+		Expect(call(2, "resources/read", `{"uri":"docs://static/intro"}`)["result"]).To(MatchJSON(`{"call":1}`))
+
+		call(3, "resources/read", `{"uri":"docs://live/status"}`)
+		Expect(call(4, "resources/read", `{"uri":"docs://live/status"}`)["result"]).To(MatchJSON(`{"call":3}`))
+	})
+
+	It("should invalidate cached lists on list_changed notifications from the backend", func() {
+		register("1", map[string]string{CacheTTLAnnotation: "1m"})
+		call(1, "tools/list", `{}`)
+		Expect(call(2, "tools/list", `{}`)["result"]).To(MatchJSON(`{"call":1}`))
+
+		req := httptest.NewRequest(http.MethodGet, "http://gw.example.com/mcp/tools/docs", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		rec := httptest.NewRecorder()
+		server.handleMCPRequest(rec, req.WithContext(context.WithValue(req.Context(), listenerKey{}, "mcp")))
+		Expect(rec.Body.String()).To(ContainSubstring("list_changed"))
+
+		Expect(call(3, "tools/list", `{}`)["result"]).To(MatchJSON(`{"call":2}`))
+	})
+
+	It("should invalidate cached responses when the service is re-registered", func() {
+		register("1", map[string]string{CacheTTLAnnotation: "1m"})
+		call(1, "tools/list", `{}`)
+		Expect(call(2, "tools/list", `{}`)["result"]).To(MatchJSON(`{"call":1}`))
+
+		register("2", map[string]string{CacheTTLAnnotation: "1m"})
+		Expect(call(3, "tools/list", `{}`)["result"]).To(MatchJSON(`{"call":2}`))
+	})
+
+	It("should expire cached responses after the TTL", func() {
+		register("1", map[string]string{CacheTTLAnnotation: "50ms"})
+		call(1, "tools/list", `{}`)
+		time.Sleep(100 * time.Millisecond)
+		Expect(call(2, "tools/list", `{}`)["result"]).To(MatchJSON(`{"call":2}`))
+	})
+})
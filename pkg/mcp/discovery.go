@@ -0,0 +1,252 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+const (
+	// WellKnownMCPPath is the path of the gateway discovery document
+	WellKnownMCPPath = "/.well-known/mcp"
+
+	// WellKnownProtectedResourcePath is the path of the OAuth 2.0 protected resource
+	// metadata document (RFC 9728), served only when authentication is enabled
+	WellKnownProtectedResourcePath = "/.well-known/oauth-protected-resource"
+
+	// MCPBasePath is the path prefix under which MCP requests are served
+	MCPBasePath = "/mcp/"
+
+	// TransportStreamableHTTP is the MCP Streamable HTTP transport
+	TransportStreamableHTTP = "streamable-http"
+)
+
+// supportedTransports lists the MCP transports served by the gateway
+var supportedTransports = []string{TransportStreamableHTTP}
+
+// DiscoveryDocument describes what a gateway offers to MCP clients
+type DiscoveryDocument struct {
+	Gateway        string             `json:"gateway"`
+	Listener       string             `json:"listener"`
+	Endpoint       string             `json:"endpoint"`
+	Transports     []string           `json:"transports"`
+	Authentication DiscoveryAuth      `json:"authentication"`
+	Services       []DiscoveryService `json:"services"`
+}
+
+// DiscoveryAuth describes the authentication requirements of a gateway
+type DiscoveryAuth struct {
+	Required             bool     `json:"required"`
+	Type                 string   `json:"type"`
+	ResourceMetadata     string   `json:"resourceMetadata,omitempty"`
+	AuthorizationServers []string `json:"authorizationServers,omitempty"`
+	Scopes               []string `json:"scopes,omitempty"`
+}
+
+// DiscoveryService describes a single MCP service exposed through a gateway
+type DiscoveryService struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+	Endpoint  string `json:"endpoint"`
+	URL       string `json:"url"`
+	Status    string `json:"status"`
+
+	// Stale is set while a service restored after an operator restart is not revalidated
+	Stale bool `json:"stale,omitempty"`
+}
+
+// ProtectedResourceMetadata is the OAuth 2.0 protected resource metadata document (RFC 9728)
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers,omitempty"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported"`
+}
+
+// BuildDiscoveryDocument builds the discovery document of a listener for the given base URL.
+// It lists the services visible through the listener.
+func (s *Server) BuildDiscoveryDocument(listener, baseURL string) *DiscoveryDocument {
+	gatewayRef, settings := s.discoveryConfig(listener)
+	auth := settings.auth
+
+	doc := &DiscoveryDocument{
+		Gateway:    gatewayRef,
+		Listener:   listener,
+		Endpoint:   baseURL + MCPBasePath,
+		Transports: append([]string(nil), supportedTransports...),
+		Authentication: DiscoveryAuth{
+			Type: string(fetchfyv1alpha2.AuthTypeNone),
+		},
+		Services: []DiscoveryService{},
+	}
+
+	if auth != nil {
+		doc.Authentication = DiscoveryAuth{
+			Required:             true,
+			Type:                 string(auth.Type),
+			ResourceMetadata:     baseURL + WellKnownProtectedResourcePath,
+			AuthorizationServers: auth.AuthorizationServers,
+			Scopes:               auth.Scopes,
+		}
+	}
+
+	for _, svc := range s.registry.ListServices() {
+		if !settings.filter.matches(svc) {
+			continue
+		}
+		doc.Services = append(doc.Services, discoveryService(svc, baseURL))
+	}
+
+	sort.Slice(doc.Services, func(i, j int) bool {
+		if doc.Services[i].Namespace != doc.Services[j].Namespace {
+			return doc.Services[i].Namespace < doc.Services[j].Namespace
+		}
+		return doc.Services[i].Name < doc.Services[j].Name
+	})
+
+	return doc
+}
+
+// discoveryService describes a registered service for clients connecting through baseURL
+func discoveryService(svc *MCPService, baseURL string) DiscoveryService {
+	return DiscoveryService{
+		Name:      svc.Name,
+		Namespace: svc.Namespace,
+		Type:      string(svc.Type),
+		Endpoint:  svc.Endpoint,
+		URL:       baseURL + svc.Endpoint,
+		Status:    string(svc.Status),
+		Stale:     svc.Stale,
+	}
+}
+
+// discoveryConfig returns the gateway reference and the settings of a listener. The auth
+// settings are only set when authentication is enabled on the listener.
+func (s *Server) discoveryConfig(listener string) (string, listenerSettings) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	settings := s.settings[listener]
+	if settings.auth == nil || settings.auth.Type == "" || settings.auth.Type == fetchfyv1alpha2.AuthTypeNone {
+		settings.auth = nil
+	} else {
+		settings.auth = settings.auth.DeepCopy()
+	}
+	return s.gatewayRef.String(), settings
+}
+
+// listenerName returns the name of the listener serving the request
+func listenerName(r *http.Request) string {
+	name, _ := r.Context().Value(listenerKey{}).(string)
+	return name
+}
+
+// handleDiscovery serves the /.well-known/mcp discovery document
+func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.BuildDiscoveryDocument(listenerName(r), requestBaseURL(r)))
+}
+
+// handleProtectedResourceMetadata serves the OAuth 2.0 protected resource metadata
+// document, or 404 when authentication is disabled
+func (s *Server) handleProtectedResourceMetadata(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	_, settings := s.discoveryConfig(listenerName(r))
+	auth := settings.auth
+	if auth == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	baseURL := requestBaseURL(r)
+	writeJSON(w, http.StatusOK, &ProtectedResourceMetadata{
+		Resource:               baseURL + MCPBasePath,
+		AuthorizationServers:   auth.AuthorizationServers,
+		ScopesSupported:        auth.Scopes,
+		BearerMethodsSupported: []string{"header"},
+	})
+}
+
+// requireAuth rejects requests without a valid bearer token when authentication is enabled,
+// pointing clients at the protected resource metadata document. Tokens must be JWTs signed
+// by one of the authorization servers, issued for the resource the metadata advertises.
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if challenge, ok := s.authenticate(r); !ok {
+			w.Header().Set("WWW-Authenticate", challenge)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// authenticate reports whether the request may access the listener, i.e. whether it carries a
+// valid bearer token when authentication is enabled. Otherwise, it returns the challenge of
+// the WWW-Authenticate header.
+func (s *Server) authenticate(r *http.Request) (string, bool) {
+	_, settings := s.discoveryConfig(listenerName(r))
+	if settings.auth == nil {
+		return "", true
+	}
+	metadata := fmt.Sprintf(`resource_metadata="%s"`, requestBaseURL(r)+WellKnownProtectedResourcePath)
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token = strings.TrimSpace(token); !ok || token == "" {
+		return "Bearer " + metadata, false
+	}
+	resource := requestBaseURL(r) + MCPBasePath
+	if err := s.tokens.verify(r.Context(), token, settings.auth.AuthorizationServers, resource); err != nil {
+		s.log.V(1).Info("Rejected bearer token", "reason", err.Error())
+		return fmt.Sprintf(`Bearer error="invalid_token", error_description="The access token is invalid", %s`,
+			metadata), false
+	}
+	return "", true
+}
+
+// requestBaseURL returns the scheme and host the client used to reach the gateway
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
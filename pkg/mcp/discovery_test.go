@@ -0,0 +1,287 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+var _ = Describe("Discovery documents", func() {
+	var (
+		registry *Registry
+		server   *Server
+		gateway  *fetchfyv1alpha2.Gateway
+	)
+
+	BeforeEach(func() {
+		registry = NewRegistry(logr.Discard())
+		server = NewServer(registry, logr.Discard())
+		gateway = newTestGateway(8080)
+
+		_, err := registry.RegisterService(context.Background(), &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "calculator",
+				Namespace:   "tools",
+				Annotations: map[string]string{"mcp.fetchfy.ai/endpoint": "/mcp/tools/calculator"},
+			},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Port: 80}},
+			},
+		}, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	get := func(handler http.HandlerFunc, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://gw.example.com"+path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), listenerKey{}, "mcp"))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	It("should list the endpoint, transports and registered services", func() {
+		server.Configure(gateway, nil)
+
+		rec := get(server.handleDiscovery, WellKnownMCPPath)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+
+		var doc DiscoveryDocument
+		Expect(json.Unmarshal(rec.Body.Bytes(), &doc)).To(Succeed())
+		Expect(doc.Gateway).To(Equal("default/gw"))
+		Expect(doc.Listener).To(Equal("mcp"))
+		Expect(doc.Endpoint).To(Equal("http://gw.example.com/mcp/"))
+		Expect(doc.Transports).To(ConsistOf(TransportStreamableHTTP))
+		Expect(doc.Authentication.Required).To(BeFalse())
+		Expect(doc.Services).To(HaveLen(1))
+		Expect(doc.Services[0].Name).To(Equal("calculator"))
+		Expect(doc.Services[0].URL).To(Equal("http://gw.example.com/mcp/tools/calculator"))
+	})
+
+	It("should not serve protected resource metadata without auth", func() {
+		server.Configure(gateway, nil)
+
+		rec := get(server.handleProtectedResourceMetadata, WellKnownProtectedResourcePath)
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+
+	Context("with OAuth2 enabled", func() {
+		BeforeEach(func() {
+			gateway.Spec.Auth = &fetchfyv1alpha2.GatewayAuth{
+				Type:                 fetchfyv1alpha2.AuthTypeOAuth2,
+				AuthorizationServers: []string{"https://issuer.example.com"},
+				Scopes:               []string{"mcp:tools"},
+			}
+			server.Configure(gateway, nil)
+		})
+
+		It("should advertise the auth requirements", func() {
+			var doc DiscoveryDocument
+			Expect(json.Unmarshal(get(server.handleDiscovery, WellKnownMCPPath).Body.Bytes(), &doc)).To(Succeed())
+			Expect(doc.Authentication.Required).To(BeTrue())
+			Expect(doc.Authentication.ResourceMetadata).To(
+				Equal("http://gw.example.com" + WellKnownProtectedResourcePath))
+		})
+
+		It("should serve protected resource metadata", func() {
+			rec := get(server.handleProtectedResourceMetadata, WellKnownProtectedResourcePath)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			var meta ProtectedResourceMetadata
+			Expect(json.Unmarshal(rec.Body.Bytes(), &meta)).To(Succeed())
+			Expect(meta.Resource).To(Equal("http://gw.example.com/mcp/"))
+			Expect(meta.AuthorizationServers).To(ConsistOf("https://issuer.example.com"))
+			Expect(meta.ScopesSupported).To(ConsistOf("mcp:tools"))
+		})
+
+		It("should challenge MCP requests without a bearer token", func() {
+			rec := get(server.requireAuth(server.handleMCPRequest), "/mcp/tools/calculator")
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Expect(rec.Header().Get("WWW-Authenticate")).To(ContainSubstring(WellKnownProtectedResourcePath))
+		})
+
+		It("should challenge service API requests without a bearer token", func() {
+			handler := server.newHandler()
+			for _, path := range []string{"/api/services", ServicesWatchPath} {
+				rec := get(handler.ServeHTTP, path)
+				Expect(rec.Code).To(Equal(http.StatusUnauthorized), path)
+				Expect(rec.Header().Get("WWW-Authenticate")).To(ContainSubstring(WellKnownProtectedResourcePath))
+			}
+		})
+
+		It("should only accept bearer tokens signed by the authorization servers", func() {
+			issuer := newTestIssuer()
+			defer issuer.Close()
+			gateway.Spec.Auth.AuthorizationServers = []string{issuer.URL}
+			server.Configure(gateway, nil)
+
+			authorized := func(token string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, "http://gw.example.com/mcp/tools/calculator", nil)
+				req.Header.Set("Authorization", "Bearer "+token)
+				req = req.WithContext(context.WithValue(req.Context(), listenerKey{}, "mcp"))
+				rec := httptest.NewRecorder()
+				server.requireAuth(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				})(rec, req)
+				return rec
+			}
+
+			Expect(authorized(issuer.validToken()).Code).To(Equal(http.StatusNoContent))
+
+			rec := authorized("not-a-token")
+			Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+			Expect(rec.Header().Get("WWW-Authenticate")).To(ContainSubstring(`error="invalid_token"`))
+			Expect(rec.Header().Get("WWW-Authenticate")).To(ContainSubstring(WellKnownProtectedResourcePath))
+
+			other := newTestIssuer()
+			defer other.Close()
+			Expect(authorized(other.validToken()).Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("with per-listener auth and filtering", func() {
+		BeforeEach(func() {
+			gateway.Spec.Auth = &fetchfyv1alpha2.GatewayAuth{Type: fetchfyv1alpha2.AuthTypeOAuth2}
+			gateway.Spec.Listeners[0].Auth = &fetchfyv1alpha2.GatewayAuth{Type: fetchfyv1alpha2.AuthTypeNone}
+			gateway.Spec.Listeners[0].Services = &fetchfyv1alpha2.ListenerServices{
+				Types: []fetchfyv1alpha2.ServiceType{fetchfyv1alpha2.ServiceType(ServiceTypeAgent)},
+			}
+			server.Configure(gateway, nil)
+		})
+
+		It("should let the listener override the gateway auth", func() {
+			rec := get(server.requireAuth(server.handleMCPRequest), "/mcp/agents/assistant")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("should hide services the listener doesn't expose", func() {
+			var doc DiscoveryDocument
+			Expect(json.Unmarshal(get(server.handleDiscovery, WellKnownMCPPath).Body.Bytes(), &doc)).To(Succeed())
+			Expect(doc.Services).To(BeEmpty())
+
+			rec := get(server.handleMCPRequest, "/mcp/tools/calculator")
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+
+			Expect(server.Listeners()[0].Services).To(BeZero())
+		})
+	})
+
+	Context("with an invalid listener selector", func() {
+		invalid := &fetchfyv1alpha2.ListenerServices{Selector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Invalid"}},
+		}}
+
+		services := func() []DiscoveryService {
+			var doc DiscoveryDocument
+			Expect(json.Unmarshal(get(server.handleDiscovery, WellKnownMCPPath).Body.Bytes(), &doc)).To(Succeed())
+			return doc.Services
+		}
+
+		It("should expose no services through a new listener", func() {
+			gateway.Spec.Listeners[0].Services = invalid
+			server.Configure(gateway, nil)
+			Expect(server.Sync(context.Background())).To(MatchError(ErrListenerConfig))
+
+			Expect(services()).To(BeEmpty())
+			Expect(get(server.handleMCPRequest, "/mcp/tools/calculator").Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should keep the previous settings of a running listener", func() {
+			gateway.Spec.Listeners[0].Services = &fetchfyv1alpha2.ListenerServices{
+				Types: []fetchfyv1alpha2.ServiceType{fetchfyv1alpha2.ServiceType(ServiceTypeAgent)},
+			}
+			server.Configure(gateway, nil)
+
+			gateway.Spec.Listeners[0].Services = invalid
+			server.Configure(gateway, nil)
+			Expect(services()).To(BeEmpty())
+			Expect(get(server.handleMCPRequest, "/mcp/tools/calculator").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("with gateways discovering disjoint namespaces", func() {
+		var (
+			other        *Server
+			otherGateway *fetchfyv1alpha2.Gateway
+		)
+
+		BeforeEach(func() {
+			_, err := registry.RegisterService(context.Background(), &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "search",
+					Namespace:   "search",
+					Annotations: map[string]string{"mcp.fetchfy.ai/endpoint": "/mcp/tools/search"},
+				},
+				Spec: corev1.ServiceSpec{
+					Type:  corev1.ServiceTypeClusterIP,
+					Ports: []corev1.ServicePort{{Port: 80}},
+				},
+			}, ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+
+			gateway.Spec.Discovery = &fetchfyv1alpha2.GatewayDiscovery{Namespaces: []string{"tools"}}
+			server.Configure(gateway, gateway.Spec.Discovery.Namespaces)
+
+			otherGateway = newTestGateway(8081)
+			otherGateway.Name = "other"
+			otherGateway.Spec.Discovery = &fetchfyv1alpha2.GatewayDiscovery{Namespaces: []string{"search"}}
+			other = NewServer(registry, logr.Discard())
+			other.Configure(otherGateway, otherGateway.Spec.Discovery.Namespaces)
+		})
+
+		services := func(server *Server) []string {
+			var doc DiscoveryDocument
+			Expect(json.Unmarshal(get(server.handleDiscovery, WellKnownMCPPath).Body.Bytes(), &doc)).To(Succeed())
+			names := []string{}
+			for _, svc := range doc.Services {
+				names = append(names, svc.Name)
+			}
+			return names
+		}
+
+		It("should only list the services of each gateway's namespaces", func() {
+			Expect(services(server)).To(ConsistOf("calculator"))
+			Expect(services(other)).To(ConsistOf("search"))
+
+			infos := registry.UpdateRegistryStatus(gateway, gateway.Spec.Discovery.Namespaces)
+			Expect(infos).To(HaveLen(1))
+			Expect(infos[0].Name).To(Equal("calculator"))
+			Expect(gateway.Status.MCPServices).To(HaveLen(1))
+
+			infos = registry.UpdateRegistryStatus(otherGateway, otherGateway.Spec.Discovery.Namespaces)
+			Expect(infos).To(HaveLen(1))
+			Expect(infos[0].Name).To(Equal("search"))
+			Expect(otherGateway.Status.MCPServices).To(HaveLen(1))
+		})
+
+		It("should not route to the services of the other gateway's namespaces", func() {
+			Expect(get(server.handleMCPRequest, "/mcp/tools/search").Code).To(Equal(http.StatusNotFound))
+			Expect(get(other.handleMCPRequest, "/mcp/tools/calculator").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+// RemoteCluster is a cluster whose MCP services are federated into the registry
+type RemoteCluster struct {
+	// Name qualifies the names of the cluster's services in the registry
+	Name string `json:"name"`
+
+	// Address is the URL of the ingress that routes MCP requests into the cluster
+	Address string `json:"address"`
+}
+
+// ClusterServiceName returns the registry name of a service of a remote cluster. Service names
+// can't contain dots, so it never collides with the name of a local Service.
+func ClusterServiceName(cluster, name string) string {
+	return cluster + "." + name
+}
+
+// RemoteTarget returns where the MCP requests of a remote cluster's Service are sent: the
+// cluster's ingress address, with the Service's endpoint in the cluster as base path
+func RemoteTarget(cluster *RemoteCluster, svc *corev1.Service) (ServiceTarget, error) {
+	address, err := url.Parse(cluster.Address)
+	if err != nil {
+		return ServiceTarget{}, fmt.Errorf("address %q of cluster %s is invalid: %w", cluster.Address, cluster.Name, err)
+	}
+	if (address.Scheme != "http" && address.Scheme != "https") || address.Hostname() == "" {
+		return ServiceTarget{}, fmt.Errorf("address %q of cluster %s must be an http or https URL",
+			cluster.Address, cluster.Name)
+	}
+
+	target := ServiceTarget{
+		Scheme:   address.Scheme,
+		Host:     address.Hostname(),
+		Port:     defaultPort(address.Scheme),
+		BasePath: strings.TrimSuffix(address.Path, "/") + ServiceEndpoint(svc),
+	}
+	if port := address.Port(); port != "" {
+		number, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return ServiceTarget{}, fmt.Errorf("address %q of cluster %s has an invalid port", cluster.Address, cluster.Name)
+		}
+		target.Port = int32(number)
+	}
+	return target, nil
+}
+
+// RegisterRemoteService adds or updates a Service of a remote cluster. It is registered as
+// ClusterServiceName in the Service's namespace, with the default endpoint of that name, and
+// routed through the cluster's address. Nil endpoints keep the counts of the registered service.
+func (r *Registry) RegisterRemoteService(
+	ctx context.Context,
+	cluster RemoteCluster,
+	svc *corev1.Service,
+	serviceType ServiceType,
+	endpoints *Endpoints,
+) (*MCPService, error) {
+	return r.registerService(fetchfyv1alpha2.DiscoverySourceService, &cluster, svc, serviceType, endpoints)
+}
+
+// DeregisterCluster removes all services of a remote cluster from the registry. It returns
+// the number of removed services.
+func (r *Registry) DeregisterCluster(ctx context.Context, cluster string) int {
+	r.mutex.Lock()
+	defer r.deliver()
+	defer r.mutex.Unlock()
+
+	before := r.snapshot()
+	removed := 0
+	for key, svc := range r.services {
+		if svc.Cluster != nil && svc.Cluster.Name == cluster {
+			delete(r.services, key)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0
+	}
+
+	r.log.Info("Deregistered MCP services of remote cluster", "cluster", cluster, "services", removed)
+	r.resolveConflicts()
+	r.recordChanges(before)
+	return removed
+}
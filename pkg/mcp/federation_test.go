@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+var _ = Describe("Federation", func() {
+	newService := func(annotations map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "tools", Annotations: annotations},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}
+	}
+
+	DescribeTable("should route remote Services through the cluster's address",
+		func(address string, annotations map[string]string, expected ServiceTarget) {
+			target, err := RemoteTarget(&RemoteCluster{Name: "east", Address: address}, newService(annotations))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target).To(Equal(expected))
+		},
+		Entry("with the scheme's default port", "https://mcp.east.example.com", nil,
+			ServiceTarget{Scheme: "https", Host: "mcp.east.example.com", Port: 443, BasePath: "/mcp/tools/weather"}),
+		Entry("with a port and path prefix", "http://10.1.0.1:8080/east/", map[string]string{EndpointAnnotation: "/weather"},
+			ServiceTarget{Scheme: "http", Host: "10.1.0.1", Port: 8080, BasePath: "/east/weather"}),
+	)
+
+	It("should reject addresses that aren't http URLs", func() {
+		_, err := RemoteTarget(&RemoteCluster{Name: "east", Address: "mcp.east.example.com"}, newService(nil))
+		Expect(err).To(MatchError(ContainSubstring("must be an http or https URL")))
+	})
+
+	It("should register remote services with qualified names and deregister them by cluster", func() {
+		registry := NewRegistry(logr.Discard())
+		recorder := record.NewFakeRecorder(10)
+		registry.SetEventRecorder(recorder)
+		east := RemoteCluster{Name: "east", Address: "https://mcp.east.example.com"}
+
+		_, err := registry.RegisterService(context.Background(), newService(map[string]string{}), ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		mcpSvc, err := registry.RegisterRemoteService(context.Background(), east,
+			newService(map[string]string{EndpointAnnotation: "/mcp/tools/weather"}), ServiceTypeTool,
+			&Endpoints{Ready: 1, Total: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mcpSvc.Name).To(Equal("east.weather"))
+		Expect(mcpSvc.Endpoint).To(Equal("/mcp/tools/east.weather"))
+		Expect(mcpSvc.Status).To(Equal(ServiceStatusAvailable))
+		Expect(mcpSvc.Target.BasePath).To(Equal("/mcp/tools/weather"))
+		Expect(recorder.Events).To(BeEmpty())
+
+		By("listing remote services only for gateways federating the cluster")
+		gateway := &fetchfyv1alpha2.Gateway{}
+		Expect(registry.UpdateRegistryStatus(gateway, nil)).To(HaveLen(1))
+		gateway.Spec.Federation = &fetchfyv1alpha2.GatewayFederation{
+			Clusters: []fetchfyv1alpha2.FederatedCluster{{Name: "east"}},
+		}
+		infos := registry.UpdateRegistryStatus(gateway, nil)
+		Expect(infos).To(HaveLen(2))
+		Expect(infos[0].Cluster).To(Equal("east"))
+
+		Expect(registry.DeregisterCluster(context.Background(), "east")).To(Equal(1))
+		_, ok := registry.GetService(types.NamespacedName{Name: "east.weather", Namespace: "tools"})
+		Expect(ok).To(BeFalse())
+		_, ok = registry.GetService(types.NamespacedName{Name: "weather", Namespace: "tools"})
+		Expect(ok).To(BeTrue())
+	})
+})
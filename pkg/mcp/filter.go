@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+// serviceFilter decides which MCP services are visible through a listener.
+// A nil filter matches every service.
+type serviceFilter struct {
+	sources    map[fetchfyv1alpha2.DiscoverySource]bool
+	clusters   map[string]bool
+	types      map[ServiceType]bool
+	namespaces map[string]bool
+	selector   labels.Selector
+
+	// discoveryNamespaces and discoverySelector scope the gateway to the local services it
+	// discovers. Services of federated clusters are scoped by clusters only.
+	discoveryNamespaces map[string]bool
+	discoverySelector   labels.Selector
+
+	// none is set for listeners whose restrictions are invalid, which expose no services
+	none bool
+}
+
+// noServices is the filter of a listener whose restrictions failed to compile
+var noServices = &serviceFilter{none: true}
+
+// newServiceFilter compiles the service restrictions of a listener, and the discovery settings
+// and federated clusters of its gateway if set. The gateway discovers local services in the
+// given namespaces, resolved from its discovery settings; nil stands for all namespaces.
+func newServiceFilter(
+	spec *fetchfyv1alpha2.ListenerServices,
+	gateway *fetchfyv1alpha2.GatewaySpec,
+	namespaces []string,
+) (*serviceFilter, error) {
+	if spec == nil && gateway == nil {
+		return nil, nil
+	}
+
+	filter := &serviceFilter{}
+	if gateway != nil {
+		if namespaces != nil {
+			filter.discoveryNamespaces = make(map[string]bool, len(namespaces))
+			for _, namespace := range namespaces {
+				filter.discoveryNamespaces[namespace] = true
+			}
+		}
+		if gateway.Discovery != nil && gateway.Discovery.ServiceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(gateway.Discovery.ServiceSelector)
+			if err != nil {
+				return nil, err
+			}
+			filter.discoverySelector = selector
+		}
+		sources := gateway.DiscoverySources()
+		filter.sources = make(map[fetchfyv1alpha2.DiscoverySource]bool, len(sources))
+		for _, source := range sources {
+			filter.sources[source] = true
+		}
+		clusters := gateway.FederatedClusters()
+		filter.clusters = make(map[string]bool, len(clusters))
+		for _, cluster := range clusters {
+			filter.clusters[cluster] = true
+		}
+	}
+	if spec == nil {
+		return filter, nil
+	}
+	if len(spec.Types) > 0 {
+		filter.types = make(map[ServiceType]bool, len(spec.Types))
+		for _, serviceType := range spec.Types {
+			filter.types[ServiceType(serviceType)] = true
+		}
+	}
+	if len(spec.Namespaces) > 0 {
+		filter.namespaces = make(map[string]bool, len(spec.Namespaces))
+		for _, namespace := range spec.Namespaces {
+			filter.namespaces[namespace] = true
+		}
+	}
+	if spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		filter.selector = selector
+	}
+
+	return filter, nil
+}
+
+// GatewayDiscovers reports whether the gateway discovers the service, given the namespaces
+// resolved from its discovery settings, nil standing for all namespaces. A gateway whose
+// service selector is invalid discovers no services.
+func GatewayDiscovers(gateway *fetchfyv1alpha2.Gateway, namespaces []string, svc *MCPService) bool {
+	filter, err := newServiceFilter(nil, &gateway.Spec, namespaces)
+	return err == nil && filter.matches(svc)
+}
+
+// matches reports whether the service is visible through the filter
+func (f *serviceFilter) matches(svc *MCPService) bool {
+	if f == nil {
+		return true
+	}
+	if f.none {
+		return false
+	}
+	if f.sources != nil && !f.sources[svc.discoverySource()] {
+		return false
+	}
+	if f.clusters != nil && svc.Cluster != nil && !f.clusters[svc.Cluster.Name] {
+		return false
+	}
+	if svc.Cluster == nil {
+		if f.discoveryNamespaces != nil && !f.discoveryNamespaces[svc.Namespace] {
+			return false
+		}
+		if f.discoverySelector != nil && !f.discoverySelector.Matches(serviceLabels(svc)) {
+			return false
+		}
+	}
+	if f.types != nil && !f.types[svc.Type] {
+		return false
+	}
+	if f.namespaces != nil && !f.namespaces[svc.Namespace] {
+		return false
+	}
+	if f.selector != nil && !f.selector.Matches(serviceLabels(svc)) {
+		return false
+	}
+	return true
+}
+
+// selectedLabels adds the keys of the labels the filter's selectors match on
+func (f *serviceFilter) selectedLabels(keys map[string]bool) {
+	if f == nil {
+		return
+	}
+	for _, selector := range []labels.Selector{f.selector, f.discoverySelector} {
+		if selector == nil {
+			continue
+		}
+		requirements, _ := selector.Requirements()
+		for _, requirement := range requirements {
+			keys[requirement.Key()] = true
+		}
+	}
+}
+
+// serviceLabels returns the labels of the service's Service object
+func serviceLabels(svc *MCPService) labels.Set {
+	if svc.Service == nil {
+		return nil
+	}
+	return svc.Service.Labels
+}
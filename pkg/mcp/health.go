@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultHealthCheckInterval is the default interval between backend health probes
+	DefaultHealthCheckInterval = 30 * time.Second
+
+	// DefaultHealthCheckTimeout is the default timeout of a single backend health probe
+	DefaultHealthCheckTimeout = 3 * time.Second
+)
+
+// ProbeFunc checks whether an MCP service backend is reachable
+type ProbeFunc func(ctx context.Context, svc *MCPService) error
+
+// HealthChecker periodically probes the registered MCP services and records
+// their health in the registry
+type HealthChecker struct {
+	registry *Registry
+	log      logr.Logger
+
+	// Interval between probe rounds
+	Interval time.Duration
+
+	// Timeout of a single probe
+	Timeout time.Duration
+
+	// Probe checks a single backend, TCPProbe by default
+	Probe ProbeFunc
+}
+
+// NewHealthChecker creates a new health checker for the registry
+func NewHealthChecker(registry *Registry, log logr.Logger) *HealthChecker {
+	return &HealthChecker{
+		registry: registry,
+		log:      log.WithName("mcp-health"),
+		Interval: DefaultHealthCheckInterval,
+		Timeout:  DefaultHealthCheckTimeout,
+		Probe:    TCPProbe,
+	}
+}
+
+// NeedLeaderElection returns false, as every replica routes by the health of its own
+// registry. It implements manager.LeaderElectionRunnable.
+func (h *HealthChecker) NeedLeaderElection() bool {
+	return false
+}
+
+// Start runs the health checker until the context is cancelled. It implements manager.Runnable.
+func (h *HealthChecker) Start(ctx context.Context) error {
+	ticker := time.NewTicker(h.Interval)
+	defer ticker.Stop()
+
+	for {
+		h.CheckAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll probes every registered service once. It returns the services whose status changed.
+func (h *HealthChecker) CheckAll(ctx context.Context) []types.NamespacedName {
+	var changed []types.NamespacedName
+
+	for _, svc := range h.registry.ListServices() {
+		// Pending services have nothing to probe, conflicted and invalid services aren't routed
+		if svc.Status == ServiceStatusPending || svc.Status == ServiceStatusConflicted ||
+			svc.Status == ServiceStatusInvalid {
+			continue
+		}
+
+		probeCtx, cancel := context.WithTimeout(ctx, h.Timeout)
+		err := h.Probe(probeCtx, svc)
+		cancel()
+
+		name := types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}
+		if h.registry.SetServiceHealth(name, err) {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed
+}
+
+// TCPProbe checks that the service's target accepts TCP connections
+func TCPProbe(ctx context.Context, svc *MCPService) error {
+	if svc.Target.Port == 0 {
+		return fmt.Errorf("service %s/%s has no target port", svc.Namespace, svc.Name)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", svc.Target.URL().Host)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
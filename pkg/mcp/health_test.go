@@ -0,0 +1,89 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("HealthChecker", func() {
+	var (
+		registry *Registry
+		checker  *HealthChecker
+		probeErr error
+		svc      *corev1.Service
+		name     = types.NamespacedName{Name: "calculator", Namespace: "tools"}
+	)
+
+	BeforeEach(func() {
+		registry = NewRegistry(logr.Discard())
+		checker = NewHealthChecker(registry, logr.Discard())
+		probeErr = nil
+		checker.Probe = func(ctx context.Context, svc *MCPService) error { return probeErr }
+
+		svc = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace, Annotations: map[string]string{}},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Port: 80}},
+			},
+		}
+		_, err := registry.RegisterService(context.Background(), svc, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should mark failing backends unavailable and report the change", func() {
+		probeErr = errors.New("connection refused")
+
+		Expect(checker.CheckAll(context.Background())).To(ConsistOf(name))
+
+		mcpSvc, ok := registry.GetService(name)
+		Expect(ok).To(BeTrue())
+		Expect(mcpSvc.Status).To(Equal(ServiceStatusUnavailable))
+		Expect(mcpSvc.Message).To(Equal("connection refused"))
+	})
+
+	It("should only report changes once", func() {
+		Expect(checker.CheckAll(context.Background())).To(BeEmpty())
+
+		probeErr = errors.New("connection refused")
+		Expect(checker.CheckAll(context.Background())).To(HaveLen(1))
+		Expect(checker.CheckAll(context.Background())).To(BeEmpty())
+
+		probeErr = nil
+		Expect(checker.CheckAll(context.Background())).To(HaveLen(1))
+	})
+
+	It("should keep the probe result when the service is re-registered", func() {
+		probeErr = errors.New("connection refused")
+		checker.CheckAll(context.Background())
+
+		_, err := registry.RegisterService(context.Background(), svc, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+
+		mcpSvc, _ := registry.GetService(name)
+		Expect(mcpSvc.Status).To(Equal(ServiceStatusUnavailable))
+	})
+})
@@ -0,0 +1,251 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/metrics"
+)
+
+// mirrorTimeout bounds a mirrored request and the wait for the served response it is compared with
+const mirrorTimeout = 30 * time.Second
+
+const (
+	// MirrorResultMatch means the shadow response has the same status and result as the served response
+	MirrorResultMatch = "match"
+
+	// MirrorResultStatusMismatch means the shadow response has a different HTTP status
+	MirrorResultStatusMismatch = "status_mismatch"
+
+	// MirrorResultResultMismatch means the shadow response has a different JSON-RPC result or error
+	MirrorResultResultMismatch = "result_mismatch"
+
+	// MirrorResultShadowError means the shadow backend couldn't be reached
+	MirrorResultShadowError = "shadow_error"
+)
+
+// Mirror copies requests of a route rule to a shadow backend
+type Mirror struct {
+	Backend Backend
+
+	// Percent is the percentage of sessions, or of requests without a session, that are mirrored
+	Percent int32
+
+	// Audit logs the requests whose shadow response differs from the served response
+	Audit bool
+}
+
+// responseOutcome summarizes a response for the comparison of served and shadow responses
+type responseOutcome struct {
+	status    int
+	latency   time.Duration
+	hash      string
+	sessionID string
+	err       error
+}
+
+// mirroredRequest is a request that was copied to a shadow backend. The outcome of the
+// served request is sent to served once the response is complete.
+type mirroredRequest struct {
+	route      string
+	mirror     *Mirror
+	path       string
+	httpMethod string
+	method     string
+	sessionID  string
+	served     chan responseOutcome
+}
+
+// done reports the outcome of the served request
+func (m *mirroredRequest) done(outcome responseOutcome) {
+	if m != nil {
+		m.served <- outcome
+	}
+}
+
+// startMirror copies the request to the shadow backend if it is sampled, and returns
+// nil if it isn't. Sessions are sampled as a whole: a session is mirrored if the request
+// that created it was, so the shadow backend sees complete sessions. Its requests carry
+// the session ID the shadow backend assigned.
+func (s *Server) startMirror(r *http.Request, route string, mirror *Mirror) *mirroredRequest {
+	sessionID := r.Header.Get(SessionIDHeader)
+	shadowSessionID := ""
+	if sessionID != "" {
+		var sampled bool
+		if shadowSessionID, sampled = s.shadows.get(sessionID); !sampled {
+			return nil
+		}
+	} else if rand.Intn(100) >= int(mirror.Percent) {
+		return nil
+	}
+
+	body, ok := readBody(r)
+	if !ok {
+		return nil
+	}
+
+	s.mutex.Lock()
+	target := *s.backendURL(mirror.Backend)
+	gateway := s.gatewayRef.String()
+	s.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), mirrorTimeout)
+	shadow := r.Clone(ctx)
+	shadow.RequestURI = ""
+	shadow.Host = ""
+	target.Path = r.URL.Path
+	target.RawQuery = r.URL.RawQuery
+	shadow.URL = &target
+	shadow.Body = io.NopCloser(bytes.NewReader(body))
+	shadow.ContentLength = int64(len(body))
+	removeClientCredentials(shadow.Header)
+	shadow.Header.Del(SessionIDHeader)
+	if shadowSessionID != "" {
+		shadow.Header.Set(SessionIDHeader, shadowSessionID)
+	}
+
+	m := &mirroredRequest{
+		route:      route,
+		mirror:     mirror,
+		path:       r.URL.Path,
+		httpMethod: r.Method,
+		sessionID:  sessionID,
+		served:     make(chan responseOutcome, 1),
+	}
+	var rpc jsonRPCRequest
+	if json.Unmarshal(body, &rpc) == nil {
+		m.method = rpc.Method
+	}
+
+	go func() {
+		defer cancel()
+		s.compareMirrored(gateway, m, s.sendShadow(shadow))
+	}()
+	return m
+}
+
+// sendShadow sends a mirrored request to the shadow backend and summarizes the response
+func (s *Server) sendShadow(req *http.Request) responseOutcome {
+	start := time.Now()
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return responseOutcome{err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxInspectedBody+1))
+	outcome := responseOutcome{
+		status:    resp.StatusCode,
+		latency:   time.Since(start),
+		sessionID: resp.Header.Get(SessionIDHeader),
+		err:       err,
+	}
+	if len(body) <= maxInspectedBody {
+		outcome.hash = resultHash(resp.Header.Get("Content-Type"), body)
+	}
+	return outcome
+}
+
+// compareMirrored compares the shadow response with the served response and records the result
+func (s *Server) compareMirrored(gateway string, m *mirroredRequest, shadow responseOutcome) {
+	var served responseOutcome
+	select {
+	case served = <-m.served:
+	case <-time.After(mirrorTimeout):
+		return
+	}
+	if served.err != nil {
+		// The client went away, there is nothing to compare with
+		return
+	}
+
+	switch {
+	case m.sessionID == "" && served.sessionID != "" && shadow.sessionID != "":
+		// The mirrored request created a session, mirror the rest of it
+		s.shadows.set(served.sessionID, shadow.sessionID)
+	case m.httpMethod == http.MethodDelete && served.status < http.StatusBadRequest:
+		s.shadows.remove(m.sessionID)
+	}
+
+	backend := m.mirror.Backend.Service.String()
+	result := MirrorResultMatch
+	switch {
+	case shadow.err != nil:
+		result = MirrorResultShadowError
+	case shadow.status != served.status:
+		result = MirrorResultStatusMismatch
+	case shadow.hash != "" && served.hash != "" && shadow.hash != served.hash:
+		result = MirrorResultResultMismatch
+	}
+
+	metrics.MirrorRequestCount.WithLabelValues(gateway, m.route, backend, result).Inc()
+	if shadow.err == nil {
+		metrics.MirrorLatencyDelta.WithLabelValues(gateway, m.route, backend).
+			Observe((shadow.latency - served.latency).Seconds())
+	}
+
+	if m.mirror.Audit && result != MirrorResultMatch {
+		keysAndValues := []interface{}{
+			"gateway", gateway,
+			"route", m.route,
+			"shadowBackend", backend,
+			"path", m.path,
+			"method", m.method,
+			"session", m.sessionID,
+			"result", result,
+			"servedStatus", served.status,
+			"shadowStatus", shadow.status,
+			"servedLatency", served.latency.String(),
+			"shadowLatency", shadow.latency.String(),
+			"servedHash", served.hash,
+			"shadowHash", shadow.hash,
+		}
+		if shadow.err != nil {
+			keysAndValues = append(keysAndValues, "shadowError", shadow.err.Error())
+		}
+		s.audit.Info("Mirrored response differs", keysAndValues...)
+	}
+}
+
+// resultHash returns a hash of the JSON-RPC result or error of a response, which is
+// either a JSON body or an SSE stream of JSON-RPC messages. Bodies that aren't JSON-RPC
+// are hashed as a whole.
+func resultHash(contentType string, body []byte) string {
+	content := body
+	if resp, ok := responseMessage(contentType, body); ok {
+		content = resp.Result
+		if content == nil {
+			content = resp.Error
+		}
+	}
+
+	var compact bytes.Buffer
+	if json.Compact(&compact, content) == nil {
+		content = compact.Bytes()
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:8])
+}
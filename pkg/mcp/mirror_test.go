@@ -0,0 +1,216 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/metrics"
+)
+
+var _ = Describe("Mirroring", func() {
+	var (
+		registry    *Registry
+		server      *Server
+		primary     *httptest.Server
+		shadow      *httptest.Server
+		results     map[string]string
+		received    []string
+		credentials []string
+		mutex       sync.Mutex
+	)
+
+	backend := func(name string) Backend {
+		return Backend{Service: types.NamespacedName{Name: name, Namespace: "tools"}, Port: 80, Weight: 1}
+	}
+
+	// mcpBackend answers initialize with a new session and tools/call with the configured result
+	mcpBackend := func(name string, record bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mutex.Lock()
+			result := results[name]
+			if record {
+				received = append(received, r.Header.Get(SessionIDHeader)+" "+string(body))
+			}
+			credentials = append(credentials, r.Header.Get("Authorization"))
+			mutex.Unlock()
+
+			if strings.Contains(string(body), "initialize") {
+				w.Header().Set(SessionIDHeader, name+"-session")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":` + result + `}`))
+		}))
+	}
+
+	post := func(body, sessionID string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "http://gw.example.com/mcp/tools/weather", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(SessionIDHeader, sessionID)
+		}
+		return req.WithContext(context.WithValue(req.Context(), listenerKey{}, "mcp"))
+	}
+
+	setMirror := func(mirror *Mirror) {
+		registry.SetRoute(&Route{
+			Name:      types.NamespacedName{Name: "weather", Namespace: "tools"},
+			CreatedAt: time.Now(),
+			Parents:   []RouteParent{{Gateway: types.NamespacedName{Name: "gw", Namespace: "default"}}},
+			Rules:     []RouteRule{{Backends: []Backend{backend("weather")}, Mirror: mirror}},
+		})
+	}
+
+	mirrored := func(result string) func() float64 {
+		counter := metrics.MirrorRequestCount.WithLabelValues("default/gw", "tools/weather", "tools/weather-v2", result)
+		return func() float64 { return testutil.ToFloat64(counter) }
+	}
+
+	BeforeEach(func() {
+		results = map[string]string{"weather": `{"temp":21}`, "weather-v2": `{"temp":21}`}
+		received = nil
+		credentials = nil
+		primary = mcpBackend("weather", false)
+		shadow = mcpBackend("weather-v2", true)
+
+		registry = NewRegistry(logr.Discard())
+		server = NewServer(registry, logr.Discard())
+		server.Configure(newTestGateway(8080), nil)
+		server.SetBackendURLFunc(func(backend Backend) *url.URL {
+			target := primary.URL
+			if backend.Service.Name == "weather-v2" {
+				target = shadow.URL
+			}
+			parsed, _ := url.Parse(target)
+			return parsed
+		})
+	})
+
+	AfterEach(func() {
+		primary.Close()
+		shadow.Close()
+	})
+
+	It("should serve the primary response and count matching shadow responses", func() {
+		setMirror(&Mirror{Backend: backend("weather-v2"), Percent: 100})
+		before := mirrored(MirrorResultMatch)()
+
+		rec := httptest.NewRecorder()
+		server.handleMCPRequest(rec, post(`{"method":"tools/call","params":{"name":"forecast"}}`, ""))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring(`{"temp":21}`))
+		Eventually(mirrored(MirrorResultMatch)).Should(Equal(before + 1))
+	})
+
+	It("should not pass the client's credentials to the primary and shadow backends", func() {
+		setMirror(&Mirror{Backend: backend("weather-v2"), Percent: 100})
+		before := mirrored(MirrorResultMatch)()
+
+		req := post(`{"method":"tools/call"}`, "")
+		req.Header.Set("Authorization", "Bearer gateway-token")
+		rec := httptest.NewRecorder()
+		server.handleMCPRequest(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Eventually(mirrored(MirrorResultMatch)).Should(Equal(before + 1))
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(credentials).To(Equal([]string{"", ""}))
+	})
+
+	It("should count shadow responses with a different result", func() {
+		results["weather-v2"] = `{"temp":  22}`
+		setMirror(&Mirror{Backend: backend("weather-v2"), Percent: 100, Audit: true})
+		before := mirrored(MirrorResultResultMismatch)()
+
+		rec := httptest.NewRecorder()
+		server.handleMCPRequest(rec, post(`{"method":"tools/call"}`, ""))
+		Expect(rec.Body.String()).To(ContainSubstring(`{"temp":21}`))
+		Eventually(mirrored(MirrorResultResultMismatch)).Should(Equal(before + 1))
+	})
+
+	It("should count unreachable shadow backends", func() {
+		shadow.Close()
+		setMirror(&Mirror{Backend: backend("weather-v2"), Percent: 100})
+		before := mirrored(MirrorResultShadowError)()
+
+		rec := httptest.NewRecorder()
+		server.handleMCPRequest(rec, post(`{"method":"tools/call"}`, ""))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Eventually(mirrored(MirrorResultShadowError)).Should(Equal(before + 1))
+	})
+
+	It("should mirror whole sessions with the shadow backend's session ID", func() {
+		setMirror(&Mirror{Backend: backend("weather-v2"), Percent: 100})
+
+		rec := httptest.NewRecorder()
+		server.handleMCPRequest(rec, post(`{"method":"initialize"}`, ""))
+		sessionID := rec.Header().Get(SessionIDHeader)
+		Expect(sessionID).To(HaveSuffix(".weather-session"))
+		Eventually(func() bool {
+			_, ok := server.shadows.get("weather-session")
+			return ok
+		}).Should(BeTrue())
+
+		server.handleMCPRequest(httptest.NewRecorder(), post(`{"method":"tools/call"}`, sessionID))
+		Eventually(func() []string {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return append([]string(nil), received...)
+		}).Should(ContainElement(`weather-v2-session {"method":"tools/call"}`))
+
+		By("not mirroring sessions that weren't sampled")
+		Expect(server.startMirror(post(`{}`, "other-session"), "tools/weather",
+			&Mirror{Backend: backend("weather-v2"), Percent: 100})).To(BeNil())
+	})
+
+	It("should not mirror when the percentage is 0", func() {
+		setMirror(&Mirror{Backend: backend("weather-v2"), Percent: 0})
+
+		for i := 0; i < 10; i++ {
+			server.handleMCPRequest(httptest.NewRecorder(), post(`{"method":"tools/call"}`, ""))
+		}
+		Consistently(func() int {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return len(received)
+		}, 200*time.Millisecond).Should(BeZero())
+	})
+
+	It("should compare the results of JSON and event stream responses", func() {
+		json := resultHash("application/json", []byte(`{"jsonrpc":"2.0","id":1,"result":{"temp": 21}}`))
+		stream := resultHash("text/event-stream",
+			[]byte("event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":2,\"result\":{\"temp\":21}}\n\n"))
+		Expect(stream).To(Equal(json))
+		Expect(resultHash("application/json", []byte(`{"jsonrpc":"2.0","id":1,"result":{"temp":22}}`))).
+			NotTo(Equal(json))
+	})
+})
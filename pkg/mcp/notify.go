@@ -0,0 +1,321 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ToolsListChangedNotification tells clients that the list of tools changed
+	ToolsListChangedNotification = "notifications/tools/list_changed"
+
+	// PromptsListChangedNotification tells clients that the list of prompts changed
+	PromptsListChangedNotification = "notifications/prompts/list_changed"
+
+	// ResourcesListChangedNotification tells clients that the list of resources changed
+	ResourcesListChangedNotification = "notifications/resources/list_changed"
+
+	// ResourceUpdatedNotification tells subscribed clients that a resource changed
+	ResourceUpdatedNotification = "notifications/resources/updated"
+
+	// LoggingMessageNotification passes a log message to clients
+	LoggingMessageNotification = "notifications/message"
+
+	// drainingRetry is the reconnection delay in milliseconds suggested to clients whose stream
+	// ends because its listener is draining
+	drainingRetry = 1000
+
+	// listChangedDebounce is how long list changes are collected before clients are notified,
+	// so that a burst of changes results in a single notification per list
+	listChangedDebounce = 500 * time.Millisecond
+
+	// streamBuffer is the number of notifications buffered per client stream
+	streamBuffer = 16
+)
+
+// allListsChanged are the notifications sent when a service is added or removed
+var allListsChanged = []string{
+	ToolsListChangedNotification,
+	PromptsListChangedNotification,
+	ResourcesListChangedNotification,
+}
+
+// ListChange reports that lists of tools, prompts or resources offered through the gateway changed
+type ListChange struct {
+	// Service is the Service whose lists changed
+	Service types.NamespacedName
+
+	// MCPService is the registered service, nil for backends that aren't registered, e.g. route backends
+	MCPService *MCPService
+
+	// Notifications are the list_changed notifications to send to clients
+	Notifications []string
+}
+
+// OnListChanged calls fn for every list change, until the returned function is called
+func (r *Registry) OnListChanged(fn func(ListChange)) (cancel func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	id := r.nextListener
+	r.nextListener++
+	r.listChanged[id] = fn
+	return func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.listChanged, id)
+	}
+}
+
+// NotifyListChanged reports a list_changed notification a backend Service sent
+func (r *Registry) NotifyListChanged(name types.NamespacedName, notification string) {
+	r.mutex.Lock()
+	defer r.deliver()
+	defer r.mutex.Unlock()
+
+	r.emitListChanged(ListChange{Service: name, MCPService: r.services[name], Notifications: []string{notification}})
+}
+
+// emitListChanged queues a list change for deliver. It must be called with the registry lock held.
+func (r *Registry) emitListChanged(change ListChange) {
+	r.pendingChanges = append(r.pendingChanges, change)
+}
+
+// notificationHub fans out consolidated list_changed notifications to the client streams of a server
+type notificationHub struct {
+	mutex       sync.Mutex
+	subscribers map[*streamSubscriber]struct{}
+	pending     map[string][]*MCPService
+	timer       *time.Timer
+}
+
+// streamSubscriber is a client stream receiving notifications for the services its listener exposes
+type streamSubscriber struct {
+	filter   *serviceFilter
+	messages chan []byte
+}
+
+// newNotificationHub creates a hub without subscribers
+func newNotificationHub() *notificationHub {
+	return &notificationHub{
+		subscribers: make(map[*streamSubscriber]struct{}),
+		pending:     make(map[string][]*MCPService),
+	}
+}
+
+// subscribe registers a client stream. The returned function unregisters it.
+func (h *notificationHub) subscribe(filter *serviceFilter) (*streamSubscriber, func()) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	sub := &streamSubscriber{filter: filter, messages: make(chan []byte, streamBuffer)}
+	h.subscribers[sub] = struct{}{}
+	return sub, func() {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+		delete(h.subscribers, sub)
+	}
+}
+
+// publish queues the notifications of a list change. Notifications queued within
+// listChangedDebounce of each other are sent once.
+func (h *notificationHub) publish(change ListChange) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, notification := range change.Notifications {
+		h.pending[notification] = append(h.pending[notification], change.MCPService)
+	}
+	if h.timer == nil {
+		h.timer = time.AfterFunc(listChangedDebounce, h.flush)
+	}
+}
+
+// flush sends the queued notifications to every subscriber that can see a changed service
+func (h *notificationHub) flush() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	notifications := make([]string, 0, len(h.pending))
+	for notification := range h.pending {
+		notifications = append(notifications, notification)
+	}
+	sort.Strings(notifications)
+
+	for sub := range h.subscribers {
+		for _, notification := range notifications {
+			if !sub.sees(h.pending[notification]) {
+				continue
+			}
+			msg, _ := json.Marshal(struct {
+				JSONRPC string `json:"jsonrpc"`
+				Method  string `json:"method"`
+			}{"2.0", notification})
+			select {
+			case sub.messages <- msg:
+			default:
+				// The client isn't keeping up, it is already behind on list changes
+			}
+		}
+	}
+
+	h.pending = make(map[string][]*MCPService)
+	h.timer = nil
+}
+
+// sees reports whether any of the changed services is exposed to the subscriber.
+// Backends that aren't registered services are exposed to all subscribers.
+func (sub *streamSubscriber) sees(services []*MCPService) bool {
+	for _, svc := range services {
+		if svc == nil || sub.filter.matches(svc) {
+			return true
+		}
+	}
+	return false
+}
+
+// drainingNotice is the last SSE event of a client stream whose listener is draining. Its log
+// notification tells the client why the stream ends, and its retry field how soon to reconnect.
+func drainingNotice() []byte {
+	msg, _ := json.Marshal(struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params"`
+	}{"2.0", LoggingMessageNotification, map[string]string{
+		"level":  "notice",
+		"logger": "fetchfy-gateway",
+		"data":   "The MCP gateway listener is draining, reconnect to continue the session",
+	}})
+	return []byte(fmt.Sprintf("retry: %d\nevent: message\ndata: %s\n\n", drainingRetry, msg))
+}
+
+// drainingStream ends a proxied SSE stream with the drainingNotice once the stream is cut
+// because its listener is draining, so that the client learns why the stream ended
+type drainingStream struct {
+	io.ReadCloser
+	drained *atomic.Bool
+	notice  []byte
+	ended   bool
+	last    byte
+}
+
+// Read reads from the stream until it is cut by draining, and then returns the notice
+func (d *drainingStream) Read(p []byte) (int, error) {
+	if !d.ended {
+		n, err := d.ReadCloser.Read(p)
+		if n > 0 {
+			d.last = p[n-1]
+		}
+		if err == nil || !d.drained.Load() {
+			return n, err
+		}
+		d.ended = true
+		d.notice = drainingNotice()
+		if d.last != 0 && d.last != '\n' {
+			// Dispatch the event the stream was cut in, so it doesn't swallow the notice
+			d.notice = append([]byte("\n\n"), d.notice...)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+	if len(d.notice) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, d.notice)
+	d.notice = d.notice[n:]
+	return n, nil
+}
+
+// onListChanged invalidates the cached lists of the changed service and notifies the client streams
+func (s *Server) onListChanged(change ListChange) {
+	for _, notification := range change.Notifications {
+		s.invalidateCached(change.Service, notification, "")
+	}
+	s.notifications.publish(change)
+}
+
+// notificationScanner watches an SSE response body for notifications while it is read
+type notificationScanner struct {
+	io.ReadCloser
+	line   []byte
+	notify func(msg *rpcMessage)
+}
+
+// Read reads from the body and passes each complete data line that is a notification to notify
+func (n *notificationScanner) Read(p []byte) (int, error) {
+	count, err := n.ReadCloser.Read(p)
+	data := p[:count]
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			if len(n.line)+len(data) <= maxInspectedBody {
+				n.line = append(n.line, data...)
+			}
+			break
+		}
+		n.line = append(n.line, data[:i]...)
+		n.scanLine()
+		data = data[i+1:]
+	}
+	return count, err
+}
+
+// scanLine inspects the buffered line and resets it
+func (n *notificationScanner) scanLine() {
+	line := bytes.TrimSuffix(n.line, []byte("\r"))
+	n.line = n.line[:0]
+	payload, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok || !bytes.Contains(payload, []byte(`"notifications/`)) {
+		return
+	}
+	var msg rpcMessage
+	if json.Unmarshal(bytes.TrimSpace(payload), &msg) == nil && len(msg.ID) == 0 {
+		n.notify(&msg)
+	}
+}
+
+// watchNotifications wraps an SSE response body from a backend so that its list_changed
+// notifications reach the registry, and its resources/updated notifications the cache
+func (s *Server) watchNotifications(resp *http.Response, backend Backend) {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	resp.Body = &notificationScanner{
+		ReadCloser: resp.Body,
+		notify: func(msg *rpcMessage) {
+			switch {
+			case listChangedMethods[msg.Method] != nil:
+				s.registry.NotifyListChanged(backend.Service, msg.Method)
+			case msg.Method == ResourceUpdatedNotification:
+				s.invalidateCached(backend.Service, msg.Method, msg.resourceURI())
+			}
+		},
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+var _ = Describe("List change notifications", func() {
+	var registry *Registry
+
+	newService := func(namespace, name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: map[string]string{EndpointAnnotation: "/mcp/" + namespace + "/" + name},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}
+	}
+
+	BeforeEach(func() {
+		registry = NewRegistry(logr.Discard())
+	})
+
+	It("should send consolidated notifications to the client streams that can see the service", func() {
+		server := NewServer(registry, logr.Discard())
+		gateway := newTestGateway(8080)
+		gateway.Spec.Listeners = append(gateway.Spec.Listeners, fetchfyv1alpha2.Listener{
+			Name:     "internal",
+			Port:     8081,
+			Protocol: fetchfyv1alpha2.ProtocolHTTP,
+			Services: &fetchfyv1alpha2.ListenerServices{Namespaces: []string{"internal"}},
+		})
+		server.Configure(gateway, nil)
+		defer server.Stop(context.Background())
+
+		// open connects to the gateway stream on the listener and returns the received data lines
+		open := func(listener string) (func() []string, func()) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				server.handleMCPRequest(w, r.WithContext(context.WithValue(r.Context(), listenerKey{}, listener)))
+			}))
+			req, err := http.NewRequest(http.MethodGet, backend.URL+"/mcp/", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", "text/event-stream")
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+
+			var mutex sync.Mutex
+			var lines []string
+			go func() {
+				scanner := bufio.NewScanner(resp.Body)
+				for scanner.Scan() {
+					if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+						mutex.Lock()
+						lines = append(lines, data)
+						mutex.Unlock()
+					}
+				}
+			}()
+			received := func() []string {
+				mutex.Lock()
+				defer mutex.Unlock()
+				return append([]string(nil), lines...)
+			}
+			return received, func() {
+				resp.Body.Close()
+				backend.Close()
+			}
+		}
+
+		public, closePublic := open("mcp")
+		defer closePublic()
+		internal, closeInternal := open("internal")
+		defer closeInternal()
+		Eventually(func() int {
+			server.notifications.mutex.Lock()
+			defer server.notifications.mutex.Unlock()
+			return len(server.notifications.subscribers)
+		}).Should(Equal(2))
+
+		By("registering two services in a burst")
+		for _, name := range []string{"weather", "calendar"} {
+			_, err := registry.RegisterService(context.Background(), newService("tools", name), ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Eventually(public).Should(ConsistOf(
+			`{"jsonrpc":"2.0","method":"notifications/prompts/list_changed"}`,
+			`{"jsonrpc":"2.0","method":"notifications/resources/list_changed"}`,
+			`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`,
+		))
+		Consistently(public, 2*listChangedDebounce).Should(HaveLen(3))
+		Expect(internal()).To(BeEmpty())
+
+		By("forwarding a backend's list_changed notification")
+		registry.NotifyListChanged(types.NamespacedName{Name: "search", Namespace: "internal"}, ToolsListChangedNotification)
+		Eventually(internal).Should(ConsistOf(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`))
+	})
+
+	It("should subscribe to the notification streams of registered services", func() {
+		var mutex sync.Mutex
+		var deleted []string
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				body, _ := io.ReadAll(r.Body)
+				if strings.Contains(string(body), `"initialize"`) {
+					w.Header().Set(SessionIDHeader, "gateway-session")
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			case http.MethodGet:
+				Expect(r.Header.Get(SessionIDHeader)).To(Equal("gateway-session"))
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}\n\n")
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			case http.MethodDelete:
+				mutex.Lock()
+				deleted = append(deleted, r.Header.Get(SessionIDHeader))
+				mutex.Unlock()
+			}
+		}))
+		defer backend.Close()
+
+		_, err := registry.RegisterService(context.Background(), newService("tools", "weather"), ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+
+		changes := make(chan ListChange, 10)
+		cancelListener := registry.OnListChanged(func(change ListChange) { changes <- change })
+		defer cancelListener()
+
+		subscriber := NewNotificationSubscriber(registry, logr.Discard())
+		subscriber.BackendURL = func(Backend) *url.URL {
+			target, _ := url.Parse(backend.URL)
+			return target
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			Expect(subscriber.Start(ctx)).To(Succeed())
+		}()
+
+		var change ListChange
+		Eventually(changes).Should(Receive(&change))
+		Expect(change.Service).To(Equal(types.NamespacedName{Name: "weather", Namespace: "tools"}))
+		Expect(change.Notifications).To(Equal([]string{ToolsListChangedNotification}))
+
+		By("ending the session when the subscriber stops")
+		cancel()
+		Eventually(done, 5*time.Second).Should(BeClosed())
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(deleted).To(ConsistOf("gateway-session"))
+	})
+})
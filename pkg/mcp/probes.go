@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// HealthzPath is the path of the liveness endpoint of each listener
+	HealthzPath = "/healthz"
+
+	// ReadyzPath is the path of the readiness endpoint of each listener
+	ReadyzPath = "/readyz"
+)
+
+// HealthState is the overall state of a listener in a health report
+type HealthState string
+
+const (
+	// HealthStateReady means all backends visible through the listener are available
+	HealthStateReady HealthState = "Ready"
+
+	// HealthStateDegraded means some, but not all, backends are unavailable
+	HealthStateDegraded HealthState = "Degraded"
+
+	// HealthStateUnavailable means none of the backends visible through the listener are available
+	HealthStateUnavailable HealthState = "Unavailable"
+
+	// HealthStateDraining means the listener is draining and refuses new sessions
+	HealthStateDraining HealthState = "Draining"
+)
+
+// HealthReport describes the health of a listener and of the backends visible through it
+type HealthReport struct {
+	Gateway  string          `json:"gateway"`
+	Listener string          `json:"listener"`
+	State    HealthState     `json:"state"`
+	Message  string          `json:"message"`
+	Backends []BackendHealth `json:"backends"`
+}
+
+// BackendHealth describes the health of a single backend
+type BackendHealth struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster,omitempty"`
+	Endpoint  string `json:"endpoint"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+
+	// Endpoints counts the ready and total endpoints, unset when they aren't tracked
+	Endpoints *Endpoints `json:"endpoints,omitempty"`
+
+	// LastProbe is the time of the last health probe, unset if never probed
+	LastProbe *time.Time `json:"lastProbe,omitempty"`
+
+	// Stale is set while a service restored after an operator restart is not revalidated
+	Stale bool `json:"stale,omitempty"`
+}
+
+// Ready reports whether the listener should receive new sessions
+func (h *HealthReport) Ready() bool {
+	return h.State == HealthStateReady || h.State == HealthStateDegraded
+}
+
+// BuildHealthReport builds the health report of a listener. Conflicted and invalid services
+// are listed but don't count towards the state, as they aren't routed.
+func (s *Server) BuildHealthReport(listener string, draining bool) *HealthReport {
+	gatewayRef, settings := s.discoveryConfig(listener)
+
+	report := &HealthReport{
+		Gateway:  gatewayRef,
+		Listener: listener,
+		Backends: []BackendHealth{},
+	}
+
+	routed, available := 0, 0
+	for _, svc := range s.registry.ListServices() {
+		if !settings.filter.matches(svc) {
+			continue
+		}
+		report.Backends = append(report.Backends, backendHealth(svc))
+
+		switch svc.Status {
+		case ServiceStatusConflicted, ServiceStatusInvalid:
+		case ServiceStatusAvailable:
+			routed++
+			available++
+		default:
+			routed++
+		}
+	}
+
+	sort.Slice(report.Backends, func(i, j int) bool {
+		a, b := report.Backends[i], report.Backends[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	switch {
+	case draining:
+		report.State = HealthStateDraining
+		report.Message = "Listener is draining"
+	case routed == 0:
+		report.State = HealthStateReady
+		report.Message = "No MCP services are routed"
+	case available == routed:
+		report.State = HealthStateReady
+		report.Message = fmt.Sprintf("All %d backends are healthy", routed)
+	case available > 0:
+		report.State = HealthStateDegraded
+		report.Message = fmt.Sprintf("%d of %d backends are unhealthy", routed-available, routed)
+	default:
+		report.State = HealthStateUnavailable
+		report.Message = fmt.Sprintf("All %d backends are unhealthy", routed)
+	}
+
+	return report
+}
+
+// backendHealth describes the health of a registered service
+func backendHealth(svc *MCPService) BackendHealth {
+	health := BackendHealth{
+		Name:      svc.Name,
+		Namespace: svc.Namespace,
+		Cluster:   svc.clusterName(),
+		Endpoint:  svc.Endpoint,
+		Status:    string(svc.Status),
+		Message:   svc.Message,
+		Stale:     svc.Stale,
+	}
+	if svc.Endpoints != nil {
+		endpoints := *svc.Endpoints
+		health.Endpoints = &endpoints
+	}
+	if !svc.LastProbe.IsZero() {
+		lastProbe := svc.LastProbe
+		health.LastProbe = &lastProbe
+	}
+	return health
+}
+
+// handleHealthz serves the health report of the listener. It answers 200 OK as long as the
+// listener serves requests, whatever the health of its backends.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.writeHealthReport(w, r, http.StatusOK, s.BuildHealthReport(listenerName(r), isDraining(r.Context())))
+}
+
+// handleReadyz serves the health report of the listener. It answers 503 Service Unavailable
+// while the listener drains or when none of its backends are available, so that load
+// balancers send new sessions elsewhere.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report := s.BuildHealthReport(listenerName(r), isDraining(r.Context()))
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	s.writeHealthReport(w, r, status, report)
+}
+
+// writeHealthReport answers a probe with the status code, and with the report to clients that
+// may access the listener. Load balancers probing a listener with authentication only get the
+// status code, as the report names the backends and describes their failures.
+func (s *Server) writeHealthReport(w http.ResponseWriter, r *http.Request, status int, report *HealthReport) {
+	if _, ok := s.authenticate(r); !ok {
+		w.WriteHeader(status)
+		return
+	}
+	writeJSON(w, status, report)
+}
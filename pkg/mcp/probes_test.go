@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+var _ = Describe("Health probes", func() {
+	var (
+		registry *Registry
+		server   *Server
+	)
+
+	register := func(name string) types.NamespacedName {
+		_, err := registry.RegisterService(context.Background(), &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "tools",
+				Annotations: map[string]string{EndpointAnnotation: "/mcp/tools/" + name},
+			},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Port: 80}},
+			},
+		}, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		return types.NamespacedName{Name: name, Namespace: "tools"}
+	}
+
+	BeforeEach(func() {
+		registry = NewRegistry(logr.Discard())
+		server = NewServer(registry, logr.Discard())
+		server.Configure(newTestGateway(8080), nil)
+	})
+
+	getWithToken := func(
+		handler http.HandlerFunc,
+		draining bool,
+		token string,
+	) (*httptest.ResponseRecorder, *HealthReport) {
+		req := httptest.NewRequest(http.MethodGet, "http://gw.example.com"+ReadyzPath, nil)
+		ctx := context.WithValue(req.Context(), listenerKey{}, "mcp")
+		closed := make(chan struct{})
+		if draining {
+			close(closed)
+		}
+		ctx = context.WithValue(ctx, drainingKey{}, (<-chan struct{})(closed))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req.WithContext(ctx))
+		if rec.Body.Len() == 0 {
+			return rec, nil
+		}
+
+		var report HealthReport
+		Expect(json.Unmarshal(rec.Body.Bytes(), &report)).To(Succeed())
+		return rec, &report
+	}
+	get := func(handler http.HandlerFunc, draining bool) (*httptest.ResponseRecorder, *HealthReport) {
+		return getWithToken(handler, draining, "")
+	}
+
+	It("should be ready without backends", func() {
+		rec, report := get(server.handleReadyz, false)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(report.Gateway).To(Equal("default/gw"))
+		Expect(report.Listener).To(Equal("mcp"))
+		Expect(report.State).To(Equal(HealthStateReady))
+		Expect(report.Backends).To(BeEmpty())
+	})
+
+	It("should report the health of each backend", func() {
+		register("weather")
+		calculator := register("calculator")
+		Expect(registry.SetServiceHealth(calculator, errors.New("connection refused"))).To(BeTrue())
+
+		rec, report := get(server.handleReadyz, false)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(report.State).To(Equal(HealthStateDegraded))
+		Expect(report.Message).To(Equal("1 of 2 backends are unhealthy"))
+		Expect(report.Backends).To(HaveLen(2))
+		Expect(report.Backends[0].Name).To(Equal("calculator"))
+		Expect(report.Backends[0].Status).To(Equal(string(ServiceStatusUnavailable)))
+		Expect(report.Backends[0].Message).To(Equal("connection refused"))
+		Expect(report.Backends[0].LastProbe).NotTo(BeNil())
+		Expect(report.Backends[1].Name).To(Equal("weather"))
+		Expect(report.Backends[1].Status).To(Equal(string(ServiceStatusAvailable)))
+	})
+
+	It("should not be ready when all backends are unhealthy, but stay live", func() {
+		weather := register("weather")
+		Expect(registry.SetServiceHealth(weather, errors.New("connection refused"))).To(BeTrue())
+
+		rec, report := get(server.handleReadyz, false)
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(report.State).To(Equal(HealthStateUnavailable))
+
+		rec, report = get(server.handleHealthz, false)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(report.State).To(Equal(HealthStateUnavailable))
+	})
+
+	It("should not be ready while draining", func() {
+		register("weather")
+
+		rec, report := get(server.handleReadyz, true)
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(report.State).To(Equal(HealthStateDraining))
+		Expect(report.Backends).To(HaveLen(1))
+	})
+
+	It("should only report the backends to authenticated clients on OAuth2 listeners", func() {
+		issuer := newTestIssuer()
+		defer issuer.Close()
+		gateway := newTestGateway(8080)
+		gateway.Spec.Auth = &fetchfyv1alpha2.GatewayAuth{
+			Type:                 fetchfyv1alpha2.AuthTypeOAuth2,
+			AuthorizationServers: []string{issuer.URL},
+		}
+		server.Configure(gateway, nil)
+		weather := register("weather")
+		Expect(registry.SetServiceHealth(weather, errors.New("connection refused"))).To(BeTrue())
+
+		for _, token := range []string{"", "not-a-token"} {
+			rec, report := getWithToken(server.handleReadyz, false, token)
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(report).To(BeNil())
+
+			rec, report = getWithToken(server.handleHealthz, false, token)
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(report).To(BeNil())
+		}
+
+		rec, report := getWithToken(server.handleReadyz, false, issuer.validToken())
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(report.Backends).To(HaveLen(1))
+		Expect(report.Backends[0].Message).To(Equal("connection refused"))
+	})
+})
@@ -0,0 +1,277 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/metrics"
+)
+
+// BackendURLFunc returns the base URL MCP requests for a backend are forwarded to
+type BackendURLFunc func(backend Backend) *url.URL
+
+// ClusterBackendURL returns the URL of the backend's host, by default the cluster DNS name of its Service
+func ClusterBackendURL(backend Backend) *url.URL {
+	scheme, host := backend.Scheme, backend.Host
+	if scheme == "" {
+		scheme = "http"
+	}
+	if host == "" {
+		host = fmt.Sprintf("%s.%s.svc", backend.Service.Name, backend.Service.Namespace)
+	}
+	return &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(int(backend.Port)))}
+}
+
+const (
+	// BackendResultSuccess means the backend answered with a JSON-RPC result
+	BackendResultSuccess = "success"
+
+	// BackendResultError means the backend answered with a JSON-RPC error
+	BackendResultError = "error"
+
+	// BackendResultToolError means the backend answered with a tool result that has isError set
+	BackendResultToolError = "tool_error"
+
+	// BackendResultUnknown means the response isn't a JSON-RPC response the gateway could inspect,
+	// such as notification acknowledgements, SSE streams and large bodies
+	BackendResultUnknown = "unknown"
+)
+
+// forwardHooks are the optional steps applied to the response of a forwarded request
+type forwardHooks struct {
+	// mirrored receives the outcome of the response, if the request was mirrored
+	mirrored *mirroredRequest
+
+	// cached is set if the response is cached on success
+	cached *cachedRequest
+
+	// pinSessions is set if the backend was picked from a split; the session IDs it assigns
+	// are passed to the client with the backend pinned
+	pinSessions bool
+}
+
+// serviceBackend returns the backend of a registered service, its resolved target
+func serviceBackend(svc *MCPService) (Backend, bool) {
+	if svc.Service == nil || svc.Target.Port == 0 {
+		return Backend{}, false
+	}
+	return Backend{
+		Service: types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace},
+		Port:    svc.Target.Port,
+		Weight:  1,
+		Scheme:  svc.Target.Scheme,
+		Host:    svc.Target.Host,
+	}, true
+}
+
+// forward proxies the request to the backend. Streaming responses are flushed as they
+// arrive. SSE streams opened by the client end with a drainingNotice when the listener
+// serving the request starts draining; other requests may complete. The response is
+// recorded in the backend metrics under the route, empty for requests routed by service
+// endpoint.
+func (s *Server) forward(w http.ResponseWriter, r *http.Request, route string, backend Backend, hooks forwardHooks) {
+	s.mutex.Lock()
+	target := s.backendURL(backend)
+	gateway := s.gatewayRef.String()
+	s.mutex.Unlock()
+
+	var drained *atomic.Bool
+	if draining := drainingFrom(r.Context()); draining != nil && isStreamRequest(r) {
+		drained = &atomic.Bool{}
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		go func() {
+			select {
+			case <-draining:
+				drained.Store(true)
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		r = r.WithContext(ctx)
+	}
+
+	start := time.Now()
+	sessionID := r.Header.Get(SessionIDHeader)
+	code := http.StatusBadGateway
+	var captured *capturingBody
+	var contentType, assignedSessionID string
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			removeClientCredentials(pr.Out.Header)
+		},
+		FlushInterval: -1,
+		ModifyResponse: func(resp *http.Response) error {
+			code = resp.StatusCode
+			assignedSessionID = resp.Header.Get(SessionIDHeader)
+			if hooks.pinSessions && assignedSessionID != "" {
+				resp.Header.Set(SessionIDHeader, pinSession(assignedSessionID, backend))
+			}
+			s.watchNotifications(resp, backend)
+			if drained != nil && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+				resp.Body = &drainingStream{ReadCloser: resp.Body, drained: drained}
+			}
+			// Responses are inspected for the backend metrics, the cache and the mirror, except
+			// for the SSE streams clients open
+			if !isStreamRequest(r) {
+				contentType = resp.Header.Get("Content-Type")
+				captured = &capturingBody{ReadCloser: resp.Body}
+				resp.Body = captured
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if r.Context().Err() != nil {
+				// The client went away or the listener is draining, there is no response to record
+				code = 0
+				return
+			}
+			s.log.Error(err, "Failed to forward MCP request", "path", r.URL.Path, "backend", backend.Service.String())
+			http.Error(w, "Bad gateway", http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+	latency := time.Since(start)
+
+	if hooks.cached != nil && code == http.StatusOK && captured != nil && !captured.truncated {
+		s.storeCached(hooks.cached, contentType, captured.buf.Bytes())
+	}
+	if mirrored := hooks.mirrored; mirrored != nil {
+		outcome := responseOutcome{status: code, latency: latency}
+		switch {
+		case code == 0:
+			outcome.err = r.Context().Err()
+		case captured != nil && !captured.truncated:
+			outcome.hash = resultHash(contentType, captured.buf.Bytes())
+		}
+		if assignedSessionID != sessionID {
+			outcome.sessionID = assignedSessionID
+		}
+		mirrored.done(outcome)
+	}
+
+	if code == 0 {
+		return
+	}
+	result := BackendResultUnknown
+	if captured != nil && !captured.truncated {
+		result = backendResult(contentType, captured.buf.Bytes())
+	}
+	backendName := backend.Service.String()
+	metrics.BackendRequestCount.WithLabelValues(gateway, route, backendName, strconv.Itoa(code), result).Inc()
+	if !isStreamRequest(r) {
+		metrics.BackendRequestDuration.WithLabelValues(gateway, route, backendName).
+			Observe(latency.Seconds())
+	}
+}
+
+// jsonRPCResponse is the part of a JSON-RPC response the gateway inspects
+type jsonRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// responseMessage returns the JSON-RPC response in a response body, which is either
+// a JSON body or an SSE stream of JSON-RPC messages. It returns false if the body
+// doesn't contain a response with a result or error.
+func responseMessage(contentType string, body []byte) (jsonRPCResponse, bool) {
+	payloads := [][]byte{body}
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		payloads = nil
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 0, 64*1024), maxInspectedBody)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data:"); ok {
+				payloads = append(payloads, []byte(strings.TrimSpace(data)))
+			}
+		}
+	}
+
+	var found jsonRPCResponse
+	ok := false
+	for _, payload := range payloads {
+		var resp jsonRPCResponse
+		if json.Unmarshal(payload, &resp) == nil && (resp.Result != nil || resp.Error != nil) {
+			found, ok = resp, true
+		}
+	}
+	return found, ok
+}
+
+// backendResult classifies the JSON-RPC response in a response body for the backend metrics
+func backendResult(contentType string, body []byte) string {
+	resp, ok := responseMessage(contentType, body)
+	switch {
+	case !ok:
+		return BackendResultUnknown
+	case resp.Error != nil:
+		return BackendResultError
+	}
+	var result struct {
+		IsError bool `json:"isError"`
+	}
+	if json.Unmarshal(resp.Result, &result) == nil && result.IsError {
+		return BackendResultToolError
+	}
+	return BackendResultSuccess
+}
+
+// capturingBody keeps a copy of a response body of up to maxInspectedBody bytes while it is read
+type capturingBody struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	truncated bool
+}
+
+// Read reads from the body and keeps a copy of the data
+func (b *capturingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.truncated {
+		if b.buf.Len()+n > maxInspectedBody {
+			b.truncated = true
+			b.buf.Reset()
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	return n, err
+}
+
+// removeClientCredentials removes the credentials the client presented to the gateway from
+// a request to a backend. The gateway's tokens are meant for the gateway, so backends must
+// not receive them and can't replay them.
+func removeClientCredentials(header http.Header) {
+	header.Del("Authorization")
+}
@@ -20,6 +20,8 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,8 +29,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
-	fetchfyv1alpha1 "github.com/fetchfy/fetchfy-operator/api/v1alpha1"
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
 )
 
 // ServiceType represents the type of MCP service
@@ -54,8 +57,50 @@ const (
 
 	// ServiceStatusUnavailable means the service is registered but cannot be reached
 	ServiceStatusUnavailable ServiceStatus = "Unavailable"
+
+	// ServiceStatusConflicted means the service's endpoint overlaps the endpoint of an older
+	// service and is not routed
+	ServiceStatusConflicted ServiceStatus = "Conflicted"
+
+	// ServiceStatusInvalid means the service's port, scheme or base path can't be resolved
+	// and it is not routed
+	ServiceStatusInvalid ServiceStatus = "Invalid"
+)
+
+const (
+	// EventReasonEndpointConflict is the reason of the Events recorded on Services whose endpoints overlap
+	EventReasonEndpointConflict = "EndpointConflict"
+
+	// EventReasonEndpointConflictResolved is the reason of the Event recorded when a conflict is resolved
+	EventReasonEndpointConflictResolved = "EndpointConflictResolved"
+
+	// EventReasonInvalidTarget is the reason of the Event recorded on Services that can't be routed to
+	EventReasonInvalidTarget = "InvalidMCPTarget"
 )
 
+// EndpointAnnotation is the annotation that overrides the endpoint path of an MCP service
+const EndpointAnnotation = "mcp.fetchfy.ai/endpoint"
+
+// ServiceEndpoint returns the endpoint path of a service, taken from the endpoint
+// annotation or derived from the service's namespace and name
+func ServiceEndpoint(svc *corev1.Service) string {
+	if endpoint, ok := svc.Annotations[EndpointAnnotation]; ok {
+		return endpoint
+	}
+	return fmt.Sprintf("/mcp/%s/%s", svc.Namespace, svc.Name)
+}
+
+// EndpointsOverlap reports whether two endpoint paths are equal or one is a path prefix
+// of the other, in which case they can't be routed unambiguously
+func EndpointsOverlap(a, b string) bool {
+	a = strings.TrimSuffix(a, "/")
+	b = strings.TrimSuffix(b, "/")
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return a == b || strings.HasPrefix(b, a+"/")
+}
+
 // MCPService represents an MCP service registered with the gateway
 type MCPService struct {
 	Name      string
@@ -65,26 +110,180 @@ type MCPService struct {
 	Status    ServiceStatus
 	Service   *corev1.Service
 	UpdatedAt time.Time
+
+	// Message explains the current status, e.g. the last health probe error
+	Message string
+
+	// LastProbe is the time of the last health probe, zero if never probed
+	LastProbe time.Time
+
+	// ConflictsWith is the older service that owns an overlapping endpoint, set when the status is Conflicted
+	ConflictsWith *types.NamespacedName
+
+	// Stale is set for services restored from a snapshot until their Service is registered again
+	Stale bool
+
+	// Endpoints counts the endpoints of the service, nil if they aren't tracked
+	Endpoints *Endpoints
+
+	// Target is where MCP requests for the service are sent, unset when the status is Invalid
+	Target ServiceTarget
+
+	// Source is the kind of object the service was discovered from. Services discovered from
+	// Pods and Deployments are represented by a Service object named after WorkloadName.
+	Source fetchfyv1alpha2.DiscoverySource
+
+	// Cluster is the remote cluster the service runs in, nil for services of the local cluster
+	Cluster *RemoteCluster
+}
+
+// WorkloadName returns the registry name of an MCP server discovered from a Pod or Deployment.
+// Object names can't contain slashes, so it never collides with the name of a Service.
+func WorkloadName(source fetchfyv1alpha2.DiscoverySource, name string) string {
+	return strings.ToLower(string(source)) + "/" + name
+}
+
+// discoverySource returns the kind of object the service was discovered from
+func (svc *MCPService) discoverySource() fetchfyv1alpha2.DiscoverySource {
+	if svc.Source == "" {
+		return fetchfyv1alpha2.DiscoverySourceService
+	}
+	return svc.Source
+}
+
+// isWorkload reports whether the service was discovered from a Pod or Deployment
+func (svc *MCPService) isWorkload() bool {
+	return svc.discoverySource() != fetchfyv1alpha2.DiscoverySourceService
+}
+
+// clusterName returns the name of the remote cluster the service runs in, empty for local services
+func (svc *MCPService) clusterName() string {
+	if svc.Cluster == nil {
+		return ""
+	}
+	return svc.Cluster.Name
+}
+
+// Endpoints counts the endpoints of a service
+type Endpoints struct {
+	// Ready is the number of endpoints ready to serve requests
+	Ready int32 `json:"ready"`
+
+	// Total is the number of endpoints that aren't terminating, ready or not
+	Total int32 `json:"total"`
+
+	// Port is the endpoint port of the MCP port, which resolves named target ports of headless Services
+	Port int32 `json:"port,omitempty"`
+}
+
+// serviceStatus returns the target and the status of a service before it is probed, from its
+// Service, source, cluster and endpoints. Services whose target can't be resolved are invalid,
+// and services without ready endpoints are pending. ExternalName Services have no endpoints.
+// Workloads are represented by ExternalName Services of a ready pod's IP, which have no target
+// until a pod is ready. Services of remote clusters are reached through the cluster's address.
+func serviceStatus(mcpService *MCPService) (ServiceTarget, ServiceStatus, string) {
+	svc, endpoints := mcpService.Service, mcpService.Endpoints
+	workload := mcpService.isWorkload()
+	if workload && endpoints != nil && endpoints.Ready == 0 {
+		if endpoints.Total == 0 {
+			return ServiceTarget{}, ServiceStatusPending, "No pods are running"
+		}
+		return ServiceTarget{}, ServiceStatusPending, fmt.Sprintf("0 of %d pods are ready", endpoints.Total)
+	}
+
+	target, err := ResolveTarget(svc, endpoints)
+	if err == nil && mcpService.Cluster != nil {
+		target, err = RemoteTarget(mcpService.Cluster, svc)
+	}
+	if err != nil {
+		return ServiceTarget{}, ServiceStatusInvalid, err.Error()
+	}
+	if !workload && svc.Spec.Type != corev1.ServiceTypeExternalName && endpoints != nil && endpoints.Ready == 0 {
+		if endpoints.Total == 0 {
+			return target, ServiceStatusPending, "Service has no endpoints"
+		}
+		return target, ServiceStatusPending, fmt.Sprintf("0 of %d endpoints are ready", endpoints.Total)
+	}
+	return target, ServiceStatusAvailable, ""
 }
 
 // Registry maintains a registry of MCP services
 type Registry struct {
 	services map[types.NamespacedName]*MCPService
+	routes   map[types.NamespacedName]*Route
 	mutex    sync.RWMutex
 	log      logr.Logger
+	recorder record.EventRecorder
+
+	listChanged    map[int]func(ListChange)
+	nextListener   int
+	pendingChanges []ListChange
+
+	watchers      map[*registryWatcher]struct{}
+	pendingEvents []ServiceEvent
 }
 
 // NewRegistry creates a new MCP service registry
 func NewRegistry(log logr.Logger) *Registry {
 	return &Registry{
 		services: make(map[types.NamespacedName]*MCPService),
+		routes:   make(map[types.NamespacedName]*Route),
 		log:      log.WithName("mcp-registry"),
+
+		listChanged: make(map[int]func(ListChange)),
+		watchers:    make(map[*registryWatcher]struct{}),
 	}
 }
 
-// RegisterService adds or updates a service in the registry
+// SetEventRecorder sets the recorder used to emit Events on Services with conflicting endpoints
+func (r *Registry) SetEventRecorder(recorder record.EventRecorder) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.recorder = recorder
+}
+
+// RegisterService adds or updates a service in the registry. The endpoint counts of a service
+// registered before are kept.
 func (r *Registry) RegisterService(ctx context.Context, svc *corev1.Service, serviceType ServiceType) (*MCPService, error) {
+	return r.registerService(fetchfyv1alpha2.DiscoverySourceService, nil, svc, serviceType, nil)
+}
+
+// RegisterServiceWithEndpoints adds or updates a service in the registry together with the
+// counts of its endpoints, which decide whether it is pending
+func (r *Registry) RegisterServiceWithEndpoints(
+	ctx context.Context,
+	svc *corev1.Service,
+	serviceType ServiceType,
+	endpoints Endpoints,
+) (*MCPService, error) {
+	return r.registerService(fetchfyv1alpha2.DiscoverySourceService, nil, svc, serviceType, &endpoints)
+}
+
+// RegisterWorkload adds or updates an MCP server discovered from a Pod or Deployment. The
+// workload is represented by an ExternalName Service named after WorkloadName, whose external
+// name is the IP of a ready pod; the endpoints count the workload's pods.
+func (r *Registry) RegisterWorkload(
+	ctx context.Context,
+	source fetchfyv1alpha2.DiscoverySource,
+	svc *corev1.Service,
+	serviceType ServiceType,
+	endpoints Endpoints,
+) (*MCPService, error) {
+	return r.registerService(source, nil, svc, serviceType, &endpoints)
+}
+
+// registerService adds or updates a service of a source, and of a remote cluster if cluster is
+// set. Nil endpoints keep the counts of the registered service.
+func (r *Registry) registerService(
+	source fetchfyv1alpha2.DiscoverySource,
+	cluster *RemoteCluster,
+	svc *corev1.Service,
+	serviceType ServiceType,
+	endpoints *Endpoints,
+) (*MCPService, error) {
 	r.mutex.Lock()
+	defer r.deliver()
 	defer r.mutex.Unlock()
 
 	key := types.NamespacedName{
@@ -97,48 +296,281 @@ func (r *Registry) RegisterService(ctx context.Context, svc *corev1.Service, ser
 		return nil, fmt.Errorf("service %s/%s has no annotations", svc.Namespace, svc.Name)
 	}
 
-	// Extract endpoint from annotations or generate one
-	endpoint, ok := svc.Annotations["mcp.fetchfy.ai/endpoint"]
-	if !ok {
-		// Generate default endpoint based on service name
-		endpoint = fmt.Sprintf("/mcp/%s/%s", svc.Namespace, svc.Name)
+	// Extract endpoint from annotations or generate one. Services of remote clusters always
+	// get the default endpoint of their qualified name, so that clusters can't collide.
+	endpoint := ServiceEndpoint(svc)
+	if cluster != nil {
+		key.Name = ClusterServiceName(cluster.Name, svc.Name)
+		endpoint = fmt.Sprintf("/mcp/%s/%s", key.Namespace, key.Name)
 	}
 
-	status := ServiceStatusPending
-	if svc.Spec.Type == corev1.ServiceTypeClusterIP && len(svc.Spec.Ports) > 0 {
-		status = ServiceStatusAvailable
+	existing, exists := r.services[key]
+	switch {
+	case source == fetchfyv1alpha2.DiscoverySourceService && svc.Spec.Type == corev1.ServiceTypeExternalName:
+		// ExternalName Services have no endpoints
+		endpoints = nil
+	case exists && endpoints == nil:
+		endpoints = existing.Endpoints
 	}
 
 	mcpService := &MCPService{
-		Name:      svc.Name,
-		Namespace: svc.Namespace,
+		Name:      key.Name,
+		Namespace: key.Namespace,
 		Type:      serviceType,
 		Endpoint:  endpoint,
-		Status:    status,
 		Service:   svc.DeepCopy(),
 		UpdatedAt: time.Now(),
+		Endpoints: endpoints,
+		Source:    source,
+		Cluster:   cluster,
+	}
+	target, status, message := serviceStatus(mcpService)
+	mcpService.Target, mcpService.Status, mcpService.Message = target, status, message
+
+	if exists {
+		switch {
+		case existing.ConflictsWith != nil && existing.Endpoint == endpoint:
+			// Keep the conflict so that only changes to it are reported
+			mcpService.Status = existing.Status
+			mcpService.Message = existing.Message
+			mcpService.ConflictsWith = existing.ConflictsWith
+		case status == ServiceStatusAvailable && !existing.LastProbe.IsZero() &&
+			(existing.Status == ServiceStatusAvailable || existing.Status == ServiceStatusUnavailable):
+			// Keep the last probe result so re-registration doesn't mask an unhealthy backend
+			mcpService.Status = existing.Status
+			mcpService.Message = existing.Message
+			mcpService.LastProbe = existing.LastProbe
+		}
+	}
+
+	// Keep the time of the last change when the Service is registered again unchanged
+	if exists && !serviceChanged(existing, mcpService) {
+		mcpService.UpdatedAt = existing.UpdatedAt
 	}
 
+	if status == ServiceStatusInvalid && (!exists || existing.Message != message) {
+		r.recordInvalidTarget(mcpService)
+	}
+
+	before := r.snapshot()
 	r.services[key] = mcpService
-	r.log.Info("Registered MCP service", "name", svc.Name, "namespace", svc.Namespace, "type", serviceType)
+	r.log.Info("Registered MCP service", "name", key.Name, "namespace", key.Namespace, "type", serviceType)
 
-	return mcpService, nil
+	r.resolveConflicts()
+	r.recordChanges(before)
+
+	return r.services[key], nil
 }
 
 // DeregisterService removes a service from the registry
 func (r *Registry) DeregisterService(ctx context.Context, name types.NamespacedName) bool {
 	r.mutex.Lock()
+	defer r.deliver()
 	defer r.mutex.Unlock()
 
 	if _, exists := r.services[name]; exists {
+		before := r.snapshot()
 		delete(r.services, name)
 		r.log.Info("Deregistered MCP service", "name", name.Name, "namespace", name.Namespace)
+		r.resolveConflicts()
+		r.recordChanges(before)
 		return true
 	}
 
 	return false
 }
 
+// SetServiceHealth records the result of a health probe for a registered service.
+// It returns true if the service status changed.
+func (r *Registry) SetServiceHealth(name types.NamespacedName, probeErr error) bool {
+	r.mutex.Lock()
+	defer r.deliver()
+	defer r.mutex.Unlock()
+
+	svc, exists := r.services[name]
+	// Conflicted and invalid services are not routed and services without ready endpoints have
+	// nothing to probe, so their health doesn't change their status
+	if !exists || svc.Status == ServiceStatusConflicted || svc.Status == ServiceStatusInvalid ||
+		(svc.Endpoints != nil && svc.Endpoints.Ready == 0) {
+		return false
+	}
+
+	status := ServiceStatusAvailable
+	message := ""
+	if probeErr != nil {
+		status = ServiceStatusUnavailable
+		message = probeErr.Error()
+	}
+
+	changed := svc.Status != status
+	// Replace rather than mutate so readers holding the old pointer see a consistent value
+	updated := *svc
+	updated.Status = status
+	updated.Message = message
+	updated.LastProbe = time.Now()
+	if changed {
+		updated.UpdatedAt = updated.LastProbe
+		r.log.Info("MCP service health changed", "name", name.Name, "namespace", name.Namespace,
+			"status", status, "message", message)
+	}
+	before := r.snapshot()
+	r.services[name] = &updated
+	r.recordChanges(before)
+
+	return changed
+}
+
+// SetServiceEndpoints records the endpoint counts of a registered service. A service without
+// ready endpoints is pending; once endpoints are ready, the health probes decide whether it
+// is available. It returns true if the service status changed.
+func (r *Registry) SetServiceEndpoints(name types.NamespacedName, endpoints Endpoints) bool {
+	r.mutex.Lock()
+	defer r.deliver()
+	defer r.mutex.Unlock()
+
+	svc, exists := r.services[name]
+	if !exists || (svc.Endpoints != nil && *svc.Endpoints == endpoints) {
+		return false
+	}
+
+	updated := *svc
+	updated.Endpoints = &endpoints
+	target, status, message := serviceStatus(&updated)
+	updated.Target = target
+	switch {
+	case svc.Status == ServiceStatusConflicted:
+		// Conflicted services are not routed, so their endpoints don't change their status
+	case status == ServiceStatusAvailable && svc.Status == ServiceStatusUnavailable:
+		// Keep the last probe result until the next probe
+	default:
+		updated.Status = status
+		updated.Message = message
+	}
+
+	changed := svc.Status != updated.Status
+	if changed {
+		updated.UpdatedAt = time.Now()
+		r.log.Info("MCP service endpoints changed", "name", name.Name, "namespace", name.Namespace,
+			"status", updated.Status, "ready", endpoints.Ready, "total", endpoints.Total)
+	}
+	before := r.snapshot()
+	r.services[name] = &updated
+	r.recordChanges(before)
+
+	return changed
+}
+
+// snapshot returns a copy of the registered services. It must be called with the registry lock held.
+func (r *Registry) snapshot() map[types.NamespacedName]*MCPService {
+	services := make(map[types.NamespacedName]*MCPService, len(r.services))
+	for name, svc := range r.services {
+		services[name] = svc
+	}
+	return services
+}
+
+// resolveConflicts marks every service whose endpoint overlaps the endpoint of an older
+// service as Conflicted, and restores services whose conflict is gone. The oldest service
+// wins; services created at the same time are ordered by namespace and name.
+// It must be called with the registry lock held.
+func (r *Registry) resolveConflicts() {
+	ordered := make([]*MCPService, 0, len(r.services))
+	for _, svc := range r.services {
+		ordered = append(ordered, svc)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		ti, tj := ordered[i].Service.CreationTimestamp, ordered[j].Service.CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		if ordered[i].Namespace != ordered[j].Namespace {
+			return ordered[i].Namespace < ordered[j].Namespace
+		}
+		return ordered[i].Name < ordered[j].Name
+	})
+
+	var winners []*MCPService
+	for _, svc := range ordered {
+		var winner *MCPService
+		for _, candidate := range winners {
+			if EndpointsOverlap(svc.Endpoint, candidate.Endpoint) {
+				winner = candidate
+				break
+			}
+		}
+
+		key := types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}
+		switch {
+		case winner != nil:
+			winnerKey := types.NamespacedName{Name: winner.Name, Namespace: winner.Namespace}
+			if svc.ConflictsWith != nil && *svc.ConflictsWith == winnerKey {
+				continue
+			}
+			r.services[key] = r.markConflicted(svc, winner)
+		case svc.ConflictsWith != nil:
+			r.services[key] = r.clearConflict(svc)
+			winners = append(winners, r.services[key])
+		default:
+			winners = append(winners, svc)
+		}
+	}
+}
+
+// markConflicted returns a copy of svc marked as conflicting with winner and records Events on both Services
+func (r *Registry) markConflicted(svc, winner *MCPService) *MCPService {
+	winnerKey := types.NamespacedName{Name: winner.Name, Namespace: winner.Namespace}
+
+	updated := *svc
+	updated.Status = ServiceStatusConflicted
+	updated.Message = fmt.Sprintf("endpoint %s overlaps endpoint %s of Service %s, which is older",
+		svc.Endpoint, winner.Endpoint, winnerKey)
+	updated.ConflictsWith = &winnerKey
+	updated.UpdatedAt = time.Now()
+
+	r.log.Info("MCP service endpoint conflict", "name", svc.Name, "namespace", svc.Namespace,
+		"endpoint", svc.Endpoint, "winner", winnerKey)
+	r.eventf(svc, corev1.EventTypeWarning, EventReasonEndpointConflict,
+		"Endpoint %s is not routed because it overlaps endpoint %s of the older Service %s; "+
+			"set a unique %s annotation", svc.Endpoint, winner.Endpoint, winnerKey, EndpointAnnotation)
+	r.eventf(winner, corev1.EventTypeWarning, EventReasonEndpointConflict,
+		"Endpoint %s of Service %s/%s overlaps this Service's endpoint %s; this Service keeps the endpoint "+
+			"because it is older", svc.Endpoint, svc.Namespace, svc.Name, winner.Endpoint)
+
+	return &updated
+}
+
+// clearConflict returns a copy of svc with its conflict removed and records an Event on the Service
+func (r *Registry) clearConflict(svc *MCPService) *MCPService {
+	updated := *svc
+	updated.Target, updated.Status, updated.Message = serviceStatus(&updated)
+	updated.ConflictsWith = nil
+	updated.LastProbe = time.Time{}
+	updated.UpdatedAt = time.Now()
+
+	r.log.Info("MCP service endpoint conflict resolved", "name", svc.Name, "namespace", svc.Namespace,
+		"endpoint", svc.Endpoint)
+	r.eventf(svc, corev1.EventTypeNormal, EventReasonEndpointConflictResolved,
+		"Endpoint %s no longer overlaps another Service and is routed again", svc.Endpoint)
+
+	return &updated
+}
+
+// recordInvalidTarget records an Event on a Service whose target can't be resolved
+func (r *Registry) recordInvalidTarget(svc *MCPService) {
+	r.log.Info("MCP service can't be routed to", "name", svc.Name, "namespace", svc.Namespace,
+		"message", svc.Message)
+	r.eventf(svc, corev1.EventTypeWarning, EventReasonInvalidTarget, "Service is not routed: %s", svc.Message)
+}
+
+// eventf records an Event on the Service of a registered service if a recorder is set. Services
+// discovered from workloads or running in remote clusters have no local Service to record Events on.
+func (r *Registry) eventf(svc *MCPService, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.recorder == nil || svc.isWorkload() || svc.Cluster != nil {
+		return
+	}
+	r.recorder.Eventf(svc.Service, eventType, reason, messageFmt, args...)
+}
+
 // GetService returns a service from the registry
 func (r *Registry) GetService(name types.NamespacedName) (*MCPService, bool) {
 	r.mutex.RLock()
@@ -148,6 +580,23 @@ func (r *Registry) GetService(name types.NamespacedName) (*MCPService, bool) {
 	return svc, exists
 }
 
+// ServiceForPath returns the routed service whose endpoint serves the request path
+func (r *Registry) ServiceForPath(path string) (*MCPService, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, svc := range r.services {
+		if svc.Status == ServiceStatusConflicted {
+			continue
+		}
+		endpoint := strings.TrimSuffix(svc.Endpoint, "/")
+		if path == endpoint || strings.HasPrefix(path, endpoint+"/") {
+			return svc, true
+		}
+	}
+	return nil, false
+}
+
 // ListServices returns all services in the registry
 func (r *Registry) ListServices() []*MCPService {
 	r.mutex.RLock()
@@ -161,22 +610,81 @@ func (r *Registry) ListServices() []*MCPService {
 	return services
 }
 
-// UpdateRegistryStatus updates the Gateway's status with current services
-func (r *Registry) UpdateRegistryStatus(gateway *fetchfyv1alpha1.Gateway) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+// UpdateRegistryStatus updates the Gateway's status with the current services discovered from the
+// gateway's discovery settings and federated clusters. Local services are discovered in the given
+// namespaces, nil standing for all namespaces. The services are
+// listed in the order of their namespace and name, capped at the gateway's status service limit,
+// and counted by type. It returns the full list of services, e.g. for an MCPServiceCatalog.
+func (r *Registry) UpdateRegistryStatus(
+	gateway *fetchfyv1alpha2.Gateway,
+	namespaces []string,
+) []fetchfyv1alpha2.MCPServiceInfo {
+	// Without listener restrictions, the filter only applies the gateway's discovery settings and clusters
+	filter, err := newServiceFilter(nil, &gateway.Spec, namespaces)
 
-	mcpServices := make([]fetchfyv1alpha1.MCPServiceInfo, 0, len(r.services))
+	r.mutex.RLock()
+	mcpServices := make([]fetchfyv1alpha2.MCPServiceInfo, 0, len(r.services))
 	for _, svc := range r.services {
-		mcpServices = append(mcpServices, fetchfyv1alpha1.MCPServiceInfo{
+		// An invalid service selector discovers no services
+		if err != nil || !filter.matches(svc) {
+			continue
+		}
+		// Stored objects keep whole seconds, so finer times would make every status write a change
+		info := fetchfyv1alpha2.MCPServiceInfo{
 			Name:        svc.Name,
 			Namespace:   svc.Namespace,
 			Type:        string(svc.Type),
 			Endpoint:    svc.Endpoint,
 			Status:      string(svc.Status),
-			LastUpdated: metav1.NewTime(svc.UpdatedAt),
-		})
+			LastUpdated: metav1.NewTime(svc.UpdatedAt.Truncate(time.Second)),
+			Message:     svc.Message,
+			Cluster:     svc.clusterName(),
+		}
+		if svc.Endpoints != nil {
+			ready := svc.Endpoints.Ready
+			info.ReadyEndpoints = &ready
+		}
+		mcpServices = append(mcpServices, info)
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(mcpServices, func(i, j int) bool {
+		if mcpServices[i].Namespace != mcpServices[j].Namespace {
+			return mcpServices[i].Namespace < mcpServices[j].Namespace
+		}
+		return mcpServices[i].Name < mcpServices[j].Name
+	})
+
+	byType := make(map[string]*fetchfyv1alpha2.ServiceTypeSummary)
+	var available int32
+	for _, svc := range mcpServices {
+		summary, ok := byType[svc.Type]
+		if !ok {
+			summary = &fetchfyv1alpha2.ServiceTypeSummary{Type: svc.Type}
+			byType[svc.Type] = summary
+		}
+		summary.Total++
+		if svc.Status == string(ServiceStatusAvailable) {
+			summary.Available++
+			available++
+		}
+	}
+
+	gateway.Status.ServicesTotal = int32(len(mcpServices))
+	gateway.Status.ServicesAvailable = available
+	gateway.Status.ServicesByType = make([]fetchfyv1alpha2.ServiceTypeSummary, 0, len(byType))
+	for _, summary := range byType {
+		gateway.Status.ServicesByType = append(gateway.Status.ServicesByType, *summary)
+	}
+	sort.Slice(gateway.Status.ServicesByType, func(i, j int) bool {
+		return gateway.Status.ServicesByType[i].Type < gateway.Status.ServicesByType[j].Type
+	})
+
+	listed := mcpServices
+	if limit := gateway.Spec.StatusServiceLimit(); len(listed) > limit {
+		listed = listed[:limit]
 	}
+	gateway.Status.MCPServices = append([]fetchfyv1alpha2.MCPServiceInfo(nil), listed...)
 
-	gateway.Status.MCPServices = mcpServices
+	return mcpServices
 }
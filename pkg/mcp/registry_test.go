@@ -0,0 +1,381 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+var _ = Describe("Registry", func() {
+	Describe("EndpointsOverlap", func() {
+		DescribeTable("should detect duplicate and nested endpoints",
+			func(a, b string, overlap bool) {
+				Expect(EndpointsOverlap(a, b)).To(Equal(overlap))
+				Expect(EndpointsOverlap(b, a)).To(Equal(overlap))
+			},
+			Entry("equal", "/mcp/tools/example", "/mcp/tools/example", true),
+			Entry("trailing slash", "/mcp/tools/example/", "/mcp/tools/example", true),
+			Entry("nested", "/mcp/tools", "/mcp/tools/example", true),
+			Entry("siblings", "/mcp/tools/example", "/mcp/tools/example-2", false),
+			Entry("distinct", "/mcp/tools/a", "/mcp/agents/a", false),
+		)
+	})
+
+	Describe("endpoint conflicts", func() {
+		var (
+			registry *Registry
+			recorder *record.FakeRecorder
+			now      = time.Now()
+		)
+
+		newService := func(name string, created time.Time, endpoint string) *corev1.Service {
+			return &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         "tools",
+					CreationTimestamp: metav1.NewTime(created),
+					Annotations:       map[string]string{EndpointAnnotation: endpoint},
+				},
+				Spec: corev1.ServiceSpec{
+					Type:  corev1.ServiceTypeClusterIP,
+					Ports: []corev1.ServicePort{{Port: 80}},
+				},
+			}
+		}
+
+		register := func(svc *corev1.Service) *MCPService {
+			mcpSvc, err := registry.RegisterService(context.Background(), svc, ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+			return mcpSvc
+		}
+
+		status := func(name string) ServiceStatus {
+			mcpSvc, ok := registry.GetService(types.NamespacedName{Name: name, Namespace: "tools"})
+			Expect(ok).To(BeTrue())
+			return mcpSvc.Status
+		}
+
+		BeforeEach(func() {
+			registry = NewRegistry(logr.Discard())
+			recorder = record.NewFakeRecorder(10)
+			registry.SetEventRecorder(recorder)
+		})
+
+		It("should keep the endpoint for the oldest service", func() {
+			register(newService("newer", now, "/mcp/tools/example"))
+			Expect(status("newer")).To(Equal(ServiceStatusAvailable))
+
+			older := register(newService("older", now.Add(-time.Hour), "/mcp/tools/example"))
+			Expect(older.Status).To(Equal(ServiceStatusAvailable))
+
+			newer, _ := registry.GetService(types.NamespacedName{Name: "newer", Namespace: "tools"})
+			Expect(newer.Status).To(Equal(ServiceStatusConflicted))
+			Expect(newer.ConflictsWith).To(Equal(&types.NamespacedName{Name: "older", Namespace: "tools"}))
+			Expect(newer.Message).To(ContainSubstring("tools/older"))
+
+			By("recording an Event on both Services")
+			Expect(recorder.Events).To(Receive(ContainSubstring("not routed because it overlaps")))
+			Expect(recorder.Events).To(Receive(ContainSubstring("this Service keeps the endpoint")))
+		})
+
+		It("should detect overlapping prefixes", func() {
+			register(newService("parent", now.Add(-time.Hour), "/mcp/tools"))
+			register(newService("child", now, "/mcp/tools/example"))
+			Expect(status("child")).To(Equal(ServiceStatusConflicted))
+			Expect(status("parent")).To(Equal(ServiceStatusAvailable))
+		})
+
+		It("should not report a conflict again when the service is re-registered", func() {
+			register(newService("older", now.Add(-time.Hour), "/mcp/tools/example"))
+			register(newService("newer", now, "/mcp/tools/example"))
+			Expect(recorder.Events).To(HaveLen(2))
+
+			register(newService("newer", now, "/mcp/tools/example"))
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(status("newer")).To(Equal(ServiceStatusConflicted))
+		})
+
+		It("should restore the loser when the conflict is gone", func() {
+			register(newService("older", now.Add(-time.Hour), "/mcp/tools/example"))
+			register(newService("newer", now, "/mcp/tools/example"))
+			Expect(status("newer")).To(Equal(ServiceStatusConflicted))
+
+			Expect(registry.DeregisterService(context.Background(),
+				types.NamespacedName{Name: "older", Namespace: "tools"})).To(BeTrue())
+			Expect(status("newer")).To(Equal(ServiceStatusAvailable))
+
+			By("restoring a service whose endpoint was changed")
+			register(newService("older", now.Add(-time.Hour), "/mcp/tools/example"))
+			Expect(status("newer")).To(Equal(ServiceStatusConflicted))
+			register(newService("newer", now, "/mcp/tools/other"))
+			Expect(status("newer")).To(Equal(ServiceStatusAvailable))
+		})
+
+		It("should route a probed service again once it moves out of a conflict", func() {
+			register(newService("a", now.Add(-time.Hour), "/mcp/x"))
+			register(newService("b", now, "/mcp/y"))
+			b := types.NamespacedName{Name: "b", Namespace: "tools"}
+			Expect(registry.SetServiceHealth(b, nil)).To(BeFalse())
+			Expect(status("b")).To(Equal(ServiceStatusAvailable))
+
+			register(newService("b", now, "/mcp/x"))
+			Expect(status("b")).To(Equal(ServiceStatusConflicted))
+
+			moved := register(newService("b", now, "/mcp/z"))
+			Expect(moved.Status).To(Equal(ServiceStatusAvailable))
+			Expect(moved.ConflictsWith).To(BeNil())
+			Expect(status("b")).To(Equal(ServiceStatusAvailable))
+		})
+
+		It("should ignore health probes of conflicted services", func() {
+			register(newService("older", now.Add(-time.Hour), "/mcp/tools/example"))
+			register(newService("newer", now, "/mcp/tools/example"))
+
+			Expect(registry.SetServiceHealth(types.NamespacedName{Name: "newer", Namespace: "tools"}, nil)).To(BeFalse())
+			Expect(status("newer")).To(Equal(ServiceStatusConflicted))
+		})
+	})
+
+	Describe("UpdateRegistryStatus", func() {
+		var registry *Registry
+
+		register := func(namespace, name string, serviceType ServiceType) {
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        name,
+					Namespace:   namespace,
+					Annotations: map[string]string{EndpointAnnotation: fmt.Sprintf("/mcp/%s/%s", namespace, name)},
+				},
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+			}
+			_, err := registry.RegisterService(context.Background(), svc, serviceType)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			registry = NewRegistry(logr.Discard())
+			register("b", "search", ServiceTypeTool)
+			register("a", "weather", ServiceTypeTool)
+			register("a", "planner", ServiceTypeAgent)
+			register("a", "calculator", ServiceTypeTool)
+			registry.SetServiceHealth(types.NamespacedName{Namespace: "b", Name: "search"}, errors.New("refused"))
+		})
+
+		It("should list the services in order and count them by type", func() {
+			gateway := &fetchfyv1alpha2.Gateway{}
+			all := registry.UpdateRegistryStatus(gateway, nil)
+
+			names := []string{}
+			for _, svc := range gateway.Status.MCPServices {
+				names = append(names, svc.Namespace+"/"+svc.Name)
+			}
+			Expect(names).To(Equal([]string{"a/calculator", "a/planner", "a/weather", "b/search"}))
+			Expect(all).To(Equal(gateway.Status.MCPServices))
+
+			Expect(gateway.Status.ServicesTotal).To(Equal(int32(4)))
+			Expect(gateway.Status.ServicesAvailable).To(Equal(int32(3)))
+			Expect(gateway.Status.ServicesByType).To(Equal([]fetchfyv1alpha2.ServiceTypeSummary{
+				{Type: "agent", Total: 1, Available: 1},
+				{Type: "tool", Total: 3, Available: 2},
+			}))
+		})
+
+		It("should cap the listed services but return all of them", func() {
+			limit := int32(2)
+			gateway := &fetchfyv1alpha2.Gateway{Spec: fetchfyv1alpha2.GatewaySpec{
+				Discovery: &fetchfyv1alpha2.GatewayDiscovery{MaxStatusServices: &limit},
+			}}
+			all := registry.UpdateRegistryStatus(gateway, nil)
+
+			Expect(all).To(HaveLen(4))
+			Expect(gateway.Status.MCPServices).To(HaveLen(2))
+			Expect(gateway.Status.MCPServices[1].Name).To(Equal("planner"))
+			Expect(gateway.Status.ServicesTotal).To(Equal(int32(4)))
+		})
+	})
+
+	Describe("endpoint tracking", func() {
+		var registry *Registry
+		name := types.NamespacedName{Name: "weather", Namespace: "tools"}
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "tools", Annotations: map[string]string{}},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}
+
+		status := func() ServiceStatus {
+			mcpSvc, ok := registry.GetService(name)
+			Expect(ok).To(BeTrue())
+			return mcpSvc.Status
+		}
+
+		BeforeEach(func() {
+			registry = NewRegistry(logr.Discard())
+		})
+
+		It("should keep services without ready endpoints pending", func() {
+			_, err := registry.RegisterServiceWithEndpoints(context.Background(), svc, ServiceTypeTool,
+				Endpoints{Ready: 0, Total: 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status()).To(Equal(ServiceStatusPending))
+
+			By("ignoring probes and keeping the counts on re-registration")
+			Expect(registry.SetServiceHealth(name, nil)).To(BeFalse())
+			_, err = registry.RegisterService(context.Background(), svc, ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status()).To(Equal(ServiceStatusPending))
+
+			Expect(registry.SetServiceEndpoints(name, Endpoints{Ready: 1, Total: 2})).To(BeTrue())
+			Expect(status()).To(Equal(ServiceStatusAvailable))
+			Expect(registry.SetServiceEndpoints(name, Endpoints{Ready: 2, Total: 2})).To(BeFalse())
+
+			gateway := &fetchfyv1alpha2.Gateway{}
+			registry.UpdateRegistryStatus(gateway, nil)
+			Expect(*gateway.Status.MCPServices[0].ReadyEndpoints).To(Equal(int32(2)))
+		})
+
+		It("should leave the status of ready services to the probes", func() {
+			_, err := registry.RegisterServiceWithEndpoints(context.Background(), svc, ServiceTypeTool,
+				Endpoints{Ready: 1, Total: 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registry.SetServiceHealth(name, errors.New("refused"))).To(BeTrue())
+
+			Expect(registry.SetServiceEndpoints(name, Endpoints{Ready: 2, Total: 2})).To(BeFalse())
+			Expect(status()).To(Equal(ServiceStatusUnavailable))
+
+			Expect(registry.SetServiceEndpoints(name, Endpoints{Ready: 0, Total: 2})).To(BeTrue())
+			Expect(status()).To(Equal(ServiceStatusPending))
+		})
+	})
+
+	Describe("invalid targets", func() {
+		It("should not route services whose port is ambiguous", func() {
+			registry := NewRegistry(logr.Discard())
+			recorder := record.NewFakeRecorder(10)
+			registry.SetEventRecorder(recorder)
+			name := types.NamespacedName{Name: "weather", Namespace: "tools"}
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "tools", Annotations: map[string]string{}},
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{
+					{Name: "http", Port: 80}, {Name: "metrics", Port: 9090},
+				}},
+			}
+
+			mcpSvc, err := registry.RegisterService(context.Background(), svc, ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mcpSvc.Status).To(Equal(ServiceStatusInvalid))
+			Expect(mcpSvc.Message).To(ContainSubstring("service has 2 ports"))
+			Expect(recorder.Events).To(Receive(ContainSubstring(EventReasonInvalidTarget)))
+			Expect(registry.SetServiceHealth(name, nil)).To(BeFalse())
+			_, ok := serviceBackend(mcpSvc)
+			Expect(ok).To(BeFalse())
+
+			By("routing the service once a port is selected")
+			svc.Annotations[PortAnnotation] = "http"
+			mcpSvc, err = registry.RegisterService(context.Background(), svc, ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mcpSvc.Status).To(Equal(ServiceStatusAvailable))
+			backend, ok := serviceBackend(mcpSvc)
+			Expect(ok).To(BeTrue())
+			Expect(ClusterBackendURL(backend).String()).To(Equal("http://weather.tools.svc:80"))
+		})
+	})
+
+	Describe("workload sources", func() {
+		newWorkload := func(podIP string) *corev1.Service {
+			return &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        WorkloadName(fetchfyv1alpha2.DiscoverySourcePod, "weather"),
+					Namespace:   "tools",
+					Annotations: map[string]string{PortAnnotation: "8080"},
+				},
+				Spec: corev1.ServiceSpec{
+					Type:         corev1.ServiceTypeExternalName,
+					ExternalName: podIP,
+					Ports:        []corev1.ServicePort{{Name: MCPPortName, Port: 8080}},
+				},
+			}
+		}
+
+		It("should keep workloads without ready pods pending and route to the pod", func() {
+			registry := NewRegistry(logr.Discard())
+			recorder := record.NewFakeRecorder(10)
+			registry.SetEventRecorder(recorder)
+
+			mcpSvc, err := registry.RegisterWorkload(context.Background(), fetchfyv1alpha2.DiscoverySourcePod,
+				newWorkload(""), ServiceTypeTool, Endpoints{Ready: 0, Total: 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mcpSvc.Status).To(Equal(ServiceStatusPending))
+			Expect(mcpSvc.Message).To(Equal("0 of 1 pods are ready"))
+			Expect(recorder.Events).To(BeEmpty())
+
+			mcpSvc, err = registry.RegisterWorkload(context.Background(), fetchfyv1alpha2.DiscoverySourcePod,
+				newWorkload("10.0.0.7"), ServiceTypeTool, Endpoints{Ready: 1, Total: 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mcpSvc.Status).To(Equal(ServiceStatusAvailable))
+			Expect(mcpSvc.Endpoints).To(Equal(&Endpoints{Ready: 1, Total: 1}))
+			Expect(mcpSvc.Endpoint).To(Equal("/mcp/tools/pod/weather"))
+			backend, ok := serviceBackend(mcpSvc)
+			Expect(ok).To(BeTrue())
+			Expect(ClusterBackendURL(backend).String()).To(Equal("http://10.0.0.7:8080"))
+		})
+
+		It("should only list services of the gateway's discovery sources", func() {
+			registry := NewRegistry(logr.Discard())
+			_, err := registry.RegisterWorkload(context.Background(), fetchfyv1alpha2.DiscoverySourcePod,
+				newWorkload("10.0.0.7"), ServiceTypeTool, Endpoints{Ready: 1, Total: 1})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = registry.RegisterService(context.Background(), &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "tools", Annotations: map[string]string{}},
+				Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+			}, ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+
+			gateway := &fetchfyv1alpha2.Gateway{}
+			Expect(registry.UpdateRegistryStatus(gateway, nil)).To(HaveLen(1))
+			Expect(gateway.Status.MCPServices[0].Name).To(Equal("search"))
+
+			gateway.Spec.Discovery = &fetchfyv1alpha2.GatewayDiscovery{
+				Sources: []fetchfyv1alpha2.DiscoverySource{
+					fetchfyv1alpha2.DiscoverySourceService, fetchfyv1alpha2.DiscoverySourcePod,
+				},
+			}
+			Expect(registry.UpdateRegistryStatus(gateway, nil)).To(HaveLen(2))
+
+			filter, err := newServiceFilter(nil, &fetchfyv1alpha2.GatewaySpec{Discovery: &fetchfyv1alpha2.GatewayDiscovery{
+				Sources: []fetchfyv1alpha2.DiscoverySource{fetchfyv1alpha2.DiscoverySourcePod},
+			}}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			workload, _ := registry.GetService(types.NamespacedName{Name: "pod/weather", Namespace: "tools"})
+			service, _ := registry.GetService(types.NamespacedName{Name: "search", Namespace: "tools"})
+			Expect(filter.matches(workload)).To(BeTrue())
+			Expect(filter.matches(service)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,320 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+// maxInspectedBody is the largest request body that is parsed to match routes on the MCP method or tool
+const maxInspectedBody = 1 << 20
+
+// Backend is a Service port that MCP requests are forwarded to
+type Backend struct {
+	Service types.NamespacedName
+	Port    int32
+	Weight  int32
+
+	// Scheme and Host override the scheme and the cluster DNS name of the Service, e.g. for ExternalName Services
+	Scheme string
+	Host   string
+}
+
+// RouteParent is a gateway, and optionally one of its listeners, that accepted a route
+type RouteParent struct {
+	Gateway types.NamespacedName
+
+	// Listener is the listener the route is attached to, all listeners when empty
+	Listener string
+}
+
+// RouteRule routes requests matching any of its matches to its backends. A rule
+// without matches matches all MCP requests. Requests may also be mirrored to a shadow backend.
+type RouteRule struct {
+	Matches  []fetchfyv1alpha2.MCPRouteMatch
+	Backends []Backend
+	Mirror   *Mirror
+}
+
+// Route is an MCPRoute compiled for the data plane
+type Route struct {
+	Name      types.NamespacedName
+	CreatedAt time.Time
+	Parents   []RouteParent
+	Rules     []RouteRule
+}
+
+// attachedTo reports whether the route is attached to the listener of the gateway
+func (r *Route) attachedTo(gateway types.NamespacedName, listener string) bool {
+	for _, parent := range r.Parents {
+		if parent.Gateway == gateway && (parent.Listener == "" || parent.Listener == listener) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsBody reports whether matching the route requires the parsed request body
+func (r *Route) needsBody() bool {
+	for _, rule := range r.Rules {
+		for _, match := range rule.Matches {
+			if match.Method != "" || match.Tool != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetRoute adds or replaces a route in the registry
+func (r *Registry) SetRoute(route *Route) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.routes[route.Name] = route
+	r.log.Info("Updated MCP route", "name", route.Name.Name, "namespace", route.Name.Namespace,
+		"parents", len(route.Parents))
+}
+
+// DeleteRoute removes a route from the registry
+func (r *Registry) DeleteRoute(name types.NamespacedName) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.routes[name]; !exists {
+		return false
+	}
+	delete(r.routes, name)
+	r.log.Info("Deleted MCP route", "name", name.Name, "namespace", name.Namespace)
+	return true
+}
+
+// RoutesFor returns the routes attached to any listener of the gateway
+func (r *Registry) RoutesFor(gateway types.NamespacedName) []*Route {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var routes []*Route
+	for _, route := range r.routes {
+		for _, parent := range route.Parents {
+			if parent.Gateway == gateway {
+				routes = append(routes, route)
+				break
+			}
+		}
+	}
+	return routes
+}
+
+// mcpRequest holds the parts of a request that routes match on
+type mcpRequest struct {
+	path   string
+	header http.Header
+	method string
+	tool   string
+}
+
+// jsonRPCRequest is the part of a JSON-RPC request needed for routing
+type jsonRPCRequest struct {
+	Method string `json:"method"`
+	Params struct {
+		Name string `json:"name"`
+	} `json:"params"`
+}
+
+// newMCPRequest extracts the routing attributes of a request. The body is only parsed
+// when parseBody is set, and is restored so it can still be forwarded.
+func newMCPRequest(r *http.Request, parseBody bool) *mcpRequest {
+	req := &mcpRequest{path: r.URL.Path, header: r.Header}
+	if !parseBody || r.Method != http.MethodPost {
+		return req
+	}
+
+	body, ok := readBody(r)
+	if !ok {
+		return req
+	}
+
+	var rpc jsonRPCRequest
+	if json.Unmarshal(body, &rpc) == nil {
+		req.method = rpc.Method
+		if rpc.Method == "tools/call" {
+			req.tool = rpc.Params.Name
+		}
+	}
+	return req
+}
+
+// readBody returns the request body and restores it so it can still be forwarded.
+// It returns false if the body is larger than maxInspectedBody or can't be read.
+func readBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInspectedBody+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	return body, err == nil && len(body) <= maxInspectedBody
+}
+
+// matchesRoute reports whether the request meets all conditions of the match
+func matchesRoute(match *fetchfyv1alpha2.MCPRouteMatch, req *mcpRequest) bool {
+	if match.Path != nil {
+		value := strings.TrimSuffix(match.Path.Value, "/")
+		if match.Path.Type == fetchfyv1alpha2.PathMatchExact {
+			if req.path != match.Path.Value {
+				return false
+			}
+		} else if req.path != value && !strings.HasPrefix(req.path, value+"/") {
+			return false
+		}
+	}
+	for _, header := range match.Headers {
+		if req.header.Get(header.Name) != header.Value {
+			return false
+		}
+	}
+	if match.Method != "" && req.method != match.Method {
+		return false
+	}
+	if match.Tool != "" && req.tool != match.Tool {
+		return false
+	}
+	return true
+}
+
+// routeCandidate is a rule of a route together with the match a request met
+type routeCandidate struct {
+	route *Route
+	rule  *RouteRule
+	match *fetchfyv1alpha2.MCPRouteMatch
+}
+
+// moreSpecific orders candidates by precedence: exact paths before prefixes, longer paths,
+// tool, method and header matches, then the oldest route and finally the route's name
+func (c routeCandidate) moreSpecific(other routeCandidate) bool {
+	a, b := c.match, other.match
+	if a == nil || b == nil {
+		if a != b {
+			return b == nil
+		}
+	} else {
+		aExact := a.Path != nil && a.Path.Type == fetchfyv1alpha2.PathMatchExact
+		bExact := b.Path != nil && b.Path.Type == fetchfyv1alpha2.PathMatchExact
+		if aExact != bExact {
+			return aExact
+		}
+		if aLen, bLen := pathLength(a), pathLength(b); aLen != bLen {
+			return aLen > bLen
+		}
+		if (a.Tool != "") != (b.Tool != "") {
+			return a.Tool != ""
+		}
+		if (a.Method != "") != (b.Method != "") {
+			return a.Method != ""
+		}
+		if len(a.Headers) != len(b.Headers) {
+			return len(a.Headers) > len(b.Headers)
+		}
+	}
+	if !c.route.CreatedAt.Equal(other.route.CreatedAt) {
+		return c.route.CreatedAt.Before(other.route.CreatedAt)
+	}
+	if c.route.Name != other.route.Name {
+		return c.route.Name.String() < other.route.Name.String()
+	}
+	return false
+}
+
+// pathLength returns the length of the path a match requires, 0 if it matches any path
+func pathLength(match *fetchfyv1alpha2.MCPRouteMatch) int {
+	if match.Path == nil {
+		return 0
+	}
+	return len(strings.TrimSuffix(match.Path.Value, "/"))
+}
+
+// matchRoute returns the most specific rule of the routes that matches the request
+func matchRoute(routes []*Route, gateway types.NamespacedName, listener string, req *mcpRequest) (*Route, *RouteRule) {
+	var candidates []routeCandidate
+	for _, route := range routes {
+		if !route.attachedTo(gateway, listener) {
+			continue
+		}
+		for i := range route.Rules {
+			rule := &route.Rules[i]
+			if len(rule.Matches) == 0 {
+				candidates = append(candidates, routeCandidate{route: route, rule: rule})
+				continue
+			}
+			for j := range rule.Matches {
+				if matchesRoute(&rule.Matches[j], req) {
+					candidates = append(candidates, routeCandidate{route: route, rule: rule, match: &rule.Matches[j]})
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].moreSpecific(candidates[j])
+	})
+	return candidates[0].route, candidates[0].rule
+}
+
+// pickBackend picks one of the backends at random, in proportion to their weights.
+// It returns false if no backend has a positive weight.
+func pickBackend(backends []Backend) (Backend, bool) {
+	return pickWeighted(backends, rand.Int())
+}
+
+// pickWeighted maps n onto the backends in proportion to their weights. It returns
+// false if no backend has a positive weight.
+func pickWeighted(backends []Backend, n int) (Backend, bool) {
+	total := 0
+	for _, backend := range backends {
+		total += int(backend.Weight)
+	}
+	if total <= 0 {
+		return Backend{}, false
+	}
+
+	n %= total
+	for _, backend := range backends {
+		if n < int(backend.Weight) {
+			return backend, true
+		}
+		n -= int(backend.Weight)
+	}
+	return Backend{}, false
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/metrics"
+)
+
+var _ = Describe("Routes", func() {
+	var (
+		registry *Registry
+		server   *Server
+		gateway  = types.NamespacedName{Name: "gw", Namespace: "default"}
+		now      = time.Now()
+	)
+
+	backend := func(name string, weight int32) Backend {
+		return Backend{Service: types.NamespacedName{Name: name, Namespace: "tools"}, Port: 80, Weight: weight}
+	}
+
+	prefix := func(path string) *fetchfyv1alpha2.PathMatch {
+		return &fetchfyv1alpha2.PathMatch{Type: fetchfyv1alpha2.PathMatchPathPrefix, Value: path}
+	}
+
+	newRoute := func(name string, created time.Time, rules ...RouteRule) *Route {
+		return &Route{
+			Name:      types.NamespacedName{Name: name, Namespace: "tools"},
+			CreatedAt: created,
+			Parents:   []RouteParent{{Gateway: gateway}},
+			Rules:     rules,
+		}
+	}
+
+	post := func(path, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "http://gw.example.com"+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req.WithContext(context.WithValue(req.Context(), listenerKey{}, "mcp"))
+	}
+
+	BeforeEach(func() {
+		registry = NewRegistry(logr.Discard())
+		server = NewServer(registry, logr.Discard())
+		server.Configure(newTestGateway(8080), nil)
+	})
+
+	Describe("matching", func() {
+		match := func(req *http.Request) *RouteRule {
+			_, rule := matchRoute(registry.RoutesFor(gateway), gateway, "mcp", newMCPRequest(req, true))
+			return rule
+		}
+
+		It("should match the JSON-RPC method and tool and keep the body", func() {
+			registry.SetRoute(newRoute("weather", now, RouteRule{
+				Matches: []fetchfyv1alpha2.MCPRouteMatch{{
+					Path:   prefix("/mcp/tools/weather"),
+					Method: "tools/call",
+					Tool:   "get_forecast",
+				}},
+				Backends: []Backend{backend("weather-v2", 1)},
+			}))
+
+			body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_forecast"}}`
+			req := post("/mcp/tools/weather", body)
+			Expect(match(req)).NotTo(BeNil())
+			Expect(io.ReadAll(req.Body)).To(BeEquivalentTo(body))
+
+			Expect(match(post("/mcp/tools/weather", `{"method":"tools/list"}`))).To(BeNil())
+			Expect(match(post("/mcp/tools/weather-2", body))).To(BeNil())
+		})
+
+		It("should prefer more specific matches, then older routes", func() {
+			registry.SetRoute(newRoute("catch-all", now.Add(-time.Hour),
+				RouteRule{Backends: []Backend{backend("default", 1)}}))
+			registry.SetRoute(newRoute("tools", now, RouteRule{
+				Matches:  []fetchfyv1alpha2.MCPRouteMatch{{Path: prefix("/mcp/tools")}},
+				Backends: []Backend{backend("tools", 1)},
+			}))
+			registry.SetRoute(newRoute("canary", now, RouteRule{
+				Matches: []fetchfyv1alpha2.MCPRouteMatch{{
+					Path:    prefix("/mcp/tools"),
+					Headers: []fetchfyv1alpha2.HeaderMatch{{Name: "X-Canary", Value: "true"}},
+				}},
+				Backends: []Backend{backend("canary", 1)},
+			}))
+			registry.SetRoute(newRoute("tools-newer", now.Add(time.Hour), RouteRule{
+				Matches:  []fetchfyv1alpha2.MCPRouteMatch{{Path: prefix("/mcp/tools")}},
+				Backends: []Backend{backend("tools-newer", 1)},
+			}))
+
+			Expect(match(post("/mcp/agents/a", "{}")).Backends[0].Service.Name).To(Equal("default"))
+			Expect(match(post("/mcp/tools/a", "{}")).Backends[0].Service.Name).To(Equal("tools"))
+
+			req := post("/mcp/tools/a", "{}")
+			req.Header.Set("X-Canary", "true")
+			Expect(match(req).Backends[0].Service.Name).To(Equal("canary"))
+		})
+
+		It("should only match on the listeners the route is attached to", func() {
+			route := newRoute("internal", now, RouteRule{Backends: []Backend{backend("internal", 1)}})
+			route.Parents[0].Listener = "internal"
+			registry.SetRoute(route)
+
+			Expect(match(post("/mcp/tools/a", "{}"))).To(BeNil())
+		})
+	})
+
+	It("should split requests across backends by weight", func() {
+		backends := []Backend{backend("stable", 3), backend("canary", 1), backend("disabled", 0)}
+		counts := map[string]int{}
+		for i := 0; i < 4000; i++ {
+			picked, ok := pickBackend(backends)
+			Expect(ok).To(BeTrue())
+			counts[picked.Service.Name]++
+		}
+		Expect(counts["disabled"]).To(BeZero())
+		Expect(counts["canary"]).To(BeNumerically("~", 1000, 150))
+
+		_, ok := pickBackend([]Backend{backend("disabled", 0)})
+		Expect(ok).To(BeFalse())
+	})
+
+	Describe("forwarding", func() {
+		var upstream *httptest.Server
+
+		BeforeEach(func() {
+			upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				w.Header().Set("X-Backend-Path", r.URL.Path)
+				w.Write(body)
+			}))
+			target, err := url.Parse(upstream.URL)
+			Expect(err).NotTo(HaveOccurred())
+			server.SetBackendURLFunc(func(Backend) *url.URL { return target })
+		})
+
+		AfterEach(func() {
+			upstream.Close()
+		})
+
+		It("should forward routed requests to the backend", func() {
+			registry.SetRoute(newRoute("weather", now, RouteRule{
+				Matches:  []fetchfyv1alpha2.MCPRouteMatch{{Method: "tools/call"}},
+				Backends: []Backend{backend("weather", 1)},
+			}))
+
+			rec := httptest.NewRecorder()
+			server.handleMCPRequest(rec, post("/mcp/tools/weather", `{"method":"tools/call"}`))
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("X-Backend-Path")).To(Equal("/mcp/tools/weather"))
+			Expect(rec.Body.String()).To(Equal(`{"method":"tools/call"}`))
+		})
+
+		It("should forward requests for a service endpoint without a route", func() {
+			_, err := registry.RegisterService(context.Background(), &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "calculator",
+					Namespace:   "tools",
+					Annotations: map[string]string{EndpointAnnotation: "/mcp/tools/calculator"},
+				},
+				Spec: corev1.ServiceSpec{
+					Type:  corev1.ServiceTypeClusterIP,
+					Ports: []corev1.ServicePort{{Port: 80}},
+				},
+			}, ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+
+			rec := httptest.NewRecorder()
+			server.handleMCPRequest(rec, post("/mcp/tools/calculator", `{}`))
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("X-Backend-Path")).To(Equal("/mcp/tools/calculator"))
+		})
+
+		It("should keep sessions on the backend that created them", func() {
+			versions := map[string]*httptest.Server{}
+			for _, name := range []string{"weather", "weather-v2"} {
+				version := name
+				versions[name] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get(SessionIDHeader) == "" {
+						w.Header().Set(SessionIDHeader, version+"-session")
+					}
+					w.Header().Set("X-Backend", version)
+					w.Header().Set("X-Backend-Session", r.Header.Get(SessionIDHeader))
+				}))
+				defer versions[name].Close()
+			}
+			server.SetBackendURLFunc(func(backend Backend) *url.URL {
+				target, _ := url.Parse(versions[backend.Service.Name].URL)
+				return target
+			})
+
+			rule := RouteRule{Backends: []Backend{backend("weather", 1), backend("weather-v2", 1)}}
+			registry.SetRoute(newRoute("weather", now, rule))
+
+			rec := httptest.NewRecorder()
+			server.handleMCPRequest(rec, post("/mcp/tools/weather", `{"method":"initialize"}`))
+			sessionID := rec.Header().Get(SessionIDHeader)
+			version := rec.Header().Get("X-Backend")
+			Expect(sessionID).To(HaveSuffix("." + version + "-session"))
+
+			By("ramping the session's backend down to 0")
+			for i := range rule.Backends {
+				if rule.Backends[i].Service.Name == version {
+					rule.Backends[i].Weight = 0
+				}
+			}
+			registry.SetRoute(newRoute("weather", now, rule))
+
+			replica := NewServer(registry, logr.Discard())
+			replica.Configure(newTestGateway(8080), nil)
+			defer replica.Stop(context.Background())
+			replica.SetBackendURLFunc(func(backend Backend) *url.URL {
+				target, _ := url.Parse(versions[backend.Service.Name].URL)
+				return target
+			})
+			for i := 0; i < 20; i++ {
+				req := post("/mcp/tools/weather", `{"method":"tools/call"}`)
+				req.Header.Set(SessionIDHeader, sessionID)
+				rec := httptest.NewRecorder()
+				// Every gateway replica sends the session to its backend
+				if i%2 == 0 {
+					server.handleMCPRequest(rec, req)
+				} else {
+					replica.handleMCPRequest(rec, req)
+				}
+				Expect(rec.Header().Get("X-Backend")).To(Equal(version))
+				Expect(rec.Header().Get("X-Backend-Session")).To(Equal(version + "-session"))
+			}
+
+			By("sending new sessions to the remaining backend")
+			rec = httptest.NewRecorder()
+			server.handleMCPRequest(rec, post("/mcp/tools/weather", `{"method":"initialize"}`))
+			Expect(rec.Header().Get("X-Backend")).NotTo(Equal(version))
+		})
+
+		It("should pick the same backend for a session created without the split", func() {
+			backends := []Backend{backend("weather", 1), backend("weather-v2", 1)}
+			first, sessionID, ok := pickSessionBackend("session-1", backends)
+			Expect(ok).To(BeTrue())
+			Expect(sessionID).To(Equal("session-1"))
+			for i := 0; i < 10; i++ {
+				picked, _, _ := pickSessionBackend("session-1", backends)
+				Expect(picked).To(Equal(first))
+			}
+		})
+
+		It("should record per-backend metrics", func() {
+			registry.SetRoute(newRoute("weather", now, RouteRule{Backends: []Backend{backend("weather", 1)}}))
+			counter := func(result string) float64 {
+				return testutil.ToFloat64(metrics.BackendRequestCount.WithLabelValues(
+					"default/gw", "tools/weather", "tools/weather", "200", result))
+			}
+			// The upstream echoes the request body
+			for body, result := range map[string]string{
+				`{}`: BackendResultUnknown,
+				`{"jsonrpc":"2.0","id":1,"result":{"content":[]}}`:                       BackendResultSuccess,
+				`{"jsonrpc":"2.0","id":1,"result":{"content":[],"isError":true}}`:        BackendResultToolError,
+				`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"not found"}}`: BackendResultError,
+			} {
+				before := counter(result)
+				server.handleMCPRequest(httptest.NewRecorder(), post("/mcp/tools/weather", body))
+				Expect(counter(result)).To(Equal(before+1), result)
+			}
+		})
+
+		It("should reject routed requests without a weighted backend", func() {
+			registry.SetRoute(newRoute("disabled", now, RouteRule{Backends: []Backend{backend("weather", 0)}}))
+
+			rec := httptest.NewRecorder()
+			server.handleMCPRequest(rec, post("/mcp/tools/weather", `{}`))
+			Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+})
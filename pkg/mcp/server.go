@@ -18,140 +18,698 @@ package mcp
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/types"
 
-	fetchfyv1alpha1 "github.com/fetchfy/fetchfy-operator/api/v1alpha1"
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
 )
 
-// Server represents an MCP gateway server that handles connections and routes requests
-// to the appropriate MCP services
+var (
+	// ErrListenFailed is returned when a gateway listener cannot be bound
+	ErrListenFailed = errors.New("failed to bind MCP gateway listener")
+
+	// ErrTLSConfig is returned when the TLS configuration of a listener is invalid
+	ErrTLSConfig = errors.New("invalid MCP gateway TLS configuration")
+
+	// ErrListenerConfig is returned when the configuration of a listener is invalid
+	ErrListenerConfig = errors.New("invalid MCP gateway listener configuration")
+
+	// ErrShuttingDown is returned when listeners are synced after the server was shut down
+	ErrShuttingDown = errors.New("MCP gateway server is shutting down")
+)
+
+// DefaultDrainTimeout is how long a replaced listener may drain in-flight requests
+// and streaming sessions before its connections are closed
+const DefaultDrainTimeout = 30 * time.Second
+
+// listenerConfig is the part of a listener's configuration that requires rebinding
+type listenerConfig struct {
+	port      int32
+	enableTLS bool
+}
+
+// listenerSettings is the desired configuration of a listener. Everything but the
+// listenerConfig applies to a running listener without rebinding.
+type listenerSettings struct {
+	config    listenerConfig
+	auth      *fetchfyv1alpha2.GatewayAuth
+	filter    *serviceFilter
+	filterErr error
+}
+
+// listenerState is a bound listener and the HTTP server serving it
+type listenerState struct {
+	name       string
+	config     listenerConfig
+	listener   net.Listener
+	httpServer *http.Server
+
+	// draining is closed when the listener starts shutting down so that
+	// long-lived streaming handlers can end their sessions
+	draining chan struct{}
+
+	// cancel cancels the contexts of the requests served by the listener
+	cancel context.CancelFunc
+}
+
+// ListenerStatus is the observed state of a listener
+type ListenerStatus struct {
+	// Name is the name of the listener
+	Name string
+
+	// Addr is the address the listener is bound to, empty if it isn't serving
+	Addr string
+
+	// Err is the last error binding or serving the listener, nil if it is up to date
+	Err error
+
+	// Services is the number of services visible through the listener
+	Services int
+}
+
+// drainingKey is the request context key holding the draining channel of the serving listener
+type drainingKey struct{}
+
+// listenerKey is the request context key holding the name of the serving listener
+type listenerKey struct{}
+
+// Server represents an MCP gateway server that handles connections on one or more
+// listeners and routes requests to the appropriate MCP services
 type Server struct {
 	registry      *Registry
-	httpServer    *http.Server
-	port          int32
 	log           logr.Logger
 	gatewayRef    types.NamespacedName
-	enableTLS     bool
-	tlsSecretName string
+	drainTimeout  time.Duration
+	certificates  map[string]*tls.Certificate
+	settings      map[string]listenerSettings
+	order         []string
+	active        map[string]*listenerState
+	errs          map[string]error
+	backendURL    BackendURLFunc
+	shadows       *sessionTable[string]
+	cache         *responseCache
+	tokens        *tokenVerifier
+	notifications *notificationHub
+	unsubscribe   func()
+	audit         logr.Logger
+	handler       http.Handler
+	replaced      map[*listenerState]struct{}
+	drains        sync.WaitGroup
+	shutdown      bool
 	mutex         sync.Mutex
-	started       bool
 }
 
-// NewServer creates a new MCP gateway server
+// NewServer creates a new MCP gateway server. It receives the registry's changes until it is stopped.
 func NewServer(registry *Registry, log logr.Logger) *Server {
-	return &Server{
-		registry: registry,
-		log:      log.WithName("mcp-server"),
-		started:  false,
+	s := &Server{
+		registry:      registry,
+		log:           log.WithName("mcp-server"),
+		drainTimeout:  DefaultDrainTimeout,
+		certificates:  make(map[string]*tls.Certificate),
+		settings:      make(map[string]listenerSettings),
+		active:        make(map[string]*listenerState),
+		errs:          make(map[string]error),
+		replaced:      make(map[*listenerState]struct{}),
+		backendURL:    ClusterBackendURL,
+		shadows:       newSessionTable[string](),
+		cache:         newResponseCache(),
+		tokens:        newTokenVerifier(),
+		notifications: newNotificationHub(),
+		audit:         log.WithName("mirror-audit"),
 	}
+	s.unsubscribe = s.subscribe()
+	return s
 }
 
-// Configure configures the server with the gateway's settings
-func (s *Server) Configure(gateway *fetchfyv1alpha1.Gateway) {
+// Configure configures the server with the gateway's listeners. The gateway discovers local
+// services in the given namespaces, nil standing for all namespaces. Authentication and
+// service filtering changes apply immediately; port and TLS changes apply on the next Sync.
+func (s *Server) Configure(gateway *fetchfyv1alpha2.Gateway, namespaces []string) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	s.port = gateway.Spec.MCPPort
-	s.enableTLS = gateway.Spec.EnableTLS
-	s.tlsSecretName = gateway.Spec.TLSSecretRef
-	s.gatewayRef = types.NamespacedName{
-		Name:      gateway.Name,
-		Namespace: gateway.Namespace,
+	s.gatewayRef = types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace}
+	s.drainTimeout = DefaultDrainTimeout
+	if gateway.Spec.DrainTimeout != nil {
+		s.drainTimeout = gateway.Spec.DrainTimeout.Duration
 	}
 
-	s.log.Info("Configured MCP server",
-		"port", s.port,
-		"enableTLS", s.enableTLS,
-		"gateway", fmt.Sprintf("%s/%s", gateway.Namespace, gateway.Name))
+	previous := s.settings
+	s.settings = make(map[string]listenerSettings, len(gateway.Spec.Listeners))
+	s.order = make([]string, 0, len(gateway.Spec.Listeners))
+	for _, listener := range gateway.Spec.Listeners {
+		s.order = append(s.order, listener.Name)
+		filter, err := newServiceFilter(listener.Services, &gateway.Spec, namespaces)
+		if err != nil {
+			err = fmt.Errorf("%w: %v", ErrListenerConfig, err)
+			s.log.Error(err, "Keeping the previous MCP listener settings", "listener", listener.Name,
+				"gateway", s.gatewayRef.String())
+
+			// A running listener keeps serving with its previous settings, a new one exposes nothing
+			settings, ok := previous[listener.Name]
+			if !ok {
+				settings = listenerSettings{filter: noServices}
+			}
+			settings.filterErr = err
+			s.settings[listener.Name] = settings
+			continue
+		}
+		s.settings[listener.Name] = listenerSettings{
+			config: listenerConfig{
+				port:      listener.Port,
+				enableTLS: listener.Protocol == fetchfyv1alpha2.ProtocolHTTPS,
+			},
+			auth:   listener.EffectiveAuth(gateway.Spec.Auth).DeepCopy(),
+			filter: filter,
+		}
+
+		s.log.Info("Configured MCP listener",
+			"listener", listener.Name,
+			"port", listener.Port,
+			"protocol", listener.Protocol,
+			"auth", listener.AuthEnabled(gateway.Spec.Auth),
+			"gateway", s.gatewayRef.String())
+	}
 }
 
-// Start starts the MCP gateway server
-func (s *Server) Start(ctx context.Context) error {
+// SetCertificate sets the certificate served by a TLS listener. A running listener
+// picks up the new certificate on the next handshake.
+func (s *Server) SetCertificate(listener string, cert *tls.Certificate) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if s.started {
-		return fmt.Errorf("server already started")
-	}
+	s.certificates[listener] = cert
+}
 
-	mux := http.NewServeMux()
+// SetBackendURLFunc sets how the URLs of backends are resolved, ClusterBackendURL by default
+func (s *Server) SetBackendURLFunc(backendURL BackendURLFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	// Root handler for health checks
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/" {
-			http.NotFound(w, r)
-			return
+	s.backendURL = backendURL
+}
+
+// Sync binds the configured listeners that aren't serving, restarts listeners whose port
+// or TLS mode changed and drains listeners that were removed. Listeners are bound before
+// Sync returns, independently of each other; the returned error joins the errors of all
+// listeners that failed.
+//
+// When a listener's port changes, the new listener is bound before the old one stops
+// accepting, so a bind failure leaves the old listener serving. Replaced listeners drain
+// in-flight requests and streaming sessions in the background for up to the drain timeout.
+func (s *Server) Sync(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.shutdown {
+		return ErrShuttingDown
+	}
+	if s.unsubscribe == nil {
+		s.unsubscribe = s.subscribe()
+	}
+
+	var errs []error
+	for _, name := range s.order {
+		if err := s.syncListener(name); err != nil {
+			s.errs[name] = err
+			errs = append(errs, fmt.Errorf("listener %s: %w", name, err))
+		} else {
+			delete(s.errs, name)
 		}
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("Fetchfy MCP Gateway: OK"))
-	})
+	}
 
-	// MCP routes handler
-	mux.HandleFunc("/mcp/", s.handleMCPRequest)
+	for name, state := range s.active {
+		if _, desired := s.settings[name]; !desired {
+			s.log.Info("Removing MCP listener", "listener", name, "address", state.listener.Addr().String())
+			delete(s.active, name)
+			s.drainAsync(state)
+		}
+	}
+	for name := range s.errs {
+		if _, desired := s.settings[name]; !desired {
+			delete(s.errs, name)
+		}
+	}
 
-	// API endpoints for MCP management
-	mux.HandleFunc("/api/services", s.handleListServices)
+	return errors.Join(errs...)
+}
 
-	addr := fmt.Sprintf(":%d", s.port)
-	s.httpServer = &http.Server{
-		Addr:    addr,
-		Handler: mux,
+// syncListener brings a single listener in line with its settings. Callers must hold the mutex.
+func (s *Server) syncListener(name string) error {
+	settings := s.settings[name]
+	if settings.filterErr != nil {
+		return settings.filterErr
 	}
 
-	s.log.Info("Starting MCP gateway server", "address", addr)
+	old := s.active[name]
+	if old != nil && old.config == settings.config {
+		return nil
+	}
 
-	go func() {
-		var err error
-		if s.enableTLS && s.tlsSecretName != "" {
-			// TODO: Implement TLS with cert from secret
-			s.log.Error(fmt.Errorf("TLS support not implemented yet"), "Failed to start server with TLS")
-			err = s.httpServer.ListenAndServe()
-		} else {
-			err = s.httpServer.ListenAndServe()
+	if old != nil && old.config.port == settings.config.port {
+		// The port can only be bound once, so stop accepting on the old listener first
+		if err := old.listener.Close(); err != nil {
+			s.log.Error(err, "Failed to close listener before restart", "address", old.listener.Addr().String())
 		}
+	}
 
-		if err != nil && err != http.ErrServerClosed {
-			s.log.Error(err, "MCP gateway server failed")
+	state, err := s.bind(name, settings.config)
+	if err != nil {
+		if old != nil && old.config.port == settings.config.port {
+			// The old listener is gone, so the listener is down until the next successful sync
+			delete(s.active, name)
+			s.drainAsync(old)
 		}
-	}()
+		return err
+	}
 
-	s.started = true
+	if old != nil {
+		s.log.Info("Restarting MCP listener", "listener", name,
+			"from", old.listener.Addr().String(), "to", state.listener.Addr().String(),
+			"drainTimeout", s.drainTimeout)
+		s.drainAsync(old)
+	}
+	s.serve(state)
 	return nil
 }
 
-// Stop stops the MCP gateway server
-func (s *Server) Stop(ctx context.Context) error {
+// NeedsRestart returns true if a running listener doesn't match its configured port or
+// TLS mode, or was removed from the configuration
+func (s *Server) NeedsRestart() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for name, state := range s.active {
+		settings, desired := s.settings[name]
+		if !desired || settings.config != state.config {
+			return true
+		}
+	}
+	return false
+}
+
+// Listeners returns the state of the configured listeners, in configuration order
+func (s *Server) Listeners() []ListenerStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	services := s.registry.ListServices()
+	statuses := make([]ListenerStatus, 0, len(s.order))
+	for _, name := range s.order {
+		status := ListenerStatus{Name: name, Err: s.errs[name]}
+		if state, ok := s.active[name]; ok {
+			status.Addr = state.listener.Addr().String()
+		}
+		for _, svc := range services {
+			if s.settings[name].filter.matches(svc) {
+				status.Services++
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// selectedLabels adds the keys of the labels the listeners select services by
+func (s *Server) selectedLabels(keys map[string]bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, settings := range s.settings {
+		settings.filter.selectedLabels(keys)
+	}
+}
+
+// Draining returns the number of replaced listeners that are still draining
+func (s *Server) Draining() int {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	return len(s.replaced)
+}
+
+// Stop stops all listeners of the MCP gateway server
+func (s *Server) Stop(ctx context.Context) error {
+	s.mutex.Lock()
+	states := s.active
+	s.active = make(map[string]*listenerState)
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+		s.unsubscribe = nil
+	}
+	s.mutex.Unlock()
 
-	if !s.started || s.httpServer == nil {
+	if len(states) == 0 {
 		return nil
 	}
 
 	s.log.Info("Stopping MCP gateway server")
-	err := s.httpServer.Shutdown(ctx)
-	s.started = false
-	return err
+	var errs []error
+	for _, state := range states {
+		errs = append(errs, state.httpServer.Shutdown(ctx))
+		state.cancel()
+	}
+	return errors.Join(errs...)
 }
 
-// IsRunning returns true if the server is running
+// Shutdown drains the server for good when the operator stops. Its listeners stop accepting
+// connections and refuse new sessions, its SSE streams end with a notification asking clients
+// to reconnect, and in-flight requests may complete until ctx is done. The connections still
+// open then are closed. Sync doesn't bind the listeners again.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	s.shutdown = true
+	states := s.active
+	s.active = make(map[string]*listenerState)
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+		s.unsubscribe = nil
+	}
+	s.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	var forced atomic.Bool
+	for _, state := range states {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !drain(ctx, state) {
+				forced.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Listeners replaced before drain on their own timeout, but not beyond ctx
+	drained := make(chan struct{})
+	go func() {
+		s.drains.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		forced.Store(true)
+		s.mutex.Lock()
+		for state := range s.replaced {
+			state.cancel()
+			_ = state.httpServer.Close()
+		}
+		s.mutex.Unlock()
+	}
+
+	if forced.Load() {
+		return fmt.Errorf("closed connections that outlived the grace period: %w", ctx.Err())
+	}
+	return nil
+}
+
+// IsRunning returns true if at least one listener is serving
 func (s *Server) IsRunning() bool {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
-	return s.started
+	return len(s.active) > 0
 }
 
-// handleMCPRequest handles MCP protocol requests and routes them to the appropriate service
+// Addr returns the address of the named listener, or an empty string if it is not serving
+func (s *Server) Addr(listener string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if state, ok := s.active[listener]; ok {
+		return state.listener.Addr().String()
+	}
+	return ""
+}
+
+// bind binds a listener for the given configuration. Callers must hold the mutex.
+func (s *Server) bind(name string, config listenerConfig) (*listenerState, error) {
+	if s.handler == nil {
+		s.handler = s.newHandler()
+	}
+
+	var tlsConfig *tls.Config
+	if config.enableTLS {
+		if s.certificates[name] == nil {
+			return nil, fmt.Errorf("%w: TLS is enabled but no certificate is configured", ErrTLSConfig)
+		}
+		tlsConfig = &tls.Config{
+			GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+				return s.certificate(name), nil
+			},
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	addr := fmt.Sprintf(":%d", config.port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w on %s: %v", ErrListenFailed, addr, err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	baseCtx, cancel := context.WithCancel(context.Background())
+	state := &listenerState{
+		name:     name,
+		config:   config,
+		listener: listener,
+		draining: make(chan struct{}),
+		cancel:   cancel,
+	}
+	state.httpServer = &http.Server{
+		Addr:    addr,
+		Handler: s.handler,
+		BaseContext: func(net.Listener) context.Context {
+			ctx := context.WithValue(baseCtx, listenerKey{}, name)
+			return context.WithValue(ctx, drainingKey{}, (<-chan struct{})(state.draining))
+		},
+	}
+	state.httpServer.RegisterOnShutdown(func() { close(state.draining) })
+
+	return state, nil
+}
+
+// certificate returns the certificate of a TLS listener
+func (s *Server) certificate(listener string) *tls.Certificate {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.certificates[listener]
+}
+
+// serve makes state the active listener for its name and serves it in the background.
+// Callers must hold the mutex.
+func (s *Server) serve(state *listenerState) {
+	s.active[state.name] = state
+
+	s.log.Info("Starting MCP listener", "listener", state.name,
+		"address", state.listener.Addr().String(), "tls", state.config.enableTLS)
+
+	go func() {
+		err := state.httpServer.Serve(state.listener)
+		if err != nil && err != http.ErrServerClosed {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			// A listener closed for a same-port restart is expected to fail
+			if s.active[state.name] == state {
+				s.log.Error(err, "MCP listener failed", "listener", state.name)
+				s.errs[state.name] = err
+				delete(s.active, state.name)
+			}
+		}
+	}()
+}
+
+// drainAsync shuts down a replaced listener in the background. Callers must hold the mutex.
+func (s *Server) drainAsync(state *listenerState) {
+	s.replaced[state] = struct{}{}
+	s.drains.Add(1)
+	timeout := s.drainTimeout
+
+	go func() {
+		defer s.drains.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if drain(ctx, state) {
+			s.log.Info("Drained previous listener", "address", state.listener.Addr().String())
+		} else {
+			s.log.Info("Drain timeout exceeded, closed remaining connections",
+				"address", state.listener.Addr().String(), "timeout", timeout)
+		}
+
+		s.mutex.Lock()
+		delete(s.replaced, state)
+		s.mutex.Unlock()
+	}()
+}
+
+// drain shuts down a listener, waiting for in-flight requests until ctx is done and closing
+// the remaining connections then. It returns false if connections had to be closed.
+func drain(ctx context.Context, state *listenerState) bool {
+	defer state.cancel()
+	if err := state.httpServer.Shutdown(ctx); err != nil {
+		// Abort the requests still forwarded to backends along with their connections
+		state.cancel()
+		_ = state.httpServer.Close()
+		return false
+	}
+	return true
+}
+
+// listenerSettingsFor returns the settings of the listener serving the request
+func (s *Server) listenerSettingsFor(r *http.Request) listenerSettings {
+	name := listenerName(r)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.settings[name]
+}
+
+// newHandler builds the HTTP handler shared by all listeners of the server
+func (s *Server) newHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	// Root handler for health checks
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("Fetchfy MCP Gateway: OK"))
+	})
+
+	// Liveness and readiness of the listener, with the health of its backends
+	mux.HandleFunc(HealthzPath, s.handleHealthz)
+	mux.HandleFunc(ReadyzPath, s.handleReadyz)
+
+	// MCP routes handler
+	mux.HandleFunc(MCPBasePath, s.requireAuth(s.handleMCPRequest))
+
+	// Discovery documents for clients that do not speak MCP yet
+	mux.HandleFunc(WellKnownMCPPath, s.handleDiscovery)
+	mux.HandleFunc(WellKnownProtectedResourcePath, s.handleProtectedResourceMetadata)
+
+	// API endpoints for MCP management, which list the same services as the MCP routes
+	mux.HandleFunc("/api/services", s.requireAuth(s.handleListServices))
+	mux.HandleFunc(ServicesWatchPath, s.requireAuth(s.handleWatchServices))
+
+	return refuseNewSessions(mux)
+}
+
+// refuseNewSessions answers requests that would start a new session or stream on a draining
+// listener with 503 Service Unavailable, so that clients retry on another replica. Requests
+// of established sessions are still served.
+func refuseNewSessions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isDraining(r.Context()) && startsSession(r) {
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "MCP gateway listener is draining", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// startsSession reports whether the request opens an SSE stream or is an MCP request outside
+// of a session, e.g. an initialize request
+func startsSession(r *http.Request) bool {
+	if isStreamRequest(r) {
+		return true
+	}
+	return strings.HasPrefix(r.URL.Path, MCPBasePath) && r.Header.Get(SessionIDHeader) == ""
+}
+
+// drainingFrom returns a channel that is closed when the listener serving the request
+// starts draining. Streaming handlers must end their sessions when it is closed.
+func drainingFrom(ctx context.Context) <-chan struct{} {
+	if ch, ok := ctx.Value(drainingKey{}).(<-chan struct{}); ok {
+		return ch
+	}
+	return nil
+}
+
+// isDraining reports whether the listener serving the request is draining
+func isDraining(ctx context.Context) bool {
+	draining := drainingFrom(ctx)
+	if draining == nil {
+		return false
+	}
+	select {
+	case <-draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleMCPRequest handles MCP protocol requests and routes them to the appropriate service.
+// MCPRoutes attached to the listener take precedence over service endpoints.
 func (s *Server) handleMCPRequest(w http.ResponseWriter, r *http.Request) {
-	// This is a simplified implementation. In a real-world scenario,
-	// this would handle routing to the actual MCP services based on the endpoint path
+	listener := listenerName(r)
+	routes := s.registry.RoutesFor(s.gatewayName())
+
+	parseBody := false
+	for _, route := range routes {
+		parseBody = parseBody || route.needsBody()
+	}
+	if route, rule := matchRoute(routes, s.gatewayName(), listener, newMCPRequest(r, parseBody)); rule != nil {
+		backend, sessionID, ok := pickSessionBackend(r.Header.Get(SessionIDHeader), rule.Backends)
+		if !ok {
+			s.log.Info("No backend available for MCP route", "route", route.Name.String(), "path", r.URL.Path)
+			http.Error(w, "No backend available", http.StatusServiceUnavailable)
+			return
+		}
+		// The backend, its cache entries and its shadow see the session ID it assigned
+		if sessionID != "" {
+			r.Header.Set(SessionIDHeader, sessionID)
+		}
+		served, cached := s.serveCached(w, r, backend)
+		if served {
+			return
+		}
+		hooks := forwardHooks{cached: cached, pinSessions: true}
+		if rule.Mirror != nil && !isStreamRequest(r) {
+			hooks.mirrored = s.startMirror(r, route.Name.String(), rule.Mirror)
+		}
+		s.forward(w, r, route.Name.String(), backend, hooks)
+		return
+	}
 
-	// For now, just return a simple response to indicate the request was received
+	svc, ok := s.registry.ServiceForPath(r.URL.Path)
+	// Services hidden from this listener don't exist for its clients
+	if ok && !s.listenerSettingsFor(r).filter.matches(svc) {
+		http.NotFound(w, r)
+		return
+	}
+	if ok {
+		if backend, ok := serviceBackend(svc); ok {
+			if served, cached := s.serveCached(w, r, backend); !served {
+				s.forward(w, withBasePath(r, svc), "", backend, forwardHooks{cached: cached})
+			}
+			return
+		}
+	}
+
+	if isStreamRequest(r) {
+		s.handleStream(w, r)
+		return
+	}
+
+	// Requests to the gateway itself are acknowledged without a backend
 	s.log.Info("Received MCP request", "path", r.URL.Path, "method", r.Method)
 
 	w.Header().Set("Content-Type", "application/json")
@@ -159,18 +717,31 @@ func (s *Server) handleMCPRequest(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status":"ok","message":"MCP request received"}`))
 }
 
-// handleListServices returns a list of registered MCP services
+// gatewayName returns the gateway the server was last configured for
+func (s *Server) gatewayName() types.NamespacedName {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.gatewayRef
+}
+
+// handleListServices returns the number of MCP services visible through the listener
 func (s *Server) handleListServices(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	services := s.registry.ListServices()
+	filter := s.listenerSettingsFor(r).filter
+	count := 0
+	for _, svc := range s.registry.ListServices() {
+		if filter.matches(svc) {
+			count++
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
 	// Simple JSON response with service list
-	w.Write([]byte(fmt.Sprintf(`{"services":%d}`, len(services))))
+	w.Write([]byte(fmt.Sprintf(`{"services":%d}`, count)))
 }
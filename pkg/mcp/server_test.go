@@ -0,0 +1,351 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+// newTestGateway returns a gateway with a single plain HTTP listener on the given port
+func newTestGateway(port int32) *fetchfyv1alpha2.Gateway {
+	return &fetchfyv1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+		Spec: fetchfyv1alpha2.GatewaySpec{
+			Listeners: []fetchfyv1alpha2.Listener{
+				{Name: "mcp", Port: port, Protocol: fetchfyv1alpha2.ProtocolHTTP},
+			},
+		},
+	}
+}
+
+var _ = Describe("Server", func() {
+	var server *Server
+
+	BeforeEach(func() {
+		server = NewServer(NewRegistry(logr.Discard()), logr.Discard())
+	})
+
+	AfterEach(func() {
+		Expect(server.Stop(context.Background())).To(Succeed())
+	})
+
+	It("should bind the listener before Sync returns", func() {
+		server.Configure(newTestGateway(0), nil)
+		Expect(server.Sync(context.Background())).To(Succeed())
+		Expect(server.IsRunning()).To(BeTrue())
+		Expect(server.Addr("mcp")).NotTo(BeEmpty())
+	})
+
+	It("should report a port that is already in use", func() {
+		l, err := net.Listen("tcp", ":0")
+		Expect(err).NotTo(HaveOccurred())
+		defer l.Close()
+
+		server.Configure(newTestGateway(int32(l.Addr().(*net.TCPAddr).Port)), nil)
+		Expect(server.Sync(context.Background())).To(MatchError(ErrListenFailed))
+		Expect(server.IsRunning()).To(BeFalse())
+		Expect(server.Listeners()[0].Err).To(MatchError(ErrListenFailed))
+	})
+
+	It("should refuse to start with TLS but no certificate", func() {
+		gateway := newTestGateway(0)
+		gateway.Spec.Listeners[0].Protocol = fetchfyv1alpha2.ProtocolHTTPS
+		server.Configure(gateway, nil)
+
+		Expect(server.Sync(context.Background())).To(MatchError(ErrTLSConfig))
+	})
+
+	It("should bind listeners independently", func() {
+		l, err := net.Listen("tcp", ":0")
+		Expect(err).NotTo(HaveOccurred())
+		defer l.Close()
+
+		gateway := newTestGateway(0)
+		gateway.Spec.Listeners = append(gateway.Spec.Listeners, fetchfyv1alpha2.Listener{
+			Name: "taken", Port: int32(l.Addr().(*net.TCPAddr).Port), Protocol: fetchfyv1alpha2.ProtocolHTTP,
+		})
+		server.Configure(gateway, nil)
+
+		err = server.Sync(context.Background())
+		Expect(err).To(MatchError(ErrListenFailed))
+		Expect(err).To(MatchError(ContainSubstring("listener taken")))
+
+		listeners := server.Listeners()
+		Expect(listeners).To(HaveLen(2))
+		Expect(listeners[0].Addr).NotTo(BeEmpty())
+		Expect(listeners[0].Err).NotTo(HaveOccurred())
+		Expect(listeners[1].Addr).To(BeEmpty())
+		Expect(listeners[1].Err).To(MatchError(ErrListenFailed))
+	})
+
+	It("should drain listeners removed from the gateway", func() {
+		gateway := newTestGateway(0)
+		gateway.Spec.Listeners = append(gateway.Spec.Listeners, fetchfyv1alpha2.Listener{
+			Name: "internal", Port: 0, Protocol: fetchfyv1alpha2.ProtocolHTTP,
+		})
+		server.Configure(gateway, nil)
+		Expect(server.Sync(context.Background())).To(Succeed())
+		Expect(server.Addr("internal")).NotTo(BeEmpty())
+
+		gateway.Spec.Listeners = gateway.Spec.Listeners[:1]
+		server.Configure(gateway, nil)
+		Expect(server.NeedsRestart()).To(BeTrue())
+		Expect(server.Sync(context.Background())).To(Succeed())
+		Expect(server.Addr("internal")).To(BeEmpty())
+		Expect(server.Listeners()).To(HaveLen(1))
+		Eventually(server.Draining).Should(BeZero())
+	})
+})
+
+var _ = Describe("Server restart", func() {
+	var (
+		server  *Server
+		gateway *fetchfyv1alpha2.Gateway
+	)
+
+	freePort := func() int32 {
+		l, err := net.Listen("tcp", ":0")
+		Expect(err).NotTo(HaveOccurred())
+		defer l.Close()
+		return int32(l.Addr().(*net.TCPAddr).Port)
+	}
+
+	BeforeEach(func() {
+		server = NewServer(NewRegistry(logr.Discard()), logr.Discard())
+		gateway = newTestGateway(freePort())
+		gateway.Spec.DrainTimeout = &metav1.Duration{Duration: 2 * time.Second}
+		server.Configure(gateway, nil)
+		Expect(server.Sync(context.Background())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(server.Stop(context.Background())).To(Succeed())
+	})
+
+	It("should not need a restart when only auth changes", func() {
+		gateway.Spec.Auth = &fetchfyv1alpha2.GatewayAuth{Type: fetchfyv1alpha2.AuthTypeOAuth2}
+		server.Configure(gateway, nil)
+		Expect(server.NeedsRestart()).To(BeFalse())
+	})
+
+	It("should move to the new port and drain streams on the old one", func() {
+		oldAddr := server.Addr("mcp")
+
+		By("opening an SSE stream on the old listener")
+		req, err := http.NewRequest(http.MethodGet, "http://"+oldAddr+MCPBasePath, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept", "text/event-stream")
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		By("changing the port")
+		newPort := freePort()
+		gateway.Spec.Listeners[0].Port = newPort
+		server.Configure(gateway, nil)
+		Expect(server.NeedsRestart()).To(BeTrue())
+		Expect(server.Sync(context.Background())).To(Succeed())
+		Expect(server.NeedsRestart()).To(BeFalse())
+
+		_, port, err := net.SplitHostPort(server.Addr("mcp"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(port).To(Equal(strconv.Itoa(int(newPort))))
+
+		By("waiting for the stream on the old listener to end")
+		_, err = io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(server.Draining).Should(BeZero())
+
+		resp, err = http.Get("http://" + server.Addr("mcp") + "/")
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should keep serving on the old port if the new one cannot be bound", func() {
+		oldAddr := server.Addr("mcp")
+
+		l, err := net.Listen("tcp", ":0")
+		Expect(err).NotTo(HaveOccurred())
+		defer l.Close()
+
+		gateway.Spec.Listeners[0].Port = int32(l.Addr().(*net.TCPAddr).Port)
+		server.Configure(gateway, nil)
+		Expect(server.Sync(context.Background())).To(MatchError(ErrListenFailed))
+		Expect(server.IsRunning()).To(BeTrue())
+		Expect(server.Addr("mcp")).To(Equal(oldAddr))
+	})
+})
+
+var _ = Describe("Server shutdown", func() {
+	var (
+		server   *Server
+		upstream *httptest.Server
+		release  chan struct{}
+	)
+
+	BeforeEach(func() {
+		release = make(chan struct{})
+		upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Reading the body lets the server notice clients going away
+			_, _ = io.Copy(io.Discard, r.Body)
+			if r.Method == http.MethodGet {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}\n\n")
+				w.(http.Flusher).Flush()
+			}
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			if r.Method == http.MethodPost {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+			}
+		}))
+		target, err := url.Parse(upstream.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		registry := NewRegistry(logr.Discard())
+		_, err = registry.RegisterService(context.Background(), &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "docs",
+				Namespace:   "tools",
+				Annotations: map[string]string{EndpointAnnotation: "/mcp/tools/docs"},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+
+		server = NewServer(registry, logr.Discard())
+		server.Configure(newTestGateway(0), nil)
+		server.SetBackendURLFunc(func(Backend) *url.URL { return target })
+		Expect(server.Sync(context.Background())).To(Succeed())
+	})
+
+	AfterEach(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+		upstream.Close()
+	})
+
+	// send sends a request to the gateway in the background and returns its outcome
+	send := func(method, accept string) (<-chan *http.Response, <-chan error) {
+		responses, errs := make(chan *http.Response, 1), make(chan error, 1)
+		req, err := http.NewRequest(method, "http://"+server.Addr("mcp")+"/mcp/tools/docs",
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept", accept)
+		go func() {
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				errs <- err
+				return
+			}
+			responses <- resp
+		}()
+		return responses, errs
+	}
+
+	It("should end streams with a notice and complete in-flight requests", func() {
+		streams, _ := send(http.MethodGet, "text/event-stream")
+		var stream *http.Response
+		Eventually(streams).Should(Receive(&stream))
+		defer stream.Body.Close()
+		responses, _ := send(http.MethodPost, "application/json")
+		// Let the request reach the backend before the listener drains
+		time.Sleep(100 * time.Millisecond)
+
+		shutdown := make(chan error, 1)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			shutdown <- server.Shutdown(ctx)
+		}()
+
+		By("ending the proxied stream with the draining notice")
+		body, err := io.ReadAll(stream.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring(ToolsListChangedNotification))
+		Expect(string(body)).To(HaveSuffix(string(drainingNotice())))
+
+		By("completing the in-flight request")
+		close(release)
+		var resp *http.Response
+		Eventually(responses).Should(Receive(&resp))
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Eventually(shutdown).Should(Receive(BeNil()))
+
+		Expect(server.Sync(context.Background())).To(MatchError(ErrShuttingDown))
+		Expect(server.IsRunning()).To(BeFalse())
+	})
+
+	It("should close connections that outlive the grace period", func() {
+		_, errs := send(http.MethodPost, "application/json")
+		time.Sleep(100 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		Expect(server.Shutdown(ctx)).To(MatchError(context.DeadlineExceeded))
+		Eventually(errs).Should(Receive())
+	})
+
+	DescribeTable("should refuse new sessions on a draining listener",
+		func(method, accept, sessionID string, code int) {
+			draining := make(chan struct{})
+			close(draining)
+			handler := refuseNewSessions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(method, "http://gw.example.com/mcp/tools/docs", nil)
+			req.Header.Set("Accept", accept)
+			if sessionID != "" {
+				req.Header.Set(SessionIDHeader, sessionID)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), drainingKey{}, (<-chan struct{})(draining)))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			Expect(rec.Code).To(Equal(code))
+		},
+		Entry("a new session", http.MethodPost, "application/json", "", http.StatusServiceUnavailable),
+		Entry("a new stream", http.MethodGet, "text/event-stream", "session-1", http.StatusServiceUnavailable),
+		Entry("a request of a session", http.MethodPost, "application/json", "session-1", http.StatusOK),
+	)
+})
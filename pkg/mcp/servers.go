@@ -0,0 +1,188 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/metrics"
+)
+
+// DefaultShutdownGracePeriod is how long the servers drain when the operator stops
+const DefaultShutdownGracePeriod = 30 * time.Second
+
+// ServerManager holds the MCP server of each gateway. It is safe for concurrent use by
+// reconcilers of different gateways.
+type ServerManager struct {
+	registry *Registry
+	log      logr.Logger
+	servers  map[types.NamespacedName]*Server
+	shutdown bool
+	mutex    sync.RWMutex
+
+	// ShutdownGracePeriod is how long in-flight requests may complete when the operator stops
+	ShutdownGracePeriod time.Duration
+}
+
+// NewServerManager creates a manager of servers that route to the registry's services
+func NewServerManager(registry *Registry, log logr.Logger) *ServerManager {
+	return &ServerManager{
+		registry:            registry,
+		log:                 log,
+		servers:             make(map[types.NamespacedName]*Server),
+		ShutdownGracePeriod: DefaultShutdownGracePeriod,
+	}
+}
+
+// NeedLeaderElection returns false, as every replica serves the gateways. It implements
+// manager.LeaderElectionRunnable.
+func (m *ServerManager) NeedLeaderElection() bool {
+	return false
+}
+
+// Start blocks until the context is done and then shuts all servers down, draining them for
+// up to the grace period. Servers created afterwards don't bind listeners. It implements
+// manager.Runnable.
+func (m *ServerManager) Start(ctx context.Context) error {
+	<-ctx.Done()
+
+	m.mutex.Lock()
+	m.shutdown = true
+	servers := make(map[types.NamespacedName]*Server, len(m.servers))
+	for gateway, server := range m.servers {
+		servers[gateway] = server
+	}
+	m.mutex.Unlock()
+
+	if len(servers) == 0 {
+		return nil
+	}
+	m.log.Info("Draining MCP gateway servers", "servers", len(servers), "gracePeriod", m.ShutdownGracePeriod)
+
+	// The manager's context is already cancelled
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), m.ShutdownGracePeriod)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for gateway, server := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				m.log.Info("MCP gateway server didn't drain in time", "gateway", gateway, "error", err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+	m.log.Info("Drained MCP gateway servers")
+	return nil
+}
+
+// Get returns the server of the gateway
+func (m *ServerManager) Get(gateway types.NamespacedName) (*Server, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	server, exists := m.servers[gateway]
+	return server, exists
+}
+
+// GetOrCreate returns the server of the gateway, creating it if there is none. It reports
+// whether the server was created. Once the servers are shut down, it doesn't create servers
+// and returns nil for gateways without one.
+func (m *ServerManager) GetOrCreate(gateway types.NamespacedName) (*Server, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if server, exists := m.servers[gateway]; exists {
+		return server, false
+	}
+	if m.shutdown {
+		return nil, false
+	}
+	server := NewServer(m.registry, m.log)
+	m.servers[gateway] = server
+	metrics.GatewayCount.Set(float64(len(m.servers)))
+	return server, true
+}
+
+// Remove stops the server of the gateway and forgets it. It returns false if the gateway
+// has no server. The server is stopped without holding the lock, so other gateways aren't
+// blocked while its listeners drain.
+func (m *ServerManager) Remove(ctx context.Context, gateway types.NamespacedName) (bool, error) {
+	m.mutex.Lock()
+	server, exists := m.servers[gateway]
+	delete(m.servers, gateway)
+	metrics.GatewayCount.Set(float64(len(m.servers)))
+	m.mutex.Unlock()
+
+	if !exists {
+		return false, nil
+	}
+	return true, server.Stop(ctx)
+}
+
+// Gateways returns the gateways that have a server, in the order of their names
+func (m *ServerManager) Gateways() []types.NamespacedName {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	gateways := make([]types.NamespacedName, 0, len(m.servers))
+	for gateway := range m.servers {
+		gateways = append(gateways, gateway)
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		return gateways[i].String() < gateways[j].String()
+	})
+	return gateways
+}
+
+// SelectedLabels returns the keys of the Service labels the servers' listeners select services
+// by, in order
+func (m *ServerManager) SelectedLabels() []string {
+	m.mutex.RLock()
+	servers := make([]*Server, 0, len(m.servers))
+	for _, server := range m.servers {
+		servers = append(servers, server)
+	}
+	m.mutex.RUnlock()
+
+	keys := make(map[string]bool)
+	for _, server := range servers {
+		server.selectedLabels(keys)
+	}
+	selected := make([]string, 0, len(keys))
+	for key := range keys {
+		selected = append(selected, key)
+	}
+	sort.Strings(selected)
+	return selected
+}
+
+// Len returns the number of servers
+func (m *ServerManager) Len() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return len(m.servers)
+}
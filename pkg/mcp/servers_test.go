@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("ServerManager", func() {
+	var manager *ServerManager
+
+	BeforeEach(func() {
+		manager = NewServerManager(NewRegistry(logr.Discard()), logr.Discard())
+	})
+
+	It("should create one server per gateway", func() {
+		gateway := types.NamespacedName{Name: "gw", Namespace: "default"}
+		server, created := manager.GetOrCreate(gateway)
+		Expect(created).To(BeTrue())
+		again, created := manager.GetOrCreate(gateway)
+		Expect(created).To(BeFalse())
+		Expect(again).To(BeIdenticalTo(server))
+
+		removed, err := manager.Remove(context.Background(), gateway)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(BeTrue())
+		_, exists := manager.Get(gateway)
+		Expect(exists).To(BeFalse())
+		removed, err = manager.Remove(context.Background(), gateway)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(removed).To(BeFalse())
+	})
+
+	It("should handle concurrent gateway churn", func() {
+		const workers = 8
+		const rounds = 50
+
+		var wg sync.WaitGroup
+		for w := range workers {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := range rounds {
+					gateway := types.NamespacedName{Name: fmt.Sprintf("gw-%d", (w+i)%4), Namespace: "default"}
+					server, _ := manager.GetOrCreate(gateway)
+					server.Configure(newTestGateway(8080), nil)
+					_ = manager.Gateways()
+					if i%3 == 0 {
+						_, err := manager.Remove(context.Background(), gateway)
+						Expect(err).NotTo(HaveOccurred())
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		Expect(manager.Len()).To(Equal(len(manager.Gateways())))
+		for _, gateway := range manager.Gateways() {
+			_, err := manager.Remove(context.Background(), gateway)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(manager.Len()).To(BeZero())
+	})
+
+	It("should drain the servers when the operator stops", func() {
+		gateway := types.NamespacedName{Name: "gw", Namespace: "default"}
+		server, _ := manager.GetOrCreate(gateway)
+		server.Configure(newTestGateway(0), nil)
+		Expect(server.Sync(context.Background())).To(Succeed())
+		Expect(manager.NeedLeaderElection()).To(BeFalse())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- manager.Start(ctx) }()
+		Consistently(done).ShouldNot(Receive())
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(server.IsRunning()).To(BeFalse())
+		Expect(server.Sync(context.Background())).To(MatchError(ErrShuttingDown))
+
+		By("not creating servers afterwards")
+		again, created := manager.GetOrCreate(gateway)
+		Expect(created).To(BeFalse())
+		Expect(again).To(BeIdenticalTo(server))
+		late, created := manager.GetOrCreate(types.NamespacedName{Name: "late", Namespace: "default"})
+		Expect(created).To(BeFalse())
+		Expect(late).To(BeNil())
+	})
+})
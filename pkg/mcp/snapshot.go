@@ -0,0 +1,429 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+const (
+	// snapshotVersion is the format version of persisted registry snapshots
+	snapshotVersion = 1
+
+	// SnapshotConfigMapKey is the ConfigMap key holding the registry snapshot
+	SnapshotConfigMapKey = "snapshot.json"
+
+	// DefaultSnapshotSaveDelay is how long registry changes are collected before the snapshot is saved
+	DefaultSnapshotSaveDelay = 5 * time.Second
+
+	// DefaultStaleTimeout is how long restored services may wait for their Service to be
+	// reconciled before they are dropped
+	DefaultStaleTimeout = 2 * time.Minute
+
+	// snapshotAnnotationPrefix is the prefix of the Service annotations a snapshot keeps
+	snapshotAnnotationPrefix = "mcp.fetchfy.ai/"
+
+	// snapshotShutdownTimeout bounds saving the final snapshot when the operator stops
+	snapshotShutdownTimeout = 5 * time.Second
+)
+
+// RegistrySnapshot is the persisted state of the registry
+type RegistrySnapshot struct {
+	Version  int               `json:"version"`
+	SavedAt  time.Time         `json:"savedAt"`
+	Services []SnapshotService `json:"services"`
+}
+
+// SnapshotService is a registered service in a registry snapshot
+type SnapshotService struct {
+	Service       *corev1.Service                 `json:"service"`
+	Type          ServiceType                     `json:"type"`
+	Endpoint      string                          `json:"endpoint"`
+	Status        ServiceStatus                   `json:"status"`
+	Message       string                          `json:"message,omitempty"`
+	UpdatedAt     time.Time                       `json:"updatedAt"`
+	LastProbe     time.Time                       `json:"lastProbe,omitempty"`
+	ConflictsWith *types.NamespacedName           `json:"conflictsWith,omitempty"`
+	Endpoints     *Endpoints                      `json:"endpoints,omitempty"`
+	Source        fetchfyv1alpha2.DiscoverySource `json:"source,omitempty"`
+	Cluster       *RemoteCluster                  `json:"cluster,omitempty"`
+}
+
+// SnapshotStore loads and saves registry snapshots
+type SnapshotStore interface {
+	// Load returns the saved snapshot, nil if there is none
+	Load(ctx context.Context) (*RegistrySnapshot, error)
+
+	// Save replaces the saved snapshot
+	Save(ctx context.Context, snapshot *RegistrySnapshot) error
+}
+
+// Snapshot returns the registered services in the order of their names. Their Services keep
+// the labels with the given keys, the ones services are selected by.
+func (r *Registry) Snapshot(labelKeys ...string) *RegistrySnapshot {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	snapshot := &RegistrySnapshot{
+		Version:  snapshotVersion,
+		SavedAt:  time.Now(),
+		Services: make([]SnapshotService, 0, len(r.services)),
+	}
+	for _, svc := range r.services {
+		snapshot.Services = append(snapshot.Services, SnapshotService{
+			Service:       snapshotObject(svc, labelKeys),
+			Type:          svc.Type,
+			Endpoint:      svc.Endpoint,
+			Status:        svc.Status,
+			Message:       svc.Message,
+			UpdatedAt:     svc.UpdatedAt,
+			LastProbe:     svc.LastProbe,
+			ConflictsWith: svc.ConflictsWith,
+			Endpoints:     svc.Endpoints,
+			Source:        svc.Source,
+			Cluster:       svc.Cluster,
+		})
+	}
+	sort.Slice(snapshot.Services, func(i, j int) bool {
+		a, b := snapshot.Services[i].Service, snapshot.Services[j].Service
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return snapshot
+}
+
+// snapshotObject returns the parts of a service's Service object needed to route to it: its
+// ports, its mcp.fetchfy.ai annotations and the labels with the given keys. Other labels and
+// annotations may hold anything, e.g. a kubectl last-applied-configuration.
+func snapshotObject(svc *MCPService, labelKeys []string) *corev1.Service {
+	obj := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: svc.Name, Namespace: svc.Namespace}}
+	if svc.Service == nil {
+		return obj
+	}
+	obj.UID = svc.Service.UID
+	obj.ResourceVersion = svc.Service.ResourceVersion
+	obj.CreationTimestamp = svc.Service.CreationTimestamp
+	for _, key := range labelKeys {
+		if value, ok := svc.Service.Labels[key]; ok {
+			if obj.Labels == nil {
+				obj.Labels = make(map[string]string)
+			}
+			obj.Labels[key] = value
+		}
+	}
+	for key, value := range svc.Service.Annotations {
+		if strings.HasPrefix(key, snapshotAnnotationPrefix) {
+			if obj.Annotations == nil {
+				obj.Annotations = make(map[string]string)
+			}
+			obj.Annotations[key] = value
+		}
+	}
+	obj.Spec = corev1.ServiceSpec{
+		Type:         svc.Service.Spec.Type,
+		ClusterIP:    svc.Service.Spec.ClusterIP,
+		ExternalName: svc.Service.Spec.ExternalName,
+		Ports:        svc.Service.Spec.Ports,
+	}
+	return obj
+}
+
+// Restore registers the services of a snapshot that aren't registered yet. They are marked
+// stale until their Service is registered again. It returns the number of restored services.
+func (r *Registry) Restore(snapshot *RegistrySnapshot) int {
+	r.mutex.Lock()
+	defer r.deliver()
+	defer r.mutex.Unlock()
+
+	before := r.snapshot()
+	restored := 0
+	for _, entry := range snapshot.Services {
+		if entry.Service == nil {
+			continue
+		}
+		key := types.NamespacedName{Name: entry.Service.Name, Namespace: entry.Service.Namespace}
+		if entry.Cluster != nil {
+			key.Name = ClusterServiceName(entry.Cluster.Name, key.Name)
+		}
+		if _, exists := r.services[key]; exists {
+			continue
+		}
+		restoredService := &MCPService{
+			Name:          key.Name,
+			Namespace:     key.Namespace,
+			Type:          entry.Type,
+			Endpoint:      entry.Endpoint,
+			Status:        entry.Status,
+			Service:       entry.Service.DeepCopy(),
+			UpdatedAt:     entry.UpdatedAt,
+			Message:       entry.Message,
+			LastProbe:     entry.LastProbe,
+			ConflictsWith: entry.ConflictsWith,
+			Endpoints:     entry.Endpoints,
+			Source:        entry.Source,
+			Cluster:       entry.Cluster,
+			Stale:         true,
+		}
+		restoredService.Target, _, _ = serviceStatus(restoredService)
+		r.services[key] = restoredService
+		restored++
+	}
+	if restored > 0 {
+		r.log.Info("Restored MCP services from snapshot", "services", restored, "savedAt", snapshot.SavedAt)
+		r.resolveConflicts()
+		r.recordChanges(before)
+	}
+	return restored
+}
+
+// DropStale deregisters the restored services that weren't registered again. It returns their names.
+func (r *Registry) DropStale() []types.NamespacedName {
+	r.mutex.Lock()
+	defer r.deliver()
+	defer r.mutex.Unlock()
+
+	before := r.snapshot()
+	var dropped []types.NamespacedName
+	for name, svc := range r.services {
+		if svc.Stale {
+			delete(r.services, name)
+			dropped = append(dropped, name)
+			r.log.Info("Dropped stale MCP service", "name", name.Name, "namespace", name.Namespace)
+		}
+	}
+	if len(dropped) > 0 {
+		r.resolveConflicts()
+		r.recordChanges(before)
+	}
+	return dropped
+}
+
+// FileSnapshotStore keeps the registry snapshot in a local file
+type FileSnapshotStore struct {
+	Path string
+}
+
+// Load implements SnapshotStore
+func (f *FileSnapshotStore) Load(_ context.Context) (*RegistrySnapshot, error) {
+	data, err := os.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSnapshot(data)
+}
+
+// Save implements SnapshotStore. The file is replaced atomically.
+func (f *FileSnapshotStore) Save(_ context.Context, snapshot *RegistrySnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// ConfigMapSnapshotStore keeps the registry snapshot in a ConfigMap. Reader should read from
+// the API server, since the ConfigMap is loaded before the manager's cache is started.
+type ConfigMapSnapshotStore struct {
+	Reader client.Reader
+	Writer client.Writer
+	Name   types.NamespacedName
+}
+
+// Load implements SnapshotStore
+func (c *ConfigMapSnapshotStore) Load(ctx context.Context) (*RegistrySnapshot, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := c.Reader.Get(ctx, c.Name, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	data, ok := configMap.Data[SnapshotConfigMapKey]
+	if !ok {
+		return nil, nil
+	}
+	return decodeSnapshot([]byte(data))
+}
+
+// Save implements SnapshotStore
+func (c *ConfigMapSnapshotStore) Save(ctx context.Context, snapshot *RegistrySnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: c.Name.Name, Namespace: c.Name.Namespace},
+		Data:       map[string]string{SnapshotConfigMapKey: string(data)},
+	}
+	err = c.Writer.Update(ctx, configMap)
+	if apierrors.IsNotFound(err) {
+		err = c.Writer.Create(ctx, configMap)
+	}
+	return err
+}
+
+// decodeSnapshot parses a saved snapshot
+func decodeSnapshot(data []byte) (*RegistrySnapshot, error) {
+	snapshot := &RegistrySnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("invalid registry snapshot: %w", err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported registry snapshot version %d", snapshot.Version)
+	}
+	return snapshot, nil
+}
+
+// SnapshotPersister restores the registry from a snapshot on startup and saves the
+// registry whenever it changes
+type SnapshotPersister struct {
+	registry *Registry
+	store    SnapshotStore
+	log      logr.Logger
+
+	// SaveDelay is how long changes are collected before the snapshot is saved
+	SaveDelay time.Duration
+
+	// StaleTimeout is how long restored services may stay stale before they are dropped
+	StaleTimeout time.Duration
+
+	// SelectedLabels, if set, returns the keys of the Service labels services are selected by,
+	// which the snapshot keeps. Without it, the snapshot keeps no labels.
+	SelectedLabels func() []string
+
+	// Elected, if set, holds saving back until it is closed, so that replicas sharing the
+	// store leave it to the leader
+	Elected <-chan struct{}
+}
+
+// NewSnapshotPersister creates a persister of the registry in the store
+func NewSnapshotPersister(registry *Registry, store SnapshotStore, log logr.Logger) *SnapshotPersister {
+	return &SnapshotPersister{
+		registry:     registry,
+		store:        store,
+		log:          log.WithName("mcp-snapshot"),
+		SaveDelay:    DefaultSnapshotSaveDelay,
+		StaleTimeout: DefaultStaleTimeout,
+	}
+}
+
+// Restore loads the saved snapshot into the registry. It returns the number of restored services.
+func (p *SnapshotPersister) Restore(ctx context.Context) (int, error) {
+	snapshot, err := p.store.Load(ctx)
+	if err != nil || snapshot == nil {
+		return 0, err
+	}
+	return p.registry.Restore(snapshot), nil
+}
+
+// NeedLeaderElection returns false, as restored services are dropped on every replica. Saving
+// waits for Elected. It implements manager.LeaderElectionRunnable.
+func (p *SnapshotPersister) NeedLeaderElection() bool {
+	return false
+}
+
+// Start saves the registry after changes until the context is cancelled, and once more
+// when it is. Restored services that are still stale after the stale timeout are dropped.
+// It implements manager.Runnable.
+func (p *SnapshotPersister) Start(ctx context.Context) error {
+	_, events := p.registry.Watch(ctx)
+	staleTimer := time.NewTimer(p.StaleTimeout)
+	defer staleTimer.Stop()
+
+	elected := p.Elected
+	saving := elected == nil
+	var save <-chan time.Time
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				if !saving {
+					return nil
+				}
+				// Save with a fresh context, the manager's is already cancelled
+				saveCtx, cancel := context.WithTimeout(context.Background(), snapshotShutdownTimeout)
+				defer cancel()
+				p.save(saveCtx)
+				return nil
+			}
+			if save == nil && saving {
+				save = time.After(p.SaveDelay)
+			}
+		case <-elected:
+			// Save the registry of the new leader, which may have changed since the last save
+			elected = nil
+			saving = true
+			if save == nil {
+				save = time.After(p.SaveDelay)
+			}
+		case <-save:
+			save = nil
+			p.save(ctx)
+		case <-staleTimer.C:
+			if dropped := p.registry.DropStale(); len(dropped) > 0 {
+				p.log.Info("Dropped restored services whose Service wasn't found", "services", len(dropped))
+			}
+		}
+	}
+}
+
+// save writes the current registry to the store
+func (p *SnapshotPersister) save(ctx context.Context) {
+	var labelKeys []string
+	if p.SelectedLabels != nil {
+		labelKeys = p.SelectedLabels()
+	}
+	snapshot := p.registry.Snapshot(labelKeys...)
+	if err := p.store.Save(ctx, snapshot); err != nil {
+		p.log.Error(err, "Failed to save registry snapshot")
+		return
+	}
+	p.log.V(1).Info("Saved registry snapshot", "services", len(snapshot.Services))
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+)
+
+var _ = Describe("Registry snapshots", func() {
+	var registry *Registry
+
+	newService := func(namespace, name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       namespace,
+				UID:             types.UID(namespace + "-" + name),
+				ResourceVersion: "1",
+				Labels:          map[string]string{"app": name, "team": "platform"},
+				Annotations: map[string]string{
+					"mcp.fetchfy.ai/version":                           "1.2.0",
+					"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 8080}}},
+		}
+	}
+	weather := types.NamespacedName{Name: "weather", Namespace: "tools"}
+
+	BeforeEach(func() {
+		registry = NewRegistry(logr.Discard())
+		_, err := registry.RegisterService(context.Background(), newService("tools", "weather"), ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = registry.RegisterService(context.Background(), newService("agents", "planner"), ServiceTypeAgent)
+		Expect(err).NotTo(HaveOccurred())
+		registry.SetServiceHealth(weather, errors.New("connection refused"))
+	})
+
+	It("should restore services as stale until they are registered again", func() {
+		store := &FileSnapshotStore{Path: filepath.Join(GinkgoT().TempDir(), "registry.json")}
+		Expect(store.Save(context.Background(), registry.Snapshot())).To(Succeed())
+
+		restarted := NewRegistry(logr.Discard())
+		persister := NewSnapshotPersister(restarted, store, logr.Discard())
+		Expect(persister.Restore(context.Background())).To(Equal(2))
+
+		svc, ok := restarted.GetService(weather)
+		Expect(ok).To(BeTrue())
+		Expect(svc.Stale).To(BeTrue())
+		Expect(svc.Type).To(Equal(ServiceTypeTool))
+		Expect(svc.Status).To(Equal(ServiceStatusUnavailable))
+		Expect(svc.Message).To(Equal("connection refused"))
+		Expect(svc.Service.Annotations).To(HaveKeyWithValue("mcp.fetchfy.ai/version", "1.2.0"))
+		Expect(svc.Service.Spec.Ports).To(HaveLen(1))
+		routed, ok := restarted.ServiceForPath("/mcp/tools/weather/")
+		Expect(ok).To(BeTrue())
+		Expect(routed.Name).To(Equal("weather"))
+
+		By("keeping the restored health when the Service is registered again")
+		_, err := restarted.RegisterService(context.Background(), newService("tools", "weather"), ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		svc, _ = restarted.GetService(weather)
+		Expect(svc.Stale).To(BeFalse())
+		Expect(svc.Status).To(Equal(ServiceStatusUnavailable))
+
+		By("dropping services whose Service wasn't registered again")
+		Expect(restarted.DropStale()).To(ConsistOf(types.NamespacedName{Name: "planner", Namespace: "agents"}))
+		Expect(restarted.ListServices()).To(HaveLen(1))
+	})
+
+	It("should not replace services that are already registered", func() {
+		snapshot := registry.Snapshot()
+		Expect(registry.Restore(snapshot)).To(BeZero())
+		svc, _ := registry.GetService(weather)
+		Expect(svc.Stale).To(BeFalse())
+	})
+
+	It("should keep only the labels and annotations services are routed by", func() {
+		servers := NewServerManager(registry, logr.Discard())
+		server, _ := servers.GetOrCreate(types.NamespacedName{Name: "gw", Namespace: "default"})
+		gateway := newTestGateway(0)
+		gateway.Spec.Listeners[0].Services = &fetchfyv1alpha2.ListenerServices{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}},
+		}
+		server.Configure(gateway, nil)
+		Expect(servers.SelectedLabels()).To(Equal([]string{"team"}))
+
+		snapshot := registry.Snapshot(servers.SelectedLabels()...)
+		Expect(snapshot.Services).To(HaveLen(2))
+		for _, saved := range snapshot.Services {
+			Expect(saved.Service.Labels).To(Equal(map[string]string{"team": "platform"}))
+			Expect(saved.Service.Annotations).To(Equal(map[string]string{"mcp.fetchfy.ai/version": "1.2.0"}))
+		}
+
+		restarted := NewRegistry(logr.Discard())
+		Expect(restarted.Restore(snapshot)).To(Equal(2))
+		svc, _ := restarted.GetService(weather)
+		Expect(server.settings["mcp"].filter.matches(svc)).To(BeTrue())
+	})
+
+	It("should load nothing when no snapshot was saved", func() {
+		store := &FileSnapshotStore{Path: filepath.Join(GinkgoT().TempDir(), "registry.json")}
+		Expect(store.Load(context.Background())).To(BeNil())
+
+		configMaps := &ConfigMapSnapshotStore{Name: types.NamespacedName{Name: "snapshot", Namespace: "fetchfy-system"}}
+		configMaps.Reader = fake.NewClientBuilder().Build()
+		Expect(configMaps.Load(context.Background())).To(BeNil())
+	})
+
+	It("should save the snapshot in a ConfigMap", func() {
+		c := fake.NewClientBuilder().Build()
+		store := &ConfigMapSnapshotStore{
+			Reader: c,
+			Writer: c,
+			Name:   types.NamespacedName{Name: "snapshot", Namespace: "fetchfy-system"},
+		}
+		Expect(store.Save(context.Background(), registry.Snapshot())).To(Succeed())
+		Expect(registry.DeregisterService(context.Background(), weather)).To(BeTrue())
+		Expect(store.Save(context.Background(), registry.Snapshot())).To(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(context.Background(), store.Name, configMap)).To(Succeed())
+		Expect(configMap.Data).To(HaveKey(SnapshotConfigMapKey))
+
+		snapshot, err := store.Load(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.Services).To(HaveLen(1))
+		Expect(snapshot.Services[0].Service.Name).To(Equal("planner"))
+	})
+
+	It("should save the registry after changes and when stopped", func() {
+		store := &FileSnapshotStore{Path: filepath.Join(GinkgoT().TempDir(), "registry.json")}
+		persister := NewSnapshotPersister(registry, store, logr.Discard())
+		persister.SaveDelay = 10 * time.Millisecond
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(persister.Start(ctx)).To(Succeed())
+		}()
+
+		saved := func() []string {
+			snapshot, err := store.Load(context.Background())
+			if err != nil || snapshot == nil {
+				return nil
+			}
+			var names []string
+			for _, entry := range snapshot.Services {
+				names = append(names, entry.Service.Name)
+			}
+			return names
+		}
+
+		Eventually(func() int {
+			registry.mutex.RLock()
+			defer registry.mutex.RUnlock()
+			return len(registry.watchers)
+		}).Should(Equal(1))
+
+		Expect(registry.DeregisterService(context.Background(), weather)).To(BeTrue())
+		Eventually(saved).Should(Equal([]string{"planner"}))
+
+		_, err := registry.RegisterService(context.Background(), newService("tools", "search"), ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		cancel()
+		Eventually(done).Should(BeClosed())
+		Expect(saved()).To(Equal([]string{"planner", "search"}))
+	})
+
+	It("should only save the snapshot once elected", func() {
+		store := &FileSnapshotStore{Path: filepath.Join(GinkgoT().TempDir(), "registry.json")}
+		persister := NewSnapshotPersister(registry, store, logr.Discard())
+		persister.SaveDelay = 10 * time.Millisecond
+		elected := make(chan struct{})
+		persister.Elected = elected
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(persister.Start(ctx)).To(Succeed())
+		}()
+
+		Eventually(func() int {
+			registry.mutex.RLock()
+			defer registry.mutex.RUnlock()
+			return len(registry.watchers)
+		}).Should(Equal(1))
+		Expect(registry.DeregisterService(context.Background(), weather)).To(BeTrue())
+		Consistently(func() (*RegistrySnapshot, error) {
+			return store.Load(context.Background())
+		}, 100*time.Millisecond).Should(BeNil())
+
+		close(elected)
+		Eventually(func() (*RegistrySnapshot, error) {
+			return store.Load(context.Background())
+		}).ShouldNot(BeNil())
+	})
+})
@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// streamKeepAliveInterval is how often an idle SSE stream receives a keep-alive comment
+const streamKeepAliveInterval = 15 * time.Second
+
+// isStreamRequest returns true if the client asks to open an SSE stream
+func isStreamRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// handleStream serves a server-to-client SSE stream carrying the list_changed
+// notifications of the services the listener exposes. The stream ends when the client
+// disconnects or the listener serving it starts draining, so the client reconnects
+// to the current listener or another replica.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sub, unsubscribe := s.notifications.subscribe(s.listenerSettingsFor(r).filter)
+	defer unsubscribe()
+
+	ticker := time.NewTicker(streamKeepAliveInterval)
+	defer ticker.Stop()
+
+	draining := drainingFrom(r.Context())
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-draining:
+			s.log.V(1).Info("Closing SSE stream of draining listener", "path", r.URL.Path)
+			if _, err := w.Write(drainingNotice()); err == nil {
+				flusher.Flush()
+			}
+			return
+		case msg := <-sub.messages:
+			if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
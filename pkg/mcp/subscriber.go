@@ -0,0 +1,276 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultSubscriberResyncInterval is how often the subscriber picks up registered and removed services
+	DefaultSubscriberResyncInterval = 10 * time.Second
+
+	// subscriberMaxBackoff bounds the wait before reconnecting to a backend's notification stream
+	subscriberMaxBackoff = time.Minute
+
+	// subscriberUnsupportedRetry is the wait before retrying a backend that doesn't offer a notification stream
+	subscriberUnsupportedRetry = 10 * time.Minute
+
+	// protocolVersion is the MCP protocol version the gateway announces to backends
+	protocolVersion = "2025-03-26"
+)
+
+// errStreamUnsupported means the backend doesn't offer a server-to-client SSE stream
+var errStreamUnsupported = errors.New("backend doesn't support notification streams")
+
+// NotificationSubscriber opens an MCP session with every available registered service
+// and listens on its notification stream, passing list_changed notifications to the
+// registry, which forwards them to the gateways' clients.
+type NotificationSubscriber struct {
+	registry *Registry
+	log      logr.Logger
+
+	// Interval is how often registered and removed services are picked up
+	Interval time.Duration
+
+	// BackendURL returns the base URL of a backend, ClusterBackendURL by default
+	BackendURL BackendURLFunc
+
+	// Client sends the requests to the backends
+	Client *http.Client
+}
+
+// NewNotificationSubscriber creates a subscriber for the services of the registry
+func NewNotificationSubscriber(registry *Registry, log logr.Logger) *NotificationSubscriber {
+	return &NotificationSubscriber{
+		registry:   registry,
+		log:        log.WithName("mcp-subscriber"),
+		Interval:   DefaultSubscriberResyncInterval,
+		BackendURL: ClusterBackendURL,
+		Client:     &http.Client{},
+	}
+}
+
+// subscription is a running subscription to a service's notification stream
+type subscription struct {
+	version string
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NeedLeaderElection returns false, as clients may be connected to any replica. It implements
+// manager.LeaderElectionRunnable.
+func (n *NotificationSubscriber) NeedLeaderElection() bool {
+	return false
+}
+
+// Start runs the subscriber until the context is cancelled. It implements manager.Runnable.
+func (n *NotificationSubscriber) Start(ctx context.Context) error {
+	running := make(map[types.NamespacedName]*subscription)
+	defer func() {
+		for _, sub := range running {
+			sub.cancel()
+			<-sub.done
+		}
+	}()
+
+	ticker := time.NewTicker(n.Interval)
+	defer ticker.Stop()
+
+	for {
+		n.resync(ctx, running)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// resync starts subscriptions for new or changed available services and stops the others
+func (n *NotificationSubscriber) resync(ctx context.Context, running map[types.NamespacedName]*subscription) {
+	desired := make(map[types.NamespacedName]*MCPService)
+	for _, svc := range n.registry.ListServices() {
+		if svc.Status == ServiceStatusAvailable {
+			desired[types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}] = svc
+		}
+	}
+
+	for name, sub := range running {
+		if svc, ok := desired[name]; !ok || cacheVersion(svc) != sub.version {
+			sub.cancel()
+			<-sub.done
+			delete(running, name)
+		}
+	}
+
+	for name, svc := range desired {
+		if _, ok := running[name]; ok {
+			continue
+		}
+		backend, ok := serviceBackend(svc)
+		if !ok {
+			continue
+		}
+		subCtx, cancel := context.WithCancel(ctx)
+		sub := &subscription{version: cacheVersion(svc), cancel: cancel, done: make(chan struct{})}
+		running[name] = sub
+
+		endpoint := *n.BackendURL(backend)
+		endpoint.Path = svc.Endpoint
+		if svc.Target.BasePath != "" {
+			endpoint.Path = svc.Target.BasePath
+		}
+		go func() {
+			defer close(sub.done)
+			n.run(subCtx, name, endpoint.String())
+		}()
+	}
+}
+
+// run keeps a subscription to the service's notification stream open until the context is cancelled
+func (n *NotificationSubscriber) run(ctx context.Context, name types.NamespacedName, endpoint string) {
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := n.subscribe(ctx, name, endpoint)
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := backoff
+		switch {
+		case errors.Is(err, errStreamUnsupported):
+			n.log.V(1).Info("MCP service offers no notification stream", "service", name.String())
+			wait = subscriberUnsupportedRetry
+		case time.Since(start) > subscriberMaxBackoff:
+			// The stream was up for a while, reconnect right away
+			backoff = time.Second
+			wait = 0
+		default:
+			n.log.V(1).Info("MCP notification stream failed", "service", name.String(), "error", err.Error())
+			backoff = min(backoff*2, subscriberMaxBackoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// subscribe initializes an MCP session with the backend and reads its notification
+// stream until the stream or the context ends
+func (n *NotificationSubscriber) subscribe(ctx context.Context, name types.NamespacedName, endpoint string) error {
+	initialize := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{`+
+		`"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":"fetchfy-gateway","version":"1.0"}}}`,
+		protocolVersion)
+	resp, err := n.post(ctx, endpoint, "", initialize)
+	if err != nil {
+		return err
+	}
+	sessionID := resp.Header.Get(SessionIDHeader)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("initialize returned %s", resp.Status)
+	}
+	if sessionID != "" {
+		defer n.endSession(endpoint, sessionID)
+	}
+
+	resp, err = n.post(ctx, endpoint, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if sessionID != "" {
+		req.Header.Set(SessionIDHeader, sessionID)
+	}
+	resp, err = n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusMethodNotAllowed ||
+		(resp.StatusCode == http.StatusOK && !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")) {
+		return errStreamUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("notification stream returned %s", resp.Status)
+	}
+
+	n.log.V(1).Info("Subscribed to MCP notifications", "service", name.String())
+	scanner := &notificationScanner{
+		ReadCloser: resp.Body,
+		notify: func(msg *rpcMessage) {
+			if listChangedMethods[msg.Method] != nil {
+				n.registry.NotifyListChanged(name, msg.Method)
+			}
+		},
+	}
+	_, err = io.Copy(io.Discard, scanner)
+	return err
+}
+
+// post sends a JSON-RPC message to the backend
+func (n *NotificationSubscriber) post(ctx context.Context, endpoint, sessionID, body string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader([]byte(body)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set(SessionIDHeader, sessionID)
+	}
+	return n.Client.Do(req)
+}
+
+// endSession terminates the gateway's MCP session with the backend
+func (n *NotificationSubscriber) endSession(endpoint, sessionID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set(SessionIDHeader, sessionID)
+	if resp, err := n.Client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
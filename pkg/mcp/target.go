@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PortAnnotation selects the Service port MCP requests are sent to, by name or number
+	PortAnnotation = "mcp.fetchfy.ai/port"
+
+	// SchemeAnnotation sets the scheme MCP requests are sent with, "http" or "https"
+	SchemeAnnotation = "mcp.fetchfy.ai/scheme"
+
+	// BasePathAnnotation sets the path the backend serves MCP on. It replaces the service's
+	// endpoint in forwarded requests; without it requests keep their path.
+	BasePathAnnotation = "mcp.fetchfy.ai/base-path"
+
+	// MCPPortName is the name of the port used when a Service has several ports and no port annotation
+	MCPPortName = "mcp"
+)
+
+// ServiceTarget is where the MCP requests of a registered service are sent
+type ServiceTarget struct {
+	Scheme   string
+	Host     string
+	Port     int32
+	BasePath string
+}
+
+// URL returns the base URL of the target, without its base path
+func (t ServiceTarget) URL() *url.URL {
+	return &url.URL{Scheme: t.Scheme, Host: net.JoinHostPort(t.Host, strconv.Itoa(int(t.Port)))}
+}
+
+// IsHeadless reports whether a Service has no cluster IP, so its DNS name resolves to its pods
+func IsHeadless(svc *corev1.Service) bool {
+	return svc.Spec.Type != corev1.ServiceTypeExternalName && svc.Spec.ClusterIP == corev1.ClusterIPNone
+}
+
+// ResolveTarget returns where the MCP requests of a Service are sent. ExternalName Services are
+// reached through their external DNS name, headless Services directly on their pods' target
+// port, and all others through their cluster IP. The endpoints resolve named target ports of
+// headless Services and may be nil. An error explains why the Service can't be routed to.
+func ResolveTarget(svc *corev1.Service, endpoints *Endpoints) (ServiceTarget, error) {
+	target := ServiceTarget{
+		Scheme: "http",
+		Host:   fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace),
+	}
+
+	if scheme, ok := svc.Annotations[SchemeAnnotation]; ok {
+		if scheme != "http" && scheme != "https" {
+			return ServiceTarget{}, fmt.Errorf("%s %q is not supported, must be \"http\" or \"https\"",
+				SchemeAnnotation, scheme)
+		}
+		target.Scheme = scheme
+	}
+
+	if basePath, ok := svc.Annotations[BasePathAnnotation]; ok {
+		if !strings.HasPrefix(basePath, "/") || strings.ContainsAny(basePath, "?# \t\n") {
+			return ServiceTarget{}, fmt.Errorf("%s %q must be an absolute path without query or fragment",
+				BasePathAnnotation, basePath)
+		}
+		target.BasePath = basePath
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		if svc.Spec.ExternalName == "" {
+			return ServiceTarget{}, errors.New("service of type ExternalName has no externalName")
+		}
+		target.Host = svc.Spec.ExternalName
+		// ExternalName Services need no ports, the scheme's default port is used then
+		if len(svc.Spec.Ports) == 0 {
+			if _, ok := svc.Annotations[PortAnnotation]; !ok {
+				target.Port = defaultPort(target.Scheme)
+				return target, nil
+			}
+		}
+	}
+
+	port, err := ServicePort(svc)
+	if err != nil {
+		return ServiceTarget{}, err
+	}
+	target.Port = port.Port
+
+	if IsHeadless(svc) {
+		// Headless Services aren't load balanced, so requests go to the pods' port
+		switch {
+		case port.TargetPort.IntVal != 0:
+			target.Port = port.TargetPort.IntVal
+		case port.TargetPort.StrVal == "":
+		case endpoints != nil && endpoints.Port != 0:
+			target.Port = endpoints.Port
+		default:
+			return ServiceTarget{}, fmt.Errorf("named target port %q of headless Service port %s isn't resolved "+
+				"by any endpoint yet", port.TargetPort.StrVal, describeServicePort(port))
+		}
+	}
+
+	return target, nil
+}
+
+// ServicePort returns the Service port MCP requests are sent to. It is selected by the port
+// annotation; without it, a Service with several ports needs a port named "mcp".
+func ServicePort(svc *corev1.Service) (*corev1.ServicePort, error) {
+	ports := svc.Spec.Ports
+	if selector, ok := svc.Annotations[PortAnnotation]; ok {
+		number, numErr := strconv.Atoi(selector)
+		for i := range ports {
+			if ports[i].Name == selector || (numErr == nil && int(ports[i].Port) == number) {
+				return &ports[i], nil
+			}
+		}
+		return nil, fmt.Errorf("%s %q matches no port of the Service", PortAnnotation, selector)
+	}
+
+	switch len(ports) {
+	case 0:
+		return nil, errors.New("service has no ports")
+	case 1:
+		return &ports[0], nil
+	}
+	for i := range ports {
+		if ports[i].Name == MCPPortName {
+			return &ports[i], nil
+		}
+	}
+	return nil, fmt.Errorf("service has %d ports, name one %q or select one with the %s annotation",
+		len(ports), MCPPortName, PortAnnotation)
+}
+
+// describeServicePort returns the name of a Service port, or its number if it has no name
+func describeServicePort(port *corev1.ServicePort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return strconv.Itoa(int(port.Port))
+}
+
+// defaultPort returns the default port of a scheme
+func defaultPort(scheme string) int32 {
+	if scheme == "https" {
+		return 443
+	}
+	return 80
+}
+
+// withBasePath returns the request with the service's endpoint replaced by the base path of
+// its target, or the request itself if the target has no base path
+func withBasePath(r *http.Request, svc *MCPService) *http.Request {
+	if svc.Target.BasePath == "" {
+		return r
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(svc.Endpoint, "/"))
+	rewritten := r.Clone(r.Context())
+	rewritten.URL.Path = strings.TrimSuffix(svc.Target.BasePath, "/") + rest
+	if rewritten.URL.Path == "" {
+		rewritten.URL.Path = "/"
+	}
+	rewritten.URL.RawPath = ""
+	return rewritten
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Describe("ResolveTarget", func() {
+	newService := func(annotations map[string]string, spec corev1.ServiceSpec) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "tools", Annotations: annotations},
+			Spec:       spec,
+		}
+	}
+	twoPorts := []corev1.ServicePort{{Name: "http", Port: 80}, {Name: "metrics", Port: 9090}}
+
+	DescribeTable("should resolve the target of a Service",
+		func(svc *corev1.Service, endpoints *Endpoints, expected ServiceTarget) {
+			target, err := ResolveTarget(svc, endpoints)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target).To(Equal(expected))
+		},
+		Entry("with a single port",
+			newService(nil, corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}}), nil,
+			ServiceTarget{Scheme: "http", Host: "weather.tools.svc", Port: 8080}),
+		Entry("with a port named mcp",
+			newService(nil, corev1.ServiceSpec{Ports: append(twoPorts, corev1.ServicePort{Name: "mcp", Port: 3000})}),
+			nil, ServiceTarget{Scheme: "http", Host: "weather.tools.svc", Port: 3000}),
+		Entry("with a port selected by name and scheme and base path annotations",
+			newService(map[string]string{
+				PortAnnotation: "metrics", SchemeAnnotation: "https", BasePathAnnotation: "/v1/mcp",
+			}, corev1.ServiceSpec{Ports: twoPorts}), nil,
+			ServiceTarget{Scheme: "https", Host: "weather.tools.svc", Port: 9090, BasePath: "/v1/mcp"}),
+		Entry("with a port selected by number",
+			newService(map[string]string{PortAnnotation: "80"}, corev1.ServiceSpec{Ports: twoPorts}), nil,
+			ServiceTarget{Scheme: "http", Host: "weather.tools.svc", Port: 80}),
+		Entry("of an ExternalName Service without ports",
+			newService(map[string]string{SchemeAnnotation: "https"}, corev1.ServiceSpec{
+				Type: corev1.ServiceTypeExternalName, ExternalName: "mcp.example.com",
+			}), nil,
+			ServiceTarget{Scheme: "https", Host: "mcp.example.com", Port: 443}),
+		Entry("of a headless Service on its target port",
+			newService(nil, corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone, Ports: []corev1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromInt32(8080)},
+			}}), nil,
+			ServiceTarget{Scheme: "http", Host: "weather.tools.svc", Port: 8080}),
+		Entry("of a headless Service with a named target port resolved by its endpoints",
+			newService(nil, corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone, Ports: []corev1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromString("http")},
+			}}), &Endpoints{Ready: 1, Total: 1, Port: 8081},
+			ServiceTarget{Scheme: "http", Host: "weather.tools.svc", Port: 8081}),
+	)
+
+	DescribeTable("should explain why a Service can't be routed to",
+		func(svc *corev1.Service, message string) {
+			_, err := ResolveTarget(svc, nil)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("without ports", newService(nil, corev1.ServiceSpec{}), "service has no ports"),
+		Entry("with several ports", newService(nil, corev1.ServiceSpec{Ports: twoPorts}),
+			`service has 2 ports, name one "mcp" or select one with the mcp.fetchfy.ai/port annotation`),
+		Entry("with an unknown port", newService(map[string]string{PortAnnotation: "grpc"},
+			corev1.ServiceSpec{Ports: twoPorts}), `mcp.fetchfy.ai/port "grpc" matches no port of the Service`),
+		Entry("with an unsupported scheme", newService(map[string]string{SchemeAnnotation: "grpc"},
+			corev1.ServiceSpec{Ports: twoPorts}), `mcp.fetchfy.ai/scheme "grpc" is not supported`),
+		Entry("with a relative base path", newService(map[string]string{BasePathAnnotation: "mcp"},
+			corev1.ServiceSpec{Ports: twoPorts}), `mcp.fetchfy.ai/base-path "mcp" must be an absolute path`),
+		Entry("with an unresolved named target port", newService(nil, corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports:     []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromString("http")}},
+		}), `named target port "http" of headless Service port 80 isn't resolved`),
+	)
+
+	It("should replace the endpoint with the base path in forwarded requests", func() {
+		svc := &MCPService{Endpoint: "/mcp/tools/weather", Target: ServiceTarget{BasePath: "/mcp"}}
+
+		r := httptest.NewRequest("POST", "/mcp/tools/weather/messages?session=1", nil)
+		Expect(withBasePath(r, svc).URL.Path).To(Equal("/mcp/messages"))
+		Expect(withBasePath(r, svc).URL.RawQuery).To(Equal("session=1"))
+		Expect(r.URL.Path).To(Equal("/mcp/tools/weather/messages"))
+
+		r = httptest.NewRequest("POST", "/mcp/tools/weather", nil)
+		Expect(withBasePath(r, svc).URL.Path).To(Equal("/mcp"))
+
+		svc.Target.BasePath = ""
+		Expect(withBasePath(r, svc)).To(BeIdenticalTo(r))
+	})
+})
@@ -0,0 +1,442 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksRefreshInterval is how long the signing keys of an authorization server are cached
+	jwksRefreshInterval = 10 * time.Minute
+
+	// jwksMinRefreshInterval rate-limits the key refreshes caused by tokens signed with unknown
+	// keys, and is how long a failed fetch is retried no sooner than
+	jwksMinRefreshInterval = 30 * time.Second
+
+	// jwksFetchTimeout bounds the fetch of an authorization server's metadata and keys
+	jwksFetchTimeout = 10 * time.Second
+
+	// tokenLeeway is the clock skew tolerated when checking the validity period of tokens
+	tokenLeeway = time.Minute
+)
+
+// ErrInvalidToken is returned for bearer tokens that aren't valid access tokens of the
+// gateway's authorization servers
+var ErrInvalidToken = errors.New("invalid bearer token")
+
+// tokenHeader is the JOSE header of a JWT access token
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// tokenClaims are the claims of a JWT access token the gateway checks
+type tokenClaims struct {
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	Expiry    *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+// audience is the aud claim, a single string or an array of strings
+type audience []string
+
+// UnmarshalJSON accepts both forms of the aud claim
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// contains reports whether the audience includes the resource, ignoring a trailing slash
+func (a audience) contains(resource string) bool {
+	for _, value := range a {
+		if strings.TrimSuffix(value, "/") == strings.TrimSuffix(resource, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// signingKey is a public key of an authorization server
+type signingKey struct {
+	id  string
+	key crypto.PublicKey
+}
+
+// issuerKeys are the cached signing keys of an authorization server. When the last fetch
+// failed, the keys are those of the last successful fetch.
+type issuerKeys struct {
+	keys    []signingKey
+	fetched time.Time
+	err     error
+}
+
+// result returns the keys, or the error of the last fetch if there are none
+func (k *issuerKeys) result() ([]signingKey, error) {
+	if len(k.keys) == 0 && k.err != nil {
+		return nil, k.err
+	}
+	return k.keys, nil
+}
+
+// tokenVerifier verifies JWT access tokens against the signing keys the authorization
+// servers publish in their JWKS. Concurrent fetches of an issuer's keys are coalesced.
+type tokenVerifier struct {
+	client  *http.Client
+	mutex   sync.Mutex
+	issuers map[string]*issuerKeys
+	fetches map[string]chan struct{}
+}
+
+// newTokenVerifier creates a verifier without cached keys
+func newTokenVerifier() *tokenVerifier {
+	return &tokenVerifier{
+		client:  &http.Client{Timeout: jwksFetchTimeout},
+		issuers: make(map[string]*issuerKeys),
+		fetches: make(map[string]chan struct{}),
+	}
+}
+
+// verify checks that the token is a JWT issued by one of the issuers for the resource,
+// signed with one of their keys and currently valid
+func (v *tokenVerifier) verify(ctx context.Context, token string, issuers []string, resource string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	var claims tokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	issuer := ""
+	for _, candidate := range issuers {
+		if strings.TrimSuffix(candidate, "/") == strings.TrimSuffix(claims.Issuer, "/") {
+			issuer = candidate
+			break
+		}
+	}
+	if issuer == "" {
+		return fmt.Errorf("%w: untrusted issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+
+	if !claims.Audience.contains(resource) {
+		return fmt.Errorf("%w: not issued for %s", ErrInvalidToken, resource)
+	}
+
+	now := time.Now()
+	if claims.Expiry == nil {
+		return fmt.Errorf("%w: no expiry", ErrInvalidToken)
+	}
+	if now.After(numericDate(*claims.Expiry).Add(tokenLeeway)) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if claims.NotBefore != nil && now.Add(tokenLeeway).Before(numericDate(*claims.NotBefore)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+
+	keys, err := v.keys(ctx, issuer, header.KeyID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	for _, key := range keys {
+		if header.KeyID != "" && key.id != header.KeyID {
+			continue
+		}
+		if verifySignature(header.Algorithm, key.key, signed, signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature not verified by the keys of %s", ErrInvalidToken, issuer)
+}
+
+// keys returns the signing keys of the issuer, fetching them when they aren't cached, are
+// outdated or don't include the key ID. Keys are fetched at most once per
+// jwksMinRefreshInterval, so that failed fetches and unknown key IDs don't cause a fetch
+// for every request.
+func (v *tokenVerifier) keys(ctx context.Context, issuer, keyID string) ([]signingKey, error) {
+	v.mutex.Lock()
+	if cached := v.issuers[issuer]; cached != nil {
+		age := time.Since(cached.fetched)
+		if age < jwksMinRefreshInterval ||
+			(age < jwksRefreshInterval && cached.err == nil && hasKey(cached.keys, keyID)) {
+			v.mutex.Unlock()
+			return cached.result()
+		}
+	}
+	done, fetching := v.fetches[issuer]
+	if !fetching {
+		done = make(chan struct{})
+		v.fetches[issuer] = done
+		go v.fetch(issuer, done)
+	}
+	v.mutex.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	return v.issuers[issuer].result()
+}
+
+// fetch fetches the keys of the issuer for all requests waiting on done. It doesn't depend
+// on the context of a single request, which may be cancelled while others wait.
+func (v *tokenVerifier) fetch(issuer string, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	keys, err := v.fetchKeys(ctx, issuer)
+
+	v.mutex.Lock()
+	entry := &issuerKeys{keys: keys, fetched: time.Now(), err: err}
+	if previous := v.issuers[issuer]; err != nil && previous != nil {
+		// Keep verifying with the previous keys while the authorization server is unreachable
+		entry.keys = previous.keys
+	}
+	v.issuers[issuer] = entry
+	delete(v.fetches, issuer)
+	v.mutex.Unlock()
+	close(done)
+}
+
+// hasKey reports whether the keys include the key ID, any key matching an empty key ID
+func hasKey(keys []signingKey, keyID string) bool {
+	for _, key := range keys {
+		if keyID == "" || key.id == keyID {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchKeys looks up the issuer's JWKS in its authorization server metadata (RFC 8414),
+// falling back to its OpenID Connect configuration, and fetches its signing keys
+func (v *tokenVerifier) fetchKeys(ctx context.Context, issuer string) ([]signingKey, error) {
+	issuerURL, err := url.Parse(strings.TrimSuffix(issuer, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer URL %q: %w", issuer, err)
+	}
+	oauthMetadata := *issuerURL
+	oauthMetadata.Path = "/.well-known/oauth-authorization-server" + issuerURL.Path
+
+	var metadata struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	err = v.getJSON(ctx, oauthMetadata.String(), &metadata)
+	if err != nil || metadata.JWKSURI == "" {
+		if err = v.getJSON(ctx, issuerURL.String()+"/.well-known/openid-configuration", &metadata); err != nil {
+			return nil, fmt.Errorf("failed to get the metadata of %s: %w", issuer, err)
+		}
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuerURL.String() {
+		return nil, fmt.Errorf("metadata of %s is for issuer %q", issuer, metadata.Issuer)
+	}
+	if metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("metadata of %s has no jwks_uri", issuer)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, metadata.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to get the keys of %s: %w", issuer, err)
+	}
+	keys := make([]signingKey, 0, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped
+		if key, err := jwk.publicKey(); err == nil {
+			keys = append(keys, signingKey{id: jwk.KeyID, key: key})
+		}
+	}
+	return keys, nil
+}
+
+// getJSON fetches the JSON document at the URL
+func (v *tokenVerifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jsonWebKey is a public key of a JWKS (RFC 7517)
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// publicKey returns the RSA or EC public key
+func (k *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+// ecdsaCurves are the curves of the ECDSA algorithms
+var ecdsaCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verifySignature verifies the JWS signature of the signed input with the algorithm, which
+// must be an RSA or ECDSA algorithm matching the key
+func verifySignature(algorithm string, key crypto.PublicKey, signed, signature []byte) bool {
+	var hash crypto.Hash
+	switch algorithm[min(2, len(algorithm)):] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return false
+	}
+	hasher := hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch algorithm[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+		case "PS":
+			return rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if ecdsaCurves[algorithm] != key.Curve || len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment of a JWT
+func decodeSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// numericDate converts a JWT NumericDate to a time
+func numericDate(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
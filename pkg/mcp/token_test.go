@@ -0,0 +1,257 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// testResource is the resource the test tokens are issued for
+const testResource = "http://gw.example.com/mcp/"
+
+// testIssuer is an OAuth 2.0 authorization server publishing its signing keys
+type testIssuer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	keyID    string
+	key      *rsa.PrivateKey
+	requests atomic.Int32
+	down     atomic.Bool
+}
+
+// newTestIssuer starts an authorization server with an RSA signing key
+func newTestIssuer() *testIssuer {
+	issuer := &testIssuer{}
+	issuer.rotate("key-1")
+	issuer.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issuer.requests.Add(1)
+		if issuer.down.Load() {
+			http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		issuer.mutex.Lock()
+		defer issuer.mutex.Unlock()
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server":
+			writeJSON(w, http.StatusOK, map[string]string{"issuer": issuer.URL, "jwks_uri": issuer.URL + "/jwks"})
+		case "/jwks":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": issuer.keyID,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(issuer.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(issuer.key.E)).Bytes()),
+			}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return issuer
+}
+
+// rotate replaces the issuer's signing key
+func (i *testIssuer) rotate(keyID string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.keyID, i.key = keyID, key
+}
+
+// token returns an RS256 token of the issuer with the claims
+func (i *testIssuer) token(claims map[string]interface{}) string {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	signed := encodeSegment(map[string]string{"alg": "RS256", "kid": i.keyID}) + "." + encodeSegment(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	Expect(err).NotTo(HaveOccurred())
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// validToken returns a token of the issuer for testResource that expires in an hour
+func (i *testIssuer) validToken() string {
+	return i.token(map[string]interface{}{
+		"iss": i.URL,
+		"aud": testResource,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+func encodeSegment(v interface{}) string {
+	data, err := json.Marshal(v)
+	Expect(err).NotTo(HaveOccurred())
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+var _ = Describe("Token verifier", func() {
+	var (
+		ctx      context.Context
+		issuer   *testIssuer
+		verifier *tokenVerifier
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		issuer = newTestIssuer()
+		verifier = newTokenVerifier()
+	})
+
+	AfterEach(func() {
+		issuer.Close()
+	})
+
+	It("should accept tokens signed by the issuer", func() {
+		Expect(verifier.verify(ctx, issuer.validToken(), []string{issuer.URL}, testResource)).To(Succeed())
+	})
+
+	It("should reject tokens of other issuers", func() {
+		Expect(verifier.verify(ctx, issuer.validToken(), []string{"https://other.example.com"}, testResource)).
+			To(MatchError(ContainSubstring("untrusted issuer")))
+	})
+
+	It("should reject tokens issued for other resources", func() {
+		for _, aud := range []interface{}{"https://api.example.com/", []string{"https://api.example.com/"}, nil} {
+			token := issuer.token(map[string]interface{}{
+				"iss": issuer.URL,
+				"aud": aud,
+				"exp": time.Now().Add(time.Hour).Unix(),
+			})
+			Expect(verifier.verify(ctx, token, []string{issuer.URL}, testResource)).
+				To(MatchError(ContainSubstring("not issued for " + testResource)))
+		}
+
+		token := issuer.token(map[string]interface{}{
+			"iss": issuer.URL,
+			"aud": []string{"https://api.example.com/", "http://gw.example.com/mcp"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		Expect(verifier.verify(ctx, token, []string{issuer.URL}, testResource)).To(Succeed())
+	})
+
+	It("should reject expired and not yet valid tokens", func() {
+		expired := issuer.token(map[string]interface{}{
+			"iss": issuer.URL,
+			"aud": testResource,
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		})
+		Expect(verifier.verify(ctx, expired, []string{issuer.URL}, testResource)).To(MatchError(ErrInvalidToken))
+
+		early := issuer.token(map[string]interface{}{
+			"iss": issuer.URL,
+			"aud": testResource,
+			"exp": time.Now().Add(2 * time.Hour).Unix(),
+			"nbf": time.Now().Add(time.Hour).Unix(),
+		})
+		Expect(verifier.verify(ctx, early, []string{issuer.URL}, testResource)).To(MatchError(ErrInvalidToken))
+
+		unlimited := issuer.token(map[string]interface{}{"iss": issuer.URL, "aud": testResource})
+		Expect(verifier.verify(ctx, unlimited, []string{issuer.URL}, testResource)).
+			To(MatchError(ContainSubstring("no expiry")))
+	})
+
+	It("should reject tokens with forged signatures", func() {
+		forger := newTestIssuer()
+		defer forger.Close()
+		forged := forger.token(map[string]interface{}{
+			"iss": issuer.URL,
+			"aud": testResource,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		Expect(verifier.verify(ctx, forged, []string{issuer.URL}, testResource)).To(MatchError(ErrInvalidToken))
+
+		unsigned := encodeSegment(map[string]string{"alg": "none"}) + "." +
+			encodeSegment(map[string]interface{}{
+				"iss": issuer.URL,
+				"aud": testResource,
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}) + "."
+		Expect(verifier.verify(ctx, unsigned, []string{issuer.URL}, testResource)).To(MatchError(ErrInvalidToken))
+
+		Expect(verifier.verify(ctx, "opaque-token", []string{issuer.URL}, testResource)).To(MatchError(ErrInvalidToken))
+	})
+
+	It("should fetch the keys again when the issuer rotates its key", func() {
+		Expect(verifier.verify(ctx, issuer.validToken(), []string{issuer.URL}, testResource)).To(Succeed())
+
+		issuer.rotate("key-2")
+		verifier.mutex.Lock()
+		verifier.issuers[issuer.URL].fetched = time.Now().Add(-jwksMinRefreshInterval)
+		verifier.mutex.Unlock()
+		Expect(verifier.verify(ctx, issuer.validToken(), []string{issuer.URL}, testResource)).To(Succeed())
+	})
+
+	It("should coalesce key fetches and back off while the issuer is down", func() {
+		issuer.down.Store(true)
+
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(verifier.verify(ctx, issuer.validToken(), []string{issuer.URL}, testResource)).
+					To(MatchError(ErrInvalidToken))
+			}()
+		}
+		wg.Wait()
+		// One fetch of the authorization server metadata and one of the OpenID configuration
+		Expect(issuer.requests.Load()).To(Equal(int32(2)))
+
+		issuer.down.Store(false)
+		Expect(verifier.verify(ctx, issuer.validToken(), []string{issuer.URL}, testResource)).
+			To(MatchError(ErrInvalidToken))
+		Expect(issuer.requests.Load()).To(Equal(int32(2)))
+
+		By("fetching again once the back-off elapsed")
+		verifier.mutex.Lock()
+		verifier.issuers[issuer.URL].fetched = time.Now().Add(-jwksMinRefreshInterval)
+		verifier.mutex.Unlock()
+		Expect(verifier.verify(ctx, issuer.validToken(), []string{issuer.URL}, testResource)).To(Succeed())
+	})
+
+	It("should verify ECDSA signatures", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		signed := []byte("header.claims")
+		digest := sha256.Sum256(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		Expect(err).NotTo(HaveOccurred())
+		signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+		Expect(verifySignature("ES256", &key.PublicKey, signed, signature)).To(BeTrue())
+		Expect(verifySignature("ES384", &key.PublicKey, signed, signature)).To(BeFalse())
+		Expect(verifySignature("RS256", &key.PublicKey, signed, signature)).To(BeFalse())
+	})
+})
@@ -0,0 +1,440 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/metrics"
+)
+
+// ServicesWatchPath is the path of the SSE stream of registry changes for dashboards
+const ServicesWatchPath = "/api/services/watch"
+
+// EventType is the type of a registry change
+type EventType string
+
+const (
+	// EventAdded means a service was registered
+	EventAdded EventType = "Added"
+
+	// EventUpdated means a registered service changed, e.g. its endpoint or status
+	EventUpdated EventType = "Updated"
+
+	// EventRemoved means a service was deregistered
+	EventRemoved EventType = "Removed"
+)
+
+// ServiceEvent is a change of the registry. Old is nil for Added events, New is nil for Removed events.
+type ServiceEvent struct {
+	Type EventType
+	Old  *MCPService
+	New  *MCPService
+}
+
+// Service returns the service the event is about, its previous state for Removed events
+func (e ServiceEvent) Service() *MCPService {
+	if e.New != nil {
+		return e.New
+	}
+	return e.Old
+}
+
+// Name returns the name of the service the event is about
+func (e ServiceEvent) Name() types.NamespacedName {
+	svc := e.Service()
+	return types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}
+}
+
+// maxWatchBacklog is the number of events a watcher may fall behind. A watcher that falls
+// further behind is resynced: its queued events are replaced by the differences between the
+// services it has seen and the current services.
+const maxWatchBacklog = 1000
+
+// registryWatcher queues the events of a single Watch call, so that a slow consumer
+// never blocks the registry
+type registryWatcher struct {
+	mutex sync.Mutex
+	queue []ServiceEvent
+	wake  chan struct{}
+
+	// resync holds the registry's services at the time the watcher fell too far behind. It is
+	// delivered to the consumer as differences to seen, before the events queued after it.
+	resync map[types.NamespacedName]*MCPService
+
+	// seen is the state of the services delivered to the consumer, only used by the goroutine
+	// of the Watch call
+	seen map[types.NamespacedName]*MCPService
+}
+
+// push queues events for the consumer. The services are the registry's services after the
+// events; they replace the queue of a consumer that fell too far behind. It must be called
+// with the registry lock held.
+func (w *registryWatcher) push(events []ServiceEvent, services map[types.NamespacedName]*MCPService) {
+	w.mutex.Lock()
+	if len(w.queue)+len(events) > maxWatchBacklog {
+		w.resync = make(map[types.NamespacedName]*MCPService, len(services))
+		for name, svc := range services {
+			w.resync[name] = svc
+		}
+		w.queue = nil
+	} else {
+		w.queue = append(w.queue, events...)
+	}
+	w.mutex.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pop takes all queued events, starting with the resync of a consumer that fell too far behind
+func (w *registryWatcher) pop() []ServiceEvent {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	events := w.queue
+	if w.resync != nil {
+		events = append(w.resyncEvents(), events...)
+	}
+	w.queue, w.resync = nil, nil
+	return events
+}
+
+// resyncEvents returns the events that lead from the services the consumer has seen to the
+// services of the resync. Callers must hold the mutex.
+func (w *registryWatcher) resyncEvents() []ServiceEvent {
+	var events []ServiceEvent
+	for name, svc := range w.resync {
+		old, seen := w.seen[name]
+		switch {
+		case !seen:
+			events = append(events, ServiceEvent{Type: EventAdded, New: svc})
+		case old != svc && serviceChanged(old, svc):
+			events = append(events, ServiceEvent{Type: EventUpdated, Old: old, New: svc})
+		}
+	}
+	for name, old := range w.seen {
+		if _, exists := w.resync[name]; !exists {
+			events = append(events, ServiceEvent{Type: EventRemoved, Old: old})
+		}
+	}
+	return events
+}
+
+// see records that an event was delivered to the consumer
+func (w *registryWatcher) see(event ServiceEvent) {
+	if event.New == nil {
+		delete(w.seen, event.Name())
+		return
+	}
+	w.seen[event.Name()] = event.New
+}
+
+// Watch returns the registered services and a channel receiving every later change, in
+// order, until the context is cancelled. The channel is closed afterwards. A consumer that
+// falls more than maxWatchBacklog events behind receives the changes since the services it
+// has seen instead of every intermediate one.
+func (r *Registry) Watch(ctx context.Context) ([]*MCPService, <-chan ServiceEvent) {
+	r.mutex.Lock()
+	watcher := &registryWatcher{
+		wake: make(chan struct{}, 1),
+		seen: make(map[types.NamespacedName]*MCPService, len(r.services)),
+	}
+	r.watchers[watcher] = struct{}{}
+	services := make([]*MCPService, 0, len(r.services))
+	for name, svc := range r.services {
+		services = append(services, svc)
+		watcher.seen[name] = svc
+	}
+	r.mutex.Unlock()
+
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
+
+	events := make(chan ServiceEvent)
+	go func() {
+		defer close(events)
+		defer func() {
+			r.mutex.Lock()
+			delete(r.watchers, watcher)
+			r.mutex.Unlock()
+		}()
+
+		for {
+			for _, event := range watcher.pop() {
+				select {
+				case events <- event:
+					watcher.see(event)
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-watcher.wake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return services, events
+}
+
+// recordChanges queues events for the differences between the services before a change
+// and the current services. It must be called with the registry lock held.
+func (r *Registry) recordChanges(before map[types.NamespacedName]*MCPService) {
+	for name, svc := range r.services {
+		old, existed := before[name]
+		switch {
+		case !existed:
+			r.pendingEvents = append(r.pendingEvents, ServiceEvent{Type: EventAdded, New: svc})
+		case old != svc && serviceChanged(old, svc):
+			r.pendingEvents = append(r.pendingEvents, ServiceEvent{Type: EventUpdated, Old: old, New: svc})
+		}
+	}
+	for name, old := range before {
+		if _, exists := r.services[name]; !exists {
+			r.pendingEvents = append(r.pendingEvents, ServiceEvent{Type: EventRemoved, Old: old})
+		}
+	}
+}
+
+// serviceChanged reports whether a service changed in a way watchers care about. Health
+// probes that confirm the status and re-registrations of an unchanged Service don't count.
+func serviceChanged(old, svc *MCPService) bool {
+	if old.Type != svc.Type || old.Endpoint != svc.Endpoint || old.Status != svc.Status || old.Message != svc.Message ||
+		old.Stale != svc.Stale || old.Target != svc.Target {
+		return true
+	}
+	if (old.ConflictsWith == nil) != (svc.ConflictsWith == nil) ||
+		(old.ConflictsWith != nil && *old.ConflictsWith != *svc.ConflictsWith) {
+		return true
+	}
+	if (old.Endpoints == nil) != (svc.Endpoints == nil) || (old.Endpoints != nil && *old.Endpoints != *svc.Endpoints) {
+		return true
+	}
+	return cacheVersion(old) != cacheVersion(svc)
+}
+
+// deliver passes the queued service events and list changes to the watchers and listeners.
+// It must be called without the registry lock, so that listeners can use the registry.
+func (r *Registry) deliver() {
+	r.mutex.Lock()
+	events, changes := r.pendingEvents, r.pendingChanges
+	r.pendingEvents, r.pendingChanges = nil, nil
+	if len(events) > 0 {
+		for watcher := range r.watchers {
+			watcher.push(events, r.services)
+		}
+	}
+	listeners := make([]func(ListChange), 0, len(r.listChanged))
+	for _, fn := range r.listChanged {
+		listeners = append(listeners, fn)
+	}
+	r.mutex.Unlock()
+
+	for _, change := range changes {
+		for _, fn := range listeners {
+			fn(change)
+		}
+	}
+}
+
+// subscribe starts receiving the registry's service events and backend list changes. It
+// returns a function that stops both.
+func (s *Server) subscribe() func() {
+	unsubscribe := s.registry.OnListChanged(s.onListChanged)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, events := s.registry.Watch(ctx)
+	go func() {
+		for event := range events {
+			s.onServiceEvent(event)
+		}
+	}()
+	return func() {
+		unsubscribe()
+		cancel()
+	}
+}
+
+// onServiceEvent drops the cached results of a removed or changed Service and tells client
+// streams to refetch their lists when a service appears, moves or disappears
+func (s *Server) onServiceEvent(event ServiceEvent) {
+	name := event.Name()
+	reason := ""
+	switch {
+	case event.Type == EventRemoved:
+		reason = cacheInvalidationDeregistered
+	case event.Type == EventUpdated && cacheVersion(event.Old) != cacheVersion(event.New):
+		reason = cacheInvalidationReregistered
+	}
+	if reason != "" && s.cache.forget(name) {
+		metrics.CacheInvalidationCount.WithLabelValues(s.gatewayName().String(), name.String(), reason).Inc()
+	}
+
+	// Clients only need to refetch their lists when a service appears or moves
+	if event.Type == EventUpdated && event.Old.Endpoint == event.New.Endpoint && event.Old.Type == event.New.Type {
+		return
+	}
+	s.notifications.publish(ListChange{Service: name, MCPService: event.Service(), Notifications: allListsChanged})
+}
+
+// ServiceCountRecorder keeps the fetchfy_mcp_service_count metric in line with the registry
+type ServiceCountRecorder struct {
+	registry *Registry
+}
+
+// NewServiceCountRecorder creates a recorder for the registry's services
+func NewServiceCountRecorder(registry *Registry) *ServiceCountRecorder {
+	return &ServiceCountRecorder{registry: registry}
+}
+
+// NeedLeaderElection returns false, as every replica exports the metric of its own registry.
+// It implements manager.LeaderElectionRunnable.
+func (c *ServiceCountRecorder) NeedLeaderElection() bool {
+	return false
+}
+
+// Start records the number of services by type until the context is cancelled. It implements manager.Runnable.
+func (c *ServiceCountRecorder) Start(ctx context.Context) error {
+	counts := map[ServiceType]int{ServiceTypeTool: 0, ServiceTypeAgent: 0}
+	services, events := c.registry.Watch(ctx)
+	for _, svc := range services {
+		counts[svc.Type]++
+	}
+	record := func() {
+		for serviceType, count := range counts {
+			metrics.ServiceCount.WithLabelValues(string(serviceType)).Set(float64(count))
+		}
+	}
+	record()
+
+	for event := range events {
+		if event.Old != nil {
+			counts[event.Old.Type]--
+		}
+		if event.New != nil {
+			counts[event.New.Type]++
+		}
+		record()
+	}
+	return nil
+}
+
+// WatchEvent is a registry change as sent on the /api/services/watch stream
+type WatchEvent struct {
+	Type     EventType         `json:"type"`
+	Service  DiscoveryService  `json:"service"`
+	Previous *DiscoveryService `json:"previous,omitempty"`
+}
+
+// handleWatchServices streams the services visible through the listener as SSE events: an
+// Added event for each current service, then an event for every change. Updated events
+// carry the previous state of the service.
+func (s *Server) handleWatchServices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	services, events := s.registry.Watch(ctx)
+	filter := s.listenerSettingsFor(r).filter
+	baseURL := requestBaseURL(r)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event WatchEvent) bool {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for _, svc := range services {
+		if filter.matches(svc) && !send(WatchEvent{Type: EventAdded, Service: discoveryService(svc, baseURL)}) {
+			return
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(streamKeepAliveInterval)
+	defer ticker.Stop()
+	draining := drainingFrom(r.Context())
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if watchEvent, visible := visibleWatchEvent(event, filter, baseURL); visible && !send(watchEvent) {
+				return
+			}
+		case <-draining:
+			return
+		case <-ticker.C:
+			if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// visibleWatchEvent converts a registry event for a client of a listener. A service that
+// becomes visible or hidden through the listener's filter is reported as Added or Removed.
+func visibleWatchEvent(event ServiceEvent, filter *serviceFilter, baseURL string) (WatchEvent, bool) {
+	oldVisible := event.Old != nil && filter.matches(event.Old)
+	newVisible := event.New != nil && filter.matches(event.New)
+	switch {
+	case oldVisible && newVisible:
+		previous := discoveryService(event.Old, baseURL)
+		return WatchEvent{Type: EventUpdated, Service: discoveryService(event.New, baseURL), Previous: &previous}, true
+	case newVisible:
+		return WatchEvent{Type: EventAdded, Service: discoveryService(event.New, baseURL)}, true
+	case oldVisible:
+		return WatchEvent{Type: EventRemoved, Service: discoveryService(event.Old, baseURL)}, true
+	}
+	return WatchEvent{}, false
+}
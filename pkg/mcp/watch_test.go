@@ -0,0 +1,285 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/metrics"
+)
+
+var _ = Describe("Registry watch", func() {
+	var registry *Registry
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	newService := func(namespace, name string, created time.Time) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(created),
+				Annotations:       map[string]string{EndpointAnnotation: "/mcp/" + namespace + "/" + name},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}
+	}
+
+	BeforeEach(func() {
+		registry = NewRegistry(logr.Discard())
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should report services that are added, changed or removed", func() {
+		weather := newService("tools", "weather", time.Now())
+		_, err := registry.RegisterService(ctx, weather, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+
+		services, events := registry.Watch(ctx)
+		Expect(services).To(HaveLen(1))
+		Expect(services[0].Name).To(Equal("weather"))
+
+		By("ignoring re-registrations that don't change the service")
+		_, err = registry.RegisterService(ctx, weather, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		Consistently(events, 100*time.Millisecond).ShouldNot(Receive())
+
+		search := newService("tools", "search", time.Now())
+		_, err = registry.RegisterService(ctx, search, ServiceTypeAgent)
+		Expect(err).NotTo(HaveOccurred())
+		var event ServiceEvent
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(EventAdded))
+		Expect(event.Old).To(BeNil())
+		Expect(event.New.Name).To(Equal("search"))
+
+		By("reporting the previous state of changed services")
+		weather.Annotations[EndpointAnnotation] = "/mcp/weather"
+		_, err = registry.RegisterService(ctx, weather, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(EventUpdated))
+		Expect(event.Old.Endpoint).To(Equal("/mcp/tools/weather"))
+		Expect(event.New.Endpoint).To(Equal("/mcp/weather"))
+
+		By("reporting health changes but not confirmations")
+		name := types.NamespacedName{Name: "weather", Namespace: "tools"}
+		Expect(registry.SetServiceHealth(name, errors.New("connection refused"))).To(BeTrue())
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(EventUpdated))
+		Expect(event.Old.Status).To(Equal(ServiceStatusAvailable))
+		Expect(event.New.Status).To(Equal(ServiceStatusUnavailable))
+		Expect(registry.SetServiceHealth(name, errors.New("connection refused"))).To(BeFalse())
+		Consistently(events, 100*time.Millisecond).ShouldNot(Receive())
+
+		Expect(registry.DeregisterService(ctx, name)).To(BeTrue())
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(EventRemoved))
+		Expect(event.Old.Name).To(Equal("weather"))
+		Expect(event.New).To(BeNil())
+
+		cancel()
+		Eventually(events).Should(BeClosed())
+	})
+
+	It("should report services whose conflict changes with another service", func() {
+		older := newService("tools", "weather", time.Now().Add(-time.Hour))
+		_, err := registry.RegisterService(ctx, older, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		_, events := registry.Watch(ctx)
+
+		newer := newService("tools", "forecast", time.Now())
+		newer.Annotations[EndpointAnnotation] = older.Annotations[EndpointAnnotation]
+		_, err = registry.RegisterService(ctx, newer, ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		var event ServiceEvent
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(EventAdded))
+		Expect(event.New.Status).To(Equal(ServiceStatusConflicted))
+
+		Expect(registry.DeregisterService(ctx, types.NamespacedName{Name: "weather", Namespace: "tools"})).To(BeTrue())
+		received := map[EventType]ServiceEvent{}
+		for range 2 {
+			Eventually(events).Should(Receive(&event))
+			received[event.Type] = event
+		}
+		Expect(received).To(HaveKey(EventRemoved))
+		Expect(received).To(HaveKey(EventUpdated))
+		Expect(received[EventUpdated].New.Name).To(Equal("forecast"))
+		Expect(received[EventUpdated].New.Status).To(Equal(ServiceStatusAvailable))
+	})
+
+	It("should not block the registry on slow watchers", func() {
+		_, _ = registry.Watch(ctx)
+		for _, name := range []string{"a", "b", "c", "d"} {
+			_, err := registry.RegisterService(ctx, newService("tools", name, time.Now()), ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(registry.ListServices()).To(HaveLen(4))
+	})
+
+	It("should resync watchers that fall too far behind", func() {
+		for _, name := range []string{"weather", "search"} {
+			_, err := registry.RegisterService(ctx, newService("tools", name, time.Now()), ServiceTypeTool)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		services, events := registry.Watch(ctx)
+
+		weather := types.NamespacedName{Name: "weather", Namespace: "tools"}
+		for i := range 2*maxWatchBacklog + 1 {
+			var probeErr error
+			if i%2 == 0 {
+				probeErr = errors.New("connection refused")
+			}
+			registry.SetServiceHealth(weather, probeErr)
+		}
+		Expect(registry.DeregisterService(ctx, types.NamespacedName{Name: "search", Namespace: "tools"})).To(BeTrue())
+		_, err := registry.RegisterService(ctx, newService("tools", "forecast", time.Now()), ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("receiving the changes since the services the watcher has seen")
+		seen := map[string]*MCPService{}
+		for _, svc := range services {
+			seen[svc.Name] = svc
+		}
+		received := 0
+		for {
+			var event ServiceEvent
+			select {
+			case event = <-events:
+			case <-time.After(100 * time.Millisecond):
+			}
+			if event.Type == "" {
+				break
+			}
+			received++
+			if event.New == nil {
+				delete(seen, event.Name().Name)
+			} else {
+				seen[event.Name().Name] = event.New
+			}
+		}
+		Expect(received).To(BeNumerically("<=", maxWatchBacklog+3))
+		Expect(seen).To(HaveLen(2))
+		Expect(seen).To(HaveKey("forecast"))
+		Expect(seen["weather"].Status).To(Equal(ServiceStatusUnavailable))
+	})
+
+	It("should keep the service count metric in line with the registry", func() {
+		recorder := NewServiceCountRecorder(registry)
+		go func() {
+			defer GinkgoRecover()
+			Expect(recorder.Start(ctx)).To(Succeed())
+		}()
+
+		_, err := registry.RegisterService(ctx, newService("tools", "weather", time.Now()), ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = registry.RegisterService(ctx, newService("agents", "planner", time.Now()), ServiceTypeAgent)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() float64 {
+			return testutil.ToFloat64(metrics.ServiceCount.WithLabelValues(string(ServiceTypeAgent)))
+		}).Should(Equal(1.0))
+		Expect(testutil.ToFloat64(metrics.ServiceCount.WithLabelValues(string(ServiceTypeTool)))).To(Equal(1.0))
+
+		Expect(registry.DeregisterService(ctx, types.NamespacedName{Name: "weather", Namespace: "tools"})).To(BeTrue())
+		Eventually(func() float64 {
+			return testutil.ToFloat64(metrics.ServiceCount.WithLabelValues(string(ServiceTypeTool)))
+		}).Should(Equal(0.0))
+	})
+
+	It("should stream the changes visible through the listener", func() {
+		server := NewServer(registry, logr.Discard())
+		gateway := newTestGateway(8080)
+		gateway.Spec.Listeners[0].Services = &fetchfyv1alpha2.ListenerServices{Namespaces: []string{"tools"}}
+		server.Configure(gateway, nil)
+		defer server.Stop(context.Background())
+
+		_, err := registry.RegisterService(ctx, newService("tools", "weather", time.Now()), ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			server.handleWatchServices(w, r.WithContext(context.WithValue(r.Context(), listenerKey{}, "mcp")))
+		}))
+		defer backend.Close()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, backend.URL+ServicesWatchPath, nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		var mutex sync.Mutex
+		var received []WatchEvent
+		go func() {
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+					var event WatchEvent
+					if json.Unmarshal([]byte(data), &event) == nil {
+						mutex.Lock()
+						received = append(received, event)
+						mutex.Unlock()
+					}
+				}
+			}
+		}()
+		events := func() []WatchEvent {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return append([]WatchEvent(nil), received...)
+		}
+
+		Eventually(events).Should(HaveLen(1))
+		Expect(events()[0].Type).To(Equal(EventAdded))
+		Expect(events()[0].Service.Name).To(Equal("weather"))
+		Expect(events()[0].Service.URL).To(Equal(backend.URL + "/mcp/tools/weather"))
+
+		By("hiding services the listener doesn't expose")
+		_, err = registry.RegisterService(ctx, newService("internal", "billing", time.Now()), ServiceTypeTool)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(registry.SetServiceHealth(types.NamespacedName{Name: "weather", Namespace: "tools"},
+			errors.New("connection refused"))).To(BeTrue())
+		Eventually(events).Should(HaveLen(2))
+		Consistently(events, 100*time.Millisecond).Should(HaveLen(2))
+		updated := events()[1]
+		Expect(updated.Type).To(Equal(EventUpdated))
+		Expect(updated.Service.Status).To(Equal(string(ServiceStatusUnavailable)))
+		Expect(updated.Previous).NotTo(BeNil())
+		Expect(updated.Previous.Status).To(Equal(string(ServiceStatusAvailable)))
+	})
+})
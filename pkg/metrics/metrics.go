@@ -58,6 +58,62 @@ var (
 		[]string{"path", "method"},
 	)
 
+	// BackendRequestCount tracks the number of MCP requests forwarded to each backend
+	BackendRequestCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fetchfy_mcp_backend_request_count",
+			Help: "Number of MCP requests forwarded to backends by gateway, route, backend, HTTP status code and result",
+		},
+		[]string{"gateway", "route", "backend", "code", "result"},
+	)
+
+	// BackendRequestDuration tracks the duration of MCP requests forwarded to each backend
+	BackendRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "fetchfy_mcp_backend_request_duration_seconds",
+			Help:    "Duration of MCP requests forwarded to backends in seconds, excluding SSE streams",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"gateway", "route", "backend"},
+	)
+
+	// MirrorRequestCount tracks the number of requests mirrored to shadow backends by comparison result
+	MirrorRequestCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fetchfy_mcp_mirror_request_count",
+			Help: "Number of MCP requests mirrored to shadow backends by gateway, route, shadow backend and result",
+		},
+		[]string{"gateway", "route", "backend", "result"},
+	)
+
+	// MirrorLatencyDelta tracks how much slower shadow backends respond than the backends serving the requests
+	MirrorLatencyDelta = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "fetchfy_mcp_mirror_latency_delta_seconds",
+			Help:    "Latency of shadow responses minus latency of served responses in seconds",
+			Buckets: []float64{-5, -1, -0.5, -0.1, -0.05, -0.01, 0, 0.01, 0.05, 0.1, 0.5, 1, 5},
+		},
+		[]string{"gateway", "route", "backend"},
+	)
+
+	// CacheRequestCount tracks the lookups of cacheable MCP requests in the response cache
+	CacheRequestCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fetchfy_mcp_cache_request_count",
+			Help: "Number of cacheable MCP requests by gateway, backend, JSON-RPC method and result (hit or miss)",
+		},
+		[]string{"gateway", "backend", "method", "result"},
+	)
+
+	// CacheInvalidationCount tracks the invalidations of cached responses
+	CacheInvalidationCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fetchfy_mcp_cache_invalidation_count",
+			Help: "Number of invalidations of cached MCP responses by gateway, backend and reason",
+		},
+		[]string{"gateway", "backend", "reason"},
+	)
+
 	// ErrorCount tracks the number of errors
 	ErrorCount = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -76,6 +132,12 @@ func init() {
 		ServiceCount,
 		RequestCount,
 		RequestDuration,
+		BackendRequestCount,
+		BackendRequestDuration,
+		MirrorRequestCount,
+		MirrorLatencyDelta,
+		CacheRequestCount,
+		CacheInvalidationCount,
 		ErrorCount,
 	)
 }
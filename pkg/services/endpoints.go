@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+)
+
+// serviceEndpoints counts the endpoints of a service from its EndpointSlices. For headless
+// services, it also resolves the endpoint port of the MCP port.
+func (sw *ServiceWatcher) serviceEndpoints(ctx context.Context, svc *corev1.Service) (mcp.Endpoints, error) {
+	slices := &discoveryv1.EndpointSliceList{}
+	if err := sw.client.List(ctx, slices, client.InNamespace(svc.Namespace),
+		client.MatchingLabels{discoveryv1.LabelServiceName: svc.Name}); err != nil {
+		return mcp.Endpoints{}, err
+	}
+
+	endpoints := CountEndpoints(slices.Items)
+	if mcp.IsHeadless(svc) {
+		endpoints.Port = endpointPort(svc, slices.Items)
+	}
+	return endpoints, nil
+}
+
+// endpointPort returns the endpoint port of the service's MCP port if its target port is
+// named, 0 otherwise. EndpointSlice ports have the names of the Service ports.
+func endpointPort(svc *corev1.Service, slices []discoveryv1.EndpointSlice) int32 {
+	port, err := mcp.ServicePort(svc)
+	if err != nil || port.TargetPort.StrVal == "" {
+		return 0
+	}
+	for _, slice := range slices {
+		for _, slicePort := range slice.Ports {
+			if slicePort.Name != nil && *slicePort.Name == port.Name && slicePort.Port != nil {
+				return *slicePort.Port
+			}
+		}
+	}
+	return 0
+}
+
+// CountEndpoints counts the endpoints of a service's EndpointSlices. Terminating endpoints
+// aren't counted, and an endpoint listed in several slices, e.g. one per IP family, is
+// counted once.
+func CountEndpoints(slices []discoveryv1.EndpointSlice) mcp.Endpoints {
+	ready := make(map[string]bool)
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating {
+				continue
+			}
+			key := endpointKey(endpoint)
+			// A nil ready condition means unknown, which consumers should treat as ready
+			ready[key] = ready[key] || endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+		}
+	}
+
+	counts := mcp.Endpoints{Total: int32(len(ready))}
+	for _, isReady := range ready {
+		if isReady {
+			counts.Ready++
+		}
+	}
+	return counts
+}
+
+// endpointKey identifies the pod or address behind an endpoint
+func endpointKey(endpoint discoveryv1.Endpoint) string {
+	if endpoint.TargetRef != nil && endpoint.TargetRef.UID != "" {
+		return string(endpoint.TargetRef.UID)
+	}
+	if len(endpoint.Addresses) > 0 {
+		return endpoint.Addresses[0]
+	}
+	return ""
+}
+
+// serviceForEndpointSlice maps an EndpointSlice to its Service if the Service is registered
+func (sw *ServiceWatcher) serviceForEndpointSlice(ctx context.Context, obj client.Object) []reconcile.Request {
+	serviceName, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+	if !ok {
+		return nil
+	}
+
+	name := types.NamespacedName{Name: serviceName, Namespace: obj.GetNamespace()}
+	if _, registered := sw.registry.GetService(sw.registryName(name)); !registered {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: name}}
+}
@@ -0,0 +1,504 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcluster "sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+)
+
+const (
+	// ClusterConditionReady is the condition of a federated cluster whose services are watched
+	ClusterConditionReady = "Ready"
+
+	// ClusterReasonConnected means the cluster's caches are synced and its services are registered
+	ClusterReasonConnected = "Connected"
+
+	// ClusterReasonConnecting means the cluster's caches haven't synced yet
+	ClusterReasonConnecting = "Connecting"
+
+	// ClusterReasonKubeconfigError means the kubeconfig of the cluster can't be loaded
+	ClusterReasonKubeconfigError = "KubeconfigError"
+
+	// ClusterReasonConnectionFailed means watching the cluster failed
+	ClusterReasonConnectionFailed = "ConnectionFailed"
+
+	// ClusterReasonConflict means another Gateway federates a cluster of the same name differently
+	ClusterReasonConflict = "ClusterConflict"
+)
+
+// ConnectFunc watches the MCP-enabled Services of a remote cluster and registers them until the
+// context is done. It calls synced once the cluster's services are registered.
+type ConnectFunc func(ctx context.Context, cluster mcp.RemoteCluster, config *rest.Config, synced func()) error
+
+// Federation watches the MCP-enabled Services of the remote clusters federated by Gateways and
+// registers them with the registry. A cluster federated by several Gateways is watched once;
+// its name must identify the same cluster for all of them.
+type Federation struct {
+	reader   client.Reader
+	registry *mcp.Registry
+	log      logr.Logger
+	scheme   *runtime.Scheme
+	mgr      ctrl.Manager
+
+	// namespace is the operator's namespace, whose kubeconfig Secrets all gateways may use
+	namespace string
+
+	// connect watches a remote cluster, it is replaced in tests
+	connect ConnectFunc
+
+	// clusters is read and written by the reconcilers of all gateways, so it is guarded by mutex
+	clusters map[string]*federatedCluster
+	mutex    sync.Mutex
+}
+
+// federatedCluster is a remote cluster that is watched for one or more gateways
+type federatedCluster struct {
+	spec          fetchfyv1alpha2.FederatedCluster
+	secretVersion string
+	gateways      map[types.NamespacedName]bool
+	cancel        context.CancelFunc
+
+	// condition is the state of the connection, written by the cluster's goroutine
+	condition metav1.Condition
+}
+
+// NewFederation creates a new federation. The reader reads the kubeconfig Secrets; it should
+// be the manager's API reader, so that the operator doesn't cache the Secrets of the cluster.
+func NewFederation(
+	reader client.Reader,
+	registry *mcp.Registry,
+	log logr.Logger,
+	scheme *runtime.Scheme,
+) *Federation {
+	f := &Federation{
+		reader:   reader,
+		registry: registry,
+		log:      log.WithName("federation"),
+		scheme:   scheme,
+		clusters: make(map[string]*federatedCluster),
+	}
+	f.connect = f.watchCluster
+	return f
+}
+
+// SetConnectFunc replaces how remote clusters are watched
+func (f *Federation) SetConnectFunc(connect ConnectFunc) {
+	f.connect = connect
+}
+
+// SetOperatorNamespace allows all gateways to use the kubeconfig Secrets of the operator's
+// namespace. Otherwise gateways may only use the Secrets of their own namespace.
+func (f *Federation) SetOperatorNamespace(namespace string) {
+	f.namespace = namespace
+}
+
+// SetupWithManager sets up the federation with the manager, which stops watching all remote
+// clusters when it stops
+func (f *Federation) SetupWithManager(mgr ctrl.Manager) error {
+	f.mgr = mgr
+	return mgr.Add(f)
+}
+
+// NeedLeaderElection returns false, as every replica registers the services of the federated
+// clusters. It implements manager.LeaderElectionRunnable.
+func (f *Federation) NeedLeaderElection() bool {
+	return false
+}
+
+// Start blocks until the context is done and then stops watching all remote clusters
+func (f *Federation) Start(ctx context.Context) error {
+	<-ctx.Done()
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for name, cluster := range f.clusters {
+		cluster.cancel()
+		delete(f.clusters, name)
+	}
+	return nil
+}
+
+// Sync watches the clusters federated by the gateway and stops watching the clusters it no
+// longer federates. A cluster whose kubeconfig Secret changed or whose connection failed is
+// connected again. It returns the status of the gateway's clusters in the order of the spec.
+func (f *Federation) Sync(
+	ctx context.Context,
+	gateway *fetchfyv1alpha2.Gateway,
+) []fetchfyv1alpha2.FederatedClusterStatus {
+	gatewayName := types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace}
+	var specs []fetchfyv1alpha2.FederatedCluster
+	if gateway.Spec.Federation != nil {
+		specs = gateway.Spec.Federation.Clusters
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	federated := make(map[string]bool, len(specs))
+	statuses := make([]fetchfyv1alpha2.FederatedClusterStatus, 0, len(specs))
+	for _, spec := range specs {
+		federated[spec.Name] = true
+		spec = withClusterDefaults(spec, gateway.Namespace)
+		condition := f.syncCluster(ctx, gatewayName, spec)
+		condition.ObservedGeneration = gateway.Generation
+
+		// Keep the transition time of an unchanged condition
+		var conditions []metav1.Condition
+		for _, previous := range gateway.Status.Clusters {
+			if previous.Name == spec.Name {
+				conditions = append(conditions, previous.Conditions...)
+			}
+		}
+		meta.SetStatusCondition(&conditions, condition)
+		statuses = append(statuses, fetchfyv1alpha2.FederatedClusterStatus{
+			Name:       spec.Name,
+			Services:   f.countServices(spec.Name),
+			Conditions: conditions,
+		})
+	}
+
+	for name, cluster := range f.clusters {
+		if cluster.gateways[gatewayName] && !federated[name] {
+			f.release(ctx, name, gatewayName)
+		}
+	}
+
+	return statuses
+}
+
+// RemoveGateway stops watching the clusters that were only federated by the gateway
+func (f *Federation) RemoveGateway(ctx context.Context, gatewayName types.NamespacedName) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for name, cluster := range f.clusters {
+		if cluster.gateways[gatewayName] {
+			f.release(ctx, name, gatewayName)
+		}
+	}
+}
+
+// Clusters returns the names of the watched clusters in order
+func (f *Federation) Clusters() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	names := make([]string, 0, len(f.clusters))
+	for name := range f.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// syncCluster watches a cluster for a gateway and returns the state of its connection.
+// It must be called with the lock held.
+func (f *Federation) syncCluster(
+	ctx context.Context,
+	gatewayName types.NamespacedName,
+	spec fetchfyv1alpha2.FederatedCluster,
+) metav1.Condition {
+	existing, exists := f.clusters[spec.Name]
+	if exists && existing.spec != spec {
+		if len(existing.gateways) > 1 || !existing.gateways[gatewayName] {
+			return clusterCondition(metav1.ConditionFalse, ClusterReasonConflict,
+				fmt.Sprintf("Cluster %s is federated with other settings by another Gateway", spec.Name))
+		}
+		// The gateway's own settings changed, connect again
+		f.stop(ctx, spec.Name)
+		exists = false
+	}
+
+	config, secretVersion, err := f.loadKubeconfig(ctx, gatewayName.Namespace, spec)
+	if err != nil {
+		if exists {
+			f.release(ctx, spec.Name, gatewayName)
+		}
+		return clusterCondition(metav1.ConditionFalse, ClusterReasonKubeconfigError, err.Error())
+	}
+
+	reconnect := exists &&
+		(existing.secretVersion != secretVersion || existing.condition.Reason == ClusterReasonConnectionFailed)
+	if reconnect {
+		gateways := existing.gateways
+		f.stop(ctx, spec.Name)
+		f.start(spec, secretVersion, config).gateways = gateways
+	} else if !exists {
+		f.start(spec, secretVersion, config)
+	}
+
+	cluster := f.clusters[spec.Name]
+	cluster.gateways[gatewayName] = true
+	return cluster.condition
+}
+
+// loadKubeconfig returns the client config of a cluster and the resource version of its Secret.
+// The Secret must be in the gateway's namespace or the operator's namespace.
+func (f *Federation) loadKubeconfig(
+	ctx context.Context,
+	namespace string,
+	spec fetchfyv1alpha2.FederatedCluster,
+) (*rest.Config, string, error) {
+	secretName := types.NamespacedName{
+		Name:      spec.KubeconfigSecretRef.Name,
+		Namespace: spec.KubeconfigSecretRef.Namespace,
+	}
+	if secretName.Namespace != namespace && (f.namespace == "" || secretName.Namespace != f.namespace) {
+		return nil, "", fmt.Errorf("kubeconfig Secret %s is outside the Gateway's namespace", secretName)
+	}
+	secret := &corev1.Secret{}
+	if err := f.reader.Get(ctx, secretName, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, "", fmt.Errorf("kubeconfig Secret %s not found", secretName)
+		}
+		return nil, "", fmt.Errorf("failed to get kubeconfig Secret %s: %w", secretName, err)
+	}
+
+	data, ok := secret.Data[spec.KubeconfigKey]
+	if !ok {
+		return nil, "", fmt.Errorf("kubeconfig Secret %s has no key %q", secretName, spec.KubeconfigKey)
+	}
+	kubeconfig, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("kubeconfig in Secret %s is invalid: %w", secretName, err)
+	}
+	if err := checkKubeconfig(kubeconfig); err != nil {
+		return nil, "", fmt.Errorf("kubeconfig in Secret %s is not supported: %w", secretName, err)
+	}
+	config, err := clientcmd.NewDefaultClientConfig(*kubeconfig, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("kubeconfig in Secret %s is invalid: %w", secretName, err)
+	}
+	return config, secret.ResourceVersion, nil
+}
+
+// checkKubeconfig rejects kubeconfigs that run commands or read files in the operator's pod.
+// Their credentials must be embedded in the kubeconfig.
+func checkKubeconfig(kubeconfig *clientcmdapi.Config) error {
+	for name, user := range kubeconfig.AuthInfos {
+		switch {
+		case user.Exec != nil:
+			return fmt.Errorf("user %q runs an exec credential plugin", name)
+		case user.AuthProvider != nil:
+			return fmt.Errorf("user %q uses an auth provider", name)
+		case user.TokenFile != "" || user.ClientCertificate != "" || user.ClientKey != "":
+			return fmt.Errorf("user %q reads its credentials from files", name)
+		}
+	}
+	for name, cluster := range kubeconfig.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return fmt.Errorf("cluster %q reads its certificate authority from a file", name)
+		}
+	}
+	return nil
+}
+
+// start connects to a cluster. It must be called with the lock held.
+func (f *Federation) start(
+	spec fetchfyv1alpha2.FederatedCluster,
+	secretVersion string,
+	config *rest.Config,
+) *federatedCluster {
+	ctx, cancel := context.WithCancel(context.Background())
+	cluster := &federatedCluster{
+		spec:          spec,
+		secretVersion: secretVersion,
+		gateways:      make(map[types.NamespacedName]bool),
+		cancel:        cancel,
+		condition: clusterCondition(metav1.ConditionFalse, ClusterReasonConnecting,
+			"Waiting for the cluster's Services to be listed"),
+	}
+	f.clusters[spec.Name] = cluster
+
+	remote := mcp.RemoteCluster{Name: spec.Name, Address: spec.Address}
+	log := f.log.WithValues("cluster", spec.Name)
+	log.Info("Connecting to federated cluster", "address", spec.Address, "server", config.Host)
+
+	go func() {
+		err := f.connect(ctx, remote, config, func() {
+			f.setCondition(cluster, clusterCondition(metav1.ConditionTrue, ClusterReasonConnected,
+				"The cluster's MCP-enabled Services are registered"))
+			log.Info("Connected to federated cluster")
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Error(err, "Failed to watch federated cluster")
+			f.setCondition(cluster,
+				clusterCondition(metav1.ConditionFalse, ClusterReasonConnectionFailed, err.Error()))
+		}
+	}()
+
+	return cluster
+}
+
+// setCondition sets the state of a cluster's connection
+func (f *Federation) setCondition(cluster *federatedCluster, condition metav1.Condition) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	cluster.condition = condition
+}
+
+// release drops a gateway from a cluster and stops watching the cluster if no gateway is left.
+// It must be called with the lock held.
+func (f *Federation) release(ctx context.Context, name string, gatewayName types.NamespacedName) {
+	cluster := f.clusters[name]
+	delete(cluster.gateways, gatewayName)
+	if len(cluster.gateways) == 0 {
+		f.stop(ctx, name)
+	}
+}
+
+// stop stops watching a cluster and deregisters its services. It must be called with the lock held.
+func (f *Federation) stop(ctx context.Context, name string) {
+	f.clusters[name].cancel()
+	delete(f.clusters, name)
+	removed := f.registry.DeregisterCluster(ctx, name)
+	f.log.Info("Stopped watching federated cluster", "cluster", name, "services", removed)
+}
+
+// countServices returns the number of registered services of a cluster
+func (f *Federation) countServices(name string) int32 {
+	var count int32
+	for _, svc := range f.registry.ListServices() {
+		if svc.Cluster != nil && svc.Cluster.Name == name {
+			count++
+		}
+	}
+	return count
+}
+
+// watchCluster runs a service watcher against a remote cluster until the context is done.
+// Only the cluster's MCP-enabled Services are cached.
+func (f *Federation) watchCluster(
+	ctx context.Context,
+	remote mcp.RemoteCluster,
+	config *rest.Config,
+	synced func(),
+) error {
+	if f.mgr == nil {
+		return fmt.Errorf("federation isn't set up with a manager")
+	}
+
+	cluster, err := ctrlcluster.New(config, func(o *ctrlcluster.Options) {
+		o.Scheme = f.scheme
+		o.Cache.ByObject = map[client.Object]cache.ByObject{
+			&corev1.Service{}: {Label: labels.SelectorFromSet(labels.Set{MCPEnabledLabel: "true"})},
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	watcher := NewRemoteServiceWatcher(cluster.GetClient(), f.registry, f.log, f.scheme, remote)
+	skipNameValidation := true
+	c, err := controller.NewUnmanaged("federated-service-"+remote.Name, f.mgr, controller.Options{
+		Reconciler:         watcher,
+		SkipNameValidation: &skipNameValidation,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.Watch(source.Kind(cluster.GetCache(), client.Object(&corev1.Service{}),
+		&handler.EnqueueRequestForObject{}, watcher.predicate)); err != nil {
+		return err
+	}
+	if err := c.Watch(source.Kind(cluster.GetCache(), client.Object(&discoveryv1.EndpointSlice{}),
+		handler.EnqueueRequestsFromMapFunc(watcher.serviceForEndpointSlice))); err != nil {
+		return err
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- cluster.Start(ctx)
+	}()
+	go func() {
+		errs <- c.Start(ctx)
+	}()
+
+	// Listing the Services blocks until the controller's informers are synced
+	services := &corev1.ServiceList{}
+	if err := cluster.GetClient().List(ctx, services); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	for _, service := range services.Items {
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&service)}
+		if _, err := watcher.Reconcile(ctx, req); err != nil {
+			return err
+		}
+	}
+	synced()
+
+	return <-errs
+}
+
+// withClusterDefaults returns the cluster spec with the Secret namespace and key defaulted
+func withClusterDefaults(
+	spec fetchfyv1alpha2.FederatedCluster,
+	namespace string,
+) fetchfyv1alpha2.FederatedCluster {
+	if spec.KubeconfigSecretRef.Namespace == "" {
+		spec.KubeconfigSecretRef.Namespace = namespace
+	}
+	if spec.KubeconfigKey == "" {
+		spec.KubeconfigKey = fetchfyv1alpha2.DefaultKubeconfigKey
+	}
+	return spec
+}
+
+// clusterCondition returns the Ready condition of a federated cluster
+func clusterCondition(status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               ClusterConditionReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+}
+
+// ClusterReady reports whether a federated cluster is connected
+func ClusterReady(status fetchfyv1alpha2.FederatedClusterStatus) bool {
+	return meta.IsStatusConditionTrue(status.Conditions, ClusterConditionReady)
+}
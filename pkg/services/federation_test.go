@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: east
+  cluster:
+    server: https://east.example.com:6443
+contexts:
+- name: east
+  context:
+    cluster: east
+    user: fetchfy
+current-context: east
+users:
+- name: fetchfy
+  user:
+    token: secret
+`
+
+var _ = Describe("Federation", func() {
+	var (
+		ctx        context.Context
+		local      client.Client
+		remote     client.Client
+		registry   *mcp.Registry
+		federation *Federation
+		servers    chan string
+	)
+
+	newGateway := func(name string, clusters ...fetchfyv1alpha2.FederatedCluster) *fetchfyv1alpha2.Gateway {
+		return &fetchfyv1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       fetchfyv1alpha2.GatewaySpec{Federation: &fetchfyv1alpha2.GatewayFederation{Clusters: clusters}},
+		}
+	}
+	east := fetchfyv1alpha2.FederatedCluster{
+		Name:                "east",
+		KubeconfigSecretRef: fetchfyv1alpha2.SecretReference{Name: "east-kubeconfig"},
+		Address:             "https://mcp.east.example.com",
+	}
+	readyReason := func(status fetchfyv1alpha2.FederatedClusterStatus) string {
+		return status.Conditions[0].Reason
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		local = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "east-kubeconfig", Namespace: "default"},
+			Data:       map[string][]byte{fetchfyv1alpha2.DefaultKubeconfigKey: []byte(testKubeconfig)},
+		}).Build()
+		remote = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "weather",
+				Namespace:   "tools",
+				Labels:      map[string]string{MCPEnabledLabel: "true"},
+				Annotations: map[string]string{MCPTypeAnnotation: string(mcp.ServiceTypeTool)},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}).Build()
+		registry = mcp.NewRegistry(logr.Discard())
+		servers = make(chan string, 10)
+
+		federation = NewFederation(local, registry, logr.Discard(), scheme)
+		federation.SetConnectFunc(func(ctx context.Context, cluster mcp.RemoteCluster, config *rest.Config,
+			synced func()) error {
+			servers <- config.Host
+			watcher := NewRemoteServiceWatcher(remote, registry, logr.Discard(), scheme, cluster)
+			if _, err := watcher.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{
+				Name: "weather", Namespace: "tools"}}); err != nil {
+				return err
+			}
+			synced()
+			<-ctx.Done()
+			return nil
+		})
+	})
+
+	It("should register the services of federated clusters until no gateway federates them", func() {
+		gateway := newGateway("gw", east)
+		Eventually(func() string {
+			gateway.Status.Clusters = federation.Sync(ctx, gateway)
+			return readyReason(gateway.Status.Clusters[0])
+		}).Should(Equal(ClusterReasonConnected))
+		Expect(servers).To(Receive(Equal("https://east.example.com:6443")))
+		Expect(gateway.Status.Clusters[0].Services).To(Equal(int32(1)))
+
+		registered, ok := registry.GetService(types.NamespacedName{Name: "east.weather", Namespace: "tools"})
+		Expect(ok).To(BeTrue())
+		Expect(registered.Target.URL().String()).To(Equal("https://mcp.east.example.com:443"))
+
+		By("sharing the cluster with another gateway")
+		other := newGateway("other", east)
+		Expect(readyReason(federation.Sync(ctx, other)[0])).To(Equal(ClusterReasonConnected))
+		Expect(servers).NotTo(Receive())
+
+		federation.RemoveGateway(ctx, types.NamespacedName{Name: "gw", Namespace: "default"})
+		Expect(federation.Clusters()).To(ConsistOf("east"))
+		federation.RemoveGateway(ctx, types.NamespacedName{Name: "other", Namespace: "default"})
+		Expect(federation.Clusters()).To(BeEmpty())
+		_, ok = registry.GetService(types.NamespacedName{Name: "east.weather", Namespace: "tools"})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should report clusters that can't be federated", func() {
+		missing := east
+		missing.Name = "west"
+		missing.KubeconfigSecretRef.Name = "west-kubeconfig"
+		statuses := federation.Sync(ctx, newGateway("gw", east, missing))
+		Expect(readyReason(statuses[1])).To(Equal(ClusterReasonKubeconfigError))
+		Expect(statuses[1].Conditions[0].Message).To(ContainSubstring("default/west-kubeconfig not found"))
+
+		By("rejecting Secrets of other namespaces")
+		foreign := east
+		foreign.Name = "south"
+		foreign.KubeconfigSecretRef.Namespace = "operator"
+		statuses = federation.Sync(ctx, newGateway("gw", east, foreign))
+		Expect(readyReason(statuses[1])).To(Equal(ClusterReasonKubeconfigError))
+		Expect(statuses[1].Conditions[0].Message).To(ContainSubstring("outside the Gateway's namespace"))
+		federation.SetOperatorNamespace("operator")
+		statuses = federation.Sync(ctx, newGateway("gw", east, foreign))
+		Expect(statuses[1].Conditions[0].Message).To(ContainSubstring("operator/east-kubeconfig not found"))
+
+		By("rejecting other settings for a cluster of the same name")
+		conflicting := east
+		conflicting.Address = "https://other.example.com"
+		statuses = federation.Sync(ctx, newGateway("other", conflicting))
+		Expect(readyReason(statuses[0])).To(Equal(ClusterReasonConflict))
+	})
+
+	It("should reject kubeconfigs that run commands or read files", func() {
+		for name, user := range map[string]string{
+			"exec":          "exec:\n      apiVersion: client.authentication.k8s.io/v1\n      command: /bin/sh",
+			"auth-provider": "auth-provider:\n      name: oidc",
+			"token-file":    "tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token",
+		} {
+			kubeconfig := strings.Replace(testKubeconfig, "token: secret", user, 1)
+			Expect(local.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Data:       map[string][]byte{fetchfyv1alpha2.DefaultKubeconfigKey: []byte(kubeconfig)},
+			})).To(Succeed())
+			cluster := east
+			cluster.KubeconfigSecretRef.Name = name
+			statuses := federation.Sync(ctx, newGateway("gw", cluster))
+			Expect(readyReason(statuses[0])).To(Equal(ClusterReasonKubeconfigError), name)
+			Expect(statuses[0].Conditions[0].Message).To(ContainSubstring("is not supported"), name)
+		}
+		Expect(servers).NotTo(Receive())
+	})
+})
@@ -19,19 +19,26 @@ package services
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
-	fetchfyv1alpha1 "github.com/fetchfy/fetchfy-operator/api/v1alpha1"
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
 	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
 )
 
@@ -41,8 +48,52 @@ const (
 
 	// MCPTypeAnnotation is the annotation that specifies the MCP service type
 	MCPTypeAnnotation = "mcp.fetchfy.ai/type"
+
+	// MCPEndpointAnnotation is the annotation that overrides the MCP endpoint path
+	MCPEndpointAnnotation = mcp.EndpointAnnotation
+
+	// MCPDescriptionAnnotation is the annotation that holds a human-readable description of the service
+	MCPDescriptionAnnotation = "mcp.fetchfy.ai/description"
+
+	// MCPVersionAnnotation is the annotation that holds the version of the service
+	MCPVersionAnnotation = "mcp.fetchfy.ai/version"
+
+	// MCPTimeoutAnnotation is the annotation that sets the request timeout in seconds
+	MCPTimeoutAnnotation = "mcp.fetchfy.ai/timeout"
+
+	// MCPCacheTTLAnnotation is the annotation that enables caching of list method responses for a duration
+	MCPCacheTTLAnnotation = mcp.CacheTTLAnnotation
+
+	// MCPCacheResourcesAnnotation is the annotation that lists the URI prefixes of resources whose reads are cached
+	MCPCacheResourcesAnnotation = mcp.CacheResourcesAnnotation
+
+	// MCPPortAnnotation is the annotation that selects the Service port by name or number
+	MCPPortAnnotation = mcp.PortAnnotation
+
+	// MCPSchemeAnnotation is the annotation that sets the scheme of forwarded requests
+	MCPSchemeAnnotation = mcp.SchemeAnnotation
+
+	// MCPBasePathAnnotation is the annotation that sets the path the backend serves MCP on
+	MCPBasePathAnnotation = mcp.BasePathAnnotation
 )
 
+// ParseServiceType returns the MCP service type of a service. Services without the type
+// annotation are tools; an unknown type is returned as an error together with the tool fallback.
+func ParseServiceType(svc *corev1.Service) (mcp.ServiceType, error) {
+	typeStr, exists := svc.Annotations[MCPTypeAnnotation]
+	if !exists {
+		return mcp.ServiceTypeTool, nil
+	}
+
+	switch serviceType := mcp.ServiceType(typeStr); serviceType {
+	case mcp.ServiceTypeTool, mcp.ServiceTypeAgent:
+		return serviceType, nil
+	default:
+		return mcp.ServiceTypeTool, fmt.Errorf("unknown %s %q, must be %q or %q",
+			MCPTypeAnnotation, typeStr, mcp.ServiceTypeTool, mcp.ServiceTypeAgent)
+	}
+}
+
 // IsMCPEnabledService checks if a service is MCP-enabled
 func IsMCPEnabledService(obj client.Object) bool {
 	svc, ok := obj.(*corev1.Service)
@@ -67,11 +118,19 @@ type ServiceWatcher struct {
 	client    client.Client
 	log       logr.Logger
 	registry  *mcp.Registry
-	gateways  map[types.NamespacedName]*fetchfyv1alpha1.Gateway
 	scheme    *runtime.Scheme
 	predicate predicate.Predicate
+
+	// cluster is the remote cluster the client reads from, nil for the local cluster
+	cluster *mcp.RemoteCluster
+
+	// synced is set once the services present at startup are registered
+	synced atomic.Bool
 }
 
+// initialSyncRetry is the delay between attempts to register the services present at startup
+const initialSyncRetry = time.Second
+
 // NewServiceWatcher creates a new service watcher
 func NewServiceWatcher(
 	client client.Client,
@@ -84,7 +143,6 @@ func NewServiceWatcher(
 		client:   client,
 		registry: registry,
 		log:      log.WithName("service-watcher"),
-		gateways: make(map[types.NamespacedName]*fetchfyv1alpha1.Gateway),
 		scheme:   scheme,
 	}
 
@@ -104,22 +162,45 @@ func NewServiceWatcher(
 	return sw
 }
 
-// IsMCPEnabledService checks if a service is MCP-enabled
-func IsMCPEnabledService(obj client.Object) bool {
-	svc, ok := obj.(*corev1.Service)
-	if !ok {
-		return false
-	}
+// NewRemoteServiceWatcher creates a service watcher for a remote cluster. The client reads
+// from the cluster, whose services are registered with cluster-qualified names.
+func NewRemoteServiceWatcher(
+	client client.Client,
+	registry *mcp.Registry,
+	log logr.Logger,
+	scheme *runtime.Scheme,
+	cluster mcp.RemoteCluster,
+) *ServiceWatcher {
+	sw := NewServiceWatcher(client, registry, log.WithValues("cluster", cluster.Name), scheme)
+	sw.cluster = &cluster
+	return sw
+}
 
-	if svc.Labels == nil {
-		return false
+// registryName returns the name of a service in the registry
+func (sw *ServiceWatcher) registryName(name types.NamespacedName) types.NamespacedName {
+	if sw.cluster != nil {
+		name.Name = mcp.ClusterServiceName(sw.cluster.Name, name.Name)
 	}
+	return name
+}
 
-	if val, exists := svc.Labels[MCPEnabledLabel]; exists && val == "true" {
-		return true
+// register registers a service with the registry. Nil endpoints keep the registered counts.
+func (sw *ServiceWatcher) register(
+	ctx context.Context,
+	service *corev1.Service,
+	serviceType mcp.ServiceType,
+	endpoints *mcp.Endpoints,
+) error {
+	var err error
+	switch {
+	case sw.cluster != nil:
+		_, err = sw.registry.RegisterRemoteService(ctx, *sw.cluster, service, serviceType, endpoints)
+	case endpoints == nil:
+		_, err = sw.registry.RegisterService(ctx, service, serviceType)
+	default:
+		_, err = sw.registry.RegisterServiceWithEndpoints(ctx, service, serviceType, *endpoints)
 	}
-
-	return false
+	return err
 }
 
 // isMCPEnabledService checks if a service is MCP-enabled (internal method)
@@ -127,24 +208,93 @@ func (sw *ServiceWatcher) isMCPEnabledService(obj client.Object) bool {
 	return IsMCPEnabledService(obj)
 }
 
-// SetupWithManager sets up the service watcher with the manager
+// AllReplicas returns the options of controllers that run on every replica rather than only
+// on the leader. Every replica serves the gateways, so each keeps its own registry up to date.
+func AllReplicas() controller.Options {
+	needLeaderElection := false
+	return controller.Options{NeedLeaderElection: &needLeaderElection}
+}
+
+// SetupWithManager sets up the service watcher with the manager. Changes to the EndpointSlices
+// of registered services update their endpoint counts.
 func (sw *ServiceWatcher) SetupWithManager(mgr ctrl.Manager) error {
+	// Register the services present at startup to tell when the registry is synced
+	if err := mgr.Add(sw); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Service{}).
-		WithEventFilter(sw.predicate).
+		WithOptions(AllReplicas()).
+		For(&corev1.Service{}, builder.WithPredicates(sw.predicate)).
+		Watches(&discoveryv1.EndpointSlice{}, handler.EnqueueRequestsFromMapFunc(sw.serviceForEndpointSlice)).
 		Complete(sw)
 }
 
+// NeedLeaderElection returns false, as every replica keeps its own registry. It implements
+// manager.LeaderElectionRunnable.
+func (sw *ServiceWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start registers the MCP-enabled services present when the operator starts and marks the
+// watcher synced, retrying until it succeeds. It implements manager.Runnable.
+func (sw *ServiceWatcher) Start(ctx context.Context) error {
+	for {
+		err := sw.registerAll(ctx)
+		if err == nil {
+			sw.synced.Store(true)
+			sw.log.Info("Registered the MCP services present at startup")
+			return nil
+		}
+		sw.log.Error(err, "Failed to register the MCP services present at startup, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(initialSyncRetry):
+		}
+	}
+}
+
+// registerAll reconciles every MCP-enabled service once
+func (sw *ServiceWatcher) registerAll(ctx context.Context) error {
+	services := &corev1.ServiceList{}
+	if err := sw.client.List(ctx, services, client.MatchingLabels{MCPEnabledLabel: "true"}); err != nil {
+		return err
+	}
+	for _, service := range services.Items {
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&service)}
+		if _, err := sw.Reconcile(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Synced reports whether the services present at startup are registered
+func (sw *ServiceWatcher) Synced() bool {
+	return sw.synced.Load()
+}
+
+// CheckSynced is a readiness check that fails until the services present at startup are
+// registered. It has the signature of healthz.Checker.
+func (sw *ServiceWatcher) CheckSynced(_ *http.Request) error {
+	if !sw.Synced() {
+		return fmt.Errorf("MCP services are not registered yet")
+	}
+	return nil
+}
+
 // Reconcile handles service reconciliation
 func (sw *ServiceWatcher) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := sw.log.WithValues("service", req.NamespacedName)
+	name := sw.registryName(req.NamespacedName)
 
 	// Fetch the service
 	var service corev1.Service
 	if err := sw.client.Get(ctx, req.NamespacedName, &service); err != nil {
 		if errors.IsNotFound(err) {
 			// Service deleted, deregister it
-			if sw.registry.DeregisterService(ctx, req.NamespacedName) {
+			if sw.registry.DeregisterService(ctx, name) {
 				log.Info("Deregistered service from MCP registry")
 			}
 			return ctrl.Result{}, nil
@@ -156,93 +306,87 @@ func (sw *ServiceWatcher) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	// Check if the service is MCP-enabled
 	if !sw.isMCPEnabledService(&service) {
 		// Service is not MCP-enabled, deregister it if it was previously registered
-		if sw.registry.DeregisterService(ctx, req.NamespacedName) {
+		if sw.registry.DeregisterService(ctx, name) {
 			log.Info("Deregistered non-MCP service from registry")
 		}
 		return ctrl.Result{}, nil
 	}
 
 	// Determine service type from annotation
-	serviceType := mcp.ServiceTypeTool // Default type
-	if service.Annotations != nil {
-		if typeStr, exists := service.Annotations[MCPTypeAnnotation]; exists {
-			if typeStr == string(mcp.ServiceTypeAgent) {
-				serviceType = mcp.ServiceTypeAgent
-			}
+	serviceType, err := ParseServiceType(&service)
+	if err != nil {
+		log.Error(err, "Invalid MCP service type, registering as tool")
+	}
+
+	// ExternalName services have no endpoints and are registered as they are
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		if err := sw.register(ctx, &service, serviceType, nil); err != nil {
+			log.Error(err, "Failed to register MCP service")
+			return ctrl.Result{}, err
 		}
+		log.Info("Registered MCP service", "type", serviceType, "externalName", service.Spec.ExternalName)
+		return ctrl.Result{}, nil
 	}
 
-	// Register the service
-	_, err := sw.registry.RegisterService(ctx, &service, serviceType)
+	// Count the ready endpoints, which decide whether the service is pending
+	endpoints, err := sw.serviceEndpoints(ctx, &service)
 	if err != nil {
-		log.Error(err, "Failed to register MCP service")
+		log.Error(err, "Failed to list EndpointSlices")
 		return ctrl.Result{}, err
 	}
 
-	log.Info("Registered MCP service", "type", serviceType)
+	// Register the service
+	if err := sw.register(ctx, &service, serviceType, &endpoints); err != nil {
+		log.Error(err, "Failed to register MCP service")
+		return ctrl.Result{}, err
+	}
 
-	// Update all gateway statuses
-	sw.updateAllGatewayStatuses(ctx)
+	log.Info("Registered MCP service", "type", serviceType, "readyEndpoints", endpoints.Ready)
 
 	return ctrl.Result{}, nil
 }
 
-// AddGateway adds a gateway to be tracked by the watcher
-func (sw *ServiceWatcher) AddGateway(gateway *fetchfyv1alpha1.Gateway) {
-	key := types.NamespacedName{
-		Name:      gateway.Name,
-		Namespace: gateway.Namespace,
+// DiscoveryNamespaces resolves the namespaces the gateway discovers services in from its
+// discovery settings. It returns nil when the gateway discovers services in all namespaces,
+// and an error if the gateway's selectors are invalid.
+func (sw *ServiceWatcher) DiscoveryNamespaces(ctx context.Context, gateway *fetchfyv1alpha2.Gateway) ([]string, error) {
+	discovery := gateway.Spec.Discovery
+	if discovery == nil {
+		return nil, nil
 	}
-	sw.gateways[key] = gateway
-	sw.log.Info("Added gateway to service watcher",
-		"gateway", fmt.Sprintf("%s/%s", gateway.Namespace, gateway.Name))
-}
 
-// RemoveGateway removes a gateway from being tracked
-func (sw *ServiceWatcher) RemoveGateway(name types.NamespacedName) {
-	delete(sw.gateways, name)
-	sw.log.Info("Removed gateway from service watcher",
-		"gateway", fmt.Sprintf("%s/%s", name.Namespace, name.Name))
-}
+	if discovery.ServiceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(discovery.ServiceSelector); err != nil {
+			return nil, err
+		}
+	}
 
-// GetMatchingServices returns all services that match the gateway's selector
-func (sw *ServiceWatcher) GetMatchingServices(ctx context.Context, gateway *fetchfyv1alpha1.Gateway) ([]corev1.Service, error) {
-	serviceList := &corev1.ServiceList{}
+	if discovery.NamespaceSelector == nil {
+		if len(discovery.Namespaces) == 0 {
+			return nil, nil
+		}
+		return discovery.Namespaces, nil
+	}
 
-	selector, err := metav1.LabelSelectorAsSelector(&gateway.Spec.ServiceSelector)
+	selector, err := metav1.LabelSelectorAsSelector(discovery.NamespaceSelector)
 	if err != nil {
 		return nil, err
 	}
-
-	listOpts := &client.ListOptions{
-		LabelSelector: selector,
-	}
-
-	if err := sw.client.List(ctx, serviceList, listOpts); err != nil {
+	namespaceList := &corev1.NamespaceList{}
+	if err := sw.client.List(ctx, namespaceList, &client.ListOptions{LabelSelector: selector}); err != nil {
 		return nil, err
 	}
 
-	return serviceList.Items, nil
-}
-
-// updateAllGatewayStatuses updates the status of all tracked gateways
-func (sw *ServiceWatcher) updateAllGatewayStatuses(ctx context.Context) {
-	for key, _ := range sw.gateways {
-		// Fetch the latest gateway
-		var currentGateway fetchfyv1alpha1.Gateway
-		if err := sw.client.Get(ctx, key, &currentGateway); err != nil {
-			sw.log.Error(err, "Failed to fetch gateway for status update",
-				"gateway", fmt.Sprintf("%s/%s", key.Namespace, key.Name))
-			continue
-		}
-
-		// Update the gateway status with the registered services
-		sw.registry.UpdateRegistryStatus(&currentGateway)
+	listed := make(map[string]bool, len(discovery.Namespaces))
+	for _, namespace := range discovery.Namespaces {
+		listed[namespace] = true
+	}
 
-		// Update the gateway status
-		if err := sw.client.Status().Update(ctx, &currentGateway); err != nil {
-			sw.log.Error(err, "Failed to update gateway status",
-				"gateway", fmt.Sprintf("%s/%s", key.Namespace, key.Name))
+	namespaces := []string{}
+	for _, namespace := range namespaceList.Items {
+		if len(listed) == 0 || listed[namespace.Name] {
+			namespaces = append(namespaces, namespace.Name)
 		}
 	}
+	return namespaces, nil
 }
@@ -0,0 +1,249 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+)
+
+var _ = Describe("ServiceWatcher", func() {
+	var (
+		ctx      context.Context
+		c        client.Client
+		registry *mcp.Registry
+		watcher  *ServiceWatcher
+	)
+
+	newService := func(namespace, name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Labels:      map[string]string{MCPEnabledLabel: "true"},
+				Annotations: map[string]string{MCPTypeAnnotation: string(mcp.ServiceTypeTool)},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}
+	}
+	newGateway := func(name string) *fetchfyv1alpha2.Gateway {
+		return &fetchfyv1alpha2.Gateway{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(fetchfyv1alpha2.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		registry = mcp.NewRegistry(logr.Discard())
+		watcher = NewServiceWatcher(c, registry, logr.Discard(), scheme)
+	})
+
+	It("should register and deregister MCP-enabled Services", func() {
+		svc := newService("tools", "weather")
+		Expect(c.Create(ctx, svc)).To(Succeed())
+		name := types.NamespacedName{Name: "weather", Namespace: "tools"}
+
+		_, err := watcher.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+		Expect(err).NotTo(HaveOccurred())
+		registered, ok := registry.GetService(name)
+		Expect(ok).To(BeTrue())
+		Expect(registered.Type).To(Equal(mcp.ServiceTypeTool))
+
+		svc.Labels[MCPEnabledLabel] = "false"
+		Expect(c.Update(ctx, svc)).To(Succeed())
+		_, err = watcher.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+		Expect(err).NotTo(HaveOccurred())
+		_, ok = registry.GetService(name)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should track the ready endpoints of registered Services", func() {
+		Expect(c.Create(ctx, newService("tools", "weather"))).To(Succeed())
+		name := types.NamespacedName{Name: "weather", Namespace: "tools"}
+		slice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "weather-abc12",
+				Namespace: "tools",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "weather"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(false)}},
+			},
+		}
+		Expect(c.Create(ctx, slice)).To(Succeed())
+
+		_, err := watcher.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+		Expect(err).NotTo(HaveOccurred())
+		registered, _ := registry.GetService(name)
+		Expect(registered.Status).To(Equal(mcp.ServiceStatusPending))
+		Expect(registered.Endpoints).To(Equal(&mcp.Endpoints{Ready: 0, Total: 1}))
+
+		By("mapping the EndpointSlice to the registered Service")
+		Expect(watcher.serviceForEndpointSlice(ctx, slice)).To(ConsistOf(ctrl.Request{NamespacedName: name}))
+
+		slice.Endpoints[0].Conditions.Ready = boolPtr(true)
+		Expect(c.Update(ctx, slice)).To(Succeed())
+		_, err = watcher.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+		Expect(err).NotTo(HaveOccurred())
+		registered, _ = registry.GetService(name)
+		Expect(registered.Status).To(Equal(mcp.ServiceStatusAvailable))
+		Expect(registered.Endpoints.Ready).To(Equal(int32(1)))
+	})
+
+	It("should register the Services present at startup before reporting synced", func() {
+		Expect(c.Create(ctx, newService("tools", "weather"))).To(Succeed())
+		other := newService("tools", "legacy")
+		other.Labels = nil
+		Expect(c.Create(ctx, other)).To(Succeed())
+		Expect(watcher.NeedLeaderElection()).To(BeFalse())
+		Expect(watcher.CheckSynced(nil)).To(HaveOccurred())
+
+		Expect(watcher.Start(ctx)).To(Succeed())
+		Expect(watcher.Synced()).To(BeTrue())
+		Expect(watcher.CheckSynced(nil)).To(Succeed())
+		_, ok := registry.GetService(types.NamespacedName{Name: "weather", Namespace: "tools"})
+		Expect(ok).To(BeTrue())
+		_, ok = registry.GetService(types.NamespacedName{Name: "legacy", Namespace: "tools"})
+		Expect(ok).To(BeFalse())
+	})
+
+	DescribeTable("should count endpoints",
+		func(endpoints []discoveryv1.Endpoint, ready, total int32) {
+			slices := []discoveryv1.EndpointSlice{{Endpoints: endpoints}}
+			Expect(CountEndpoints(slices)).To(Equal(mcp.Endpoints{Ready: ready, Total: total}))
+		},
+		Entry("without endpoints", nil, int32(0), int32(0)),
+		Entry("with unknown readiness", []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}}, int32(1), int32(1)),
+		Entry("without terminating endpoints", []discoveryv1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(true)}},
+			{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{
+				Ready: boolPtr(false), Terminating: boolPtr(true)}},
+			{Addresses: []string{"10.0.0.3"}, Conditions: discoveryv1.EndpointConditions{Ready: boolPtr(false)}},
+		}, int32(1), int32(2)),
+		Entry("once per pod", []discoveryv1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}, TargetRef: &corev1.ObjectReference{UID: "pod-1"}},
+			{Addresses: []string{"fd00::1"}, TargetRef: &corev1.ObjectReference{UID: "pod-1"}},
+		}, int32(1), int32(1)),
+	)
+
+	It("should resolve the discovery namespaces of gateways", func() {
+		for name, team := range map[string]string{"tools": "a", "prompts": "a", "other": "b"} {
+			Expect(c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"team": team},
+			}})).To(Succeed())
+		}
+
+		gateway := newGateway("gw")
+		Expect(watcher.DiscoveryNamespaces(ctx, gateway)).To(BeNil())
+
+		gateway.Spec.Discovery = &fetchfyv1alpha2.GatewayDiscovery{Namespaces: []string{"tools"}}
+		Expect(watcher.DiscoveryNamespaces(ctx, gateway)).To(Equal([]string{"tools"}))
+
+		gateway.Spec.Discovery.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+		Expect(watcher.DiscoveryNamespaces(ctx, gateway)).To(Equal([]string{"tools"}))
+
+		gateway.Spec.Discovery.Namespaces = nil
+		Expect(watcher.DiscoveryNamespaces(ctx, gateway)).To(ConsistOf("tools", "prompts"))
+
+		gateway.Spec.Discovery.ServiceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "app", Operator: "Invalid"},
+		}}
+		_, err := watcher.DiscoveryNamespaces(ctx, gateway)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should handle concurrent gateway and service churn", func() {
+		const workers = 8
+		const rounds = 20
+
+		servers := mcp.NewServerManager(registry, logr.Discard())
+
+		var wg sync.WaitGroup
+		for w := range workers {
+			wg.Add(2)
+
+			// Gateway reconciler: configure the gateway's server and list its services
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := range rounds {
+					gateway := newGateway(fmt.Sprintf("gw-%d", (w+i)%3))
+					namespaces, err := watcher.DiscoveryNamespaces(ctx, gateway)
+					Expect(err).NotTo(HaveOccurred())
+					if i%4 == 3 {
+						_, err := servers.Remove(ctx, client.ObjectKeyFromObject(gateway))
+						Expect(err).NotTo(HaveOccurred())
+						continue
+					}
+					server, _ := servers.GetOrCreate(client.ObjectKeyFromObject(gateway))
+					server.Configure(gateway, namespaces)
+					_ = registry.UpdateRegistryStatus(gateway, namespaces)
+				}
+			}()
+
+			// Service reconciler: create, update and delete Services
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := range rounds {
+					name := types.NamespacedName{Name: fmt.Sprintf("svc-%d-%d", w, i%5), Namespace: "tools"}
+					svc := newService(name.Namespace, name.Name)
+					if err := c.Create(ctx, svc); err != nil {
+						Expect(c.Delete(ctx, svc)).To(Succeed())
+					}
+					_, err := watcher.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+					Expect(err).NotTo(HaveOccurred())
+					_ = registry.ListServices()
+				}
+			}()
+		}
+		wg.Wait()
+		for _, gateway := range servers.Gateways() {
+			_, err := servers.Remove(ctx, gateway)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		serviceList := &corev1.ServiceList{}
+		Expect(c.List(ctx, serviceList)).To(Succeed())
+		Expect(registry.ListServices()).To(HaveLen(len(serviceList.Items)))
+	})
+})
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	fetchfyv1alpha2 "github.com/fetchfy/fetchfy-operator/api/v1alpha2"
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+)
+
+// mcpEnabledPredicate passes events of objects that carry, or carried, the MCP-enabled label
+var mcpEnabledPredicate = predicate.NewPredicateFuncs(func(obj client.Object) bool {
+	return obj.GetLabels()[MCPEnabledLabel] == "true"
+})
+
+// PodWatcher registers MCP servers running in Pods that carry the MCP-enabled label and the
+// port annotation, e.g. as a sidecar, without a Service of their own
+type PodWatcher struct {
+	client   client.Client
+	log      logr.Logger
+	registry *mcp.Registry
+}
+
+// NewPodWatcher creates a new pod watcher
+func NewPodWatcher(client client.Client, registry *mcp.Registry, log logr.Logger) *PodWatcher {
+	return &PodWatcher{
+		client:   client,
+		registry: registry,
+		log:      log.WithName("pod-watcher"),
+	}
+}
+
+// SetupWithManager sets up the pod watcher with the manager
+func (pw *PodWatcher) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(AllReplicas()).
+		For(&corev1.Pod{}, builder.WithPredicates(predicate.Or(mcpEnabledPredicate, labelRemovedPredicate))).
+		Complete(pw)
+}
+
+// Reconcile registers or deregisters an MCP-enabled pod
+func (pw *PodWatcher) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	name := workloadKey(fetchfyv1alpha2.DiscoverySourcePod, req.NamespacedName)
+	log := pw.log.WithValues("pod", req.NamespacedName)
+
+	var pod corev1.Pod
+	if err := pw.client.Get(ctx, req.NamespacedName, &pod); err != nil {
+		if errors.IsNotFound(err) {
+			if pw.registry.DeregisterService(ctx, name) {
+				log.Info("Deregistered pod from MCP registry")
+			}
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to fetch pod")
+		return ctrl.Result{}, err
+	}
+
+	var endpoints mcp.Endpoints
+	if pod.DeletionTimestamp == nil && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+		endpoints.Total = 1
+		if isPodReady(&pod) {
+			endpoints.Ready = 1
+		}
+	}
+
+	return registerWorkload(ctx, pw.registry, log, fetchfyv1alpha2.DiscoverySourcePod,
+		&pod.ObjectMeta, &pod, pod.Spec.Containers, endpoints)
+}
+
+// DeploymentWatcher registers MCP servers running in the pods of Deployments that carry the
+// MCP-enabled label and the port annotation. Requests are sent to one ready pod of a Deployment.
+type DeploymentWatcher struct {
+	client client.Client
+	// reader lists the pods of Deployments, which don't carry the MCP-enabled label themselves
+	// and therefore aren't in the cache
+	reader   client.Reader
+	log      logr.Logger
+	registry *mcp.Registry
+}
+
+// NewDeploymentWatcher creates a new deployment watcher. The reader lists the pods of
+// Deployments and should read from the API server.
+func NewDeploymentWatcher(
+	client client.Client,
+	reader client.Reader,
+	registry *mcp.Registry,
+	log logr.Logger,
+) *DeploymentWatcher {
+	return &DeploymentWatcher{
+		client:   client,
+		reader:   reader,
+		registry: registry,
+		log:      log.WithName("deployment-watcher"),
+	}
+}
+
+// SetupWithManager sets up the deployment watcher with the manager. The Deployment status
+// changes as its pods become ready, so the pods themselves aren't watched.
+func (dw *DeploymentWatcher) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(AllReplicas()).
+		For(&appsv1.Deployment{}, builder.WithPredicates(predicate.Or(mcpEnabledPredicate, labelRemovedPredicate))).
+		Complete(dw)
+}
+
+// Reconcile registers or deregisters an MCP-enabled deployment
+func (dw *DeploymentWatcher) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	name := workloadKey(fetchfyv1alpha2.DiscoverySourceDeployment, req.NamespacedName)
+	log := dw.log.WithValues("deployment", req.NamespacedName)
+
+	var deployment appsv1.Deployment
+	if err := dw.client.Get(ctx, req.NamespacedName, &deployment); err != nil {
+		if errors.IsNotFound(err) {
+			if dw.registry.DeregisterService(ctx, name) {
+				log.Info("Deregistered deployment from MCP registry")
+			}
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to fetch deployment")
+		return ctrl.Result{}, err
+	}
+
+	// Send requests to the ready pod with the lowest name, which stays the same while it is ready
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		log.Error(err, "Invalid deployment selector")
+		return ctrl.Result{}, nil
+	}
+	pods := &corev1.PodList{}
+	if err := dw.reader.List(ctx, pods, client.InNamespace(deployment.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		log.Error(err, "Failed to list deployment pods")
+		return ctrl.Result{}, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	var endpoints mcp.Endpoints
+	var target *corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded ||
+			pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		endpoints.Total++
+		if isPodReady(pod) {
+			endpoints.Ready++
+			if target == nil {
+				target = pod
+			}
+		}
+	}
+
+	return registerWorkload(ctx, dw.registry, log, fetchfyv1alpha2.DiscoverySourceDeployment,
+		&deployment.ObjectMeta, target, deployment.Spec.Template.Spec.Containers, endpoints)
+}
+
+// labelRemovedPredicate passes updates that remove the MCP-enabled label, so that the
+// workload is deregistered
+var labelRemovedPredicate = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.ObjectOld.GetLabels()[MCPEnabledLabel] == "true"
+	},
+}
+
+// registerWorkload registers a workload as an ExternalName Service of the IP of the pod that
+// serves its requests. The pod is nil while no pod is ready. Workloads that aren't MCP-enabled
+// or lack the port annotation are deregistered.
+func registerWorkload(
+	ctx context.Context,
+	registry *mcp.Registry,
+	log logr.Logger,
+	source fetchfyv1alpha2.DiscoverySource,
+	meta *metav1.ObjectMeta,
+	pod *corev1.Pod,
+	containers []corev1.Container,
+	endpoints mcp.Endpoints,
+) (ctrl.Result, error) {
+	name := workloadKey(source, types.NamespacedName{Name: meta.Name, Namespace: meta.Namespace})
+
+	portSelector, hasPort := meta.Annotations[MCPPortAnnotation]
+	if meta.Labels[MCPEnabledLabel] != "true" || !hasPort {
+		if registry.DeregisterService(ctx, name) {
+			log.Info("Deregistered non-MCP workload from registry")
+		}
+		if meta.Labels[MCPEnabledLabel] == "true" {
+			log.Info("Ignoring MCP-enabled workload without port annotation", "annotation", MCPPortAnnotation)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	svc := WorkloadService(source, meta, pod, containers)
+	serviceType, err := ParseServiceType(svc)
+	if err != nil {
+		log.Error(err, "Invalid MCP service type, registering as tool")
+	}
+
+	if _, err := registry.RegisterWorkload(ctx, source, svc, serviceType, endpoints); err != nil {
+		log.Error(err, "Failed to register MCP workload")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Registered MCP workload", "type", serviceType, "port", portSelector, "readyPods", endpoints.Ready)
+	return ctrl.Result{}, nil
+}
+
+// WorkloadService returns the Service that represents a workload in the registry. It is an
+// ExternalName Service of the pod's IP, with the container port selected by the port
+// annotation. The Service has no ports if the annotation matches no container port, which
+// makes it invalid, and no external name if the pod is nil.
+func WorkloadService(
+	source fetchfyv1alpha2.DiscoverySource,
+	meta *metav1.ObjectMeta,
+	pod *corev1.Pod,
+	containers []corev1.Container,
+) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              mcp.WorkloadName(source, meta.Name),
+			Namespace:         meta.Namespace,
+			UID:               meta.UID,
+			ResourceVersion:   meta.ResourceVersion,
+			Generation:        meta.Generation,
+			CreationTimestamp: meta.CreationTimestamp,
+			Labels:            meta.Labels,
+			Annotations:       meta.Annotations,
+		},
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName},
+	}
+	if pod != nil {
+		svc.Spec.ExternalName = pod.Status.PodIP
+	}
+
+	selector := meta.Annotations[MCPPortAnnotation]
+	if port, ok := containerPort(containers, selector); ok {
+		portName := selector
+		if _, err := strconv.Atoi(selector); err == nil {
+			portName = mcp.MCPPortName
+		}
+		svc.Spec.Ports = []corev1.ServicePort{{Name: portName, Port: port, Protocol: corev1.ProtocolTCP}}
+	}
+	return svc
+}
+
+// containerPort returns the container port selected by name or number. A number needn't be
+// declared by a container.
+func containerPort(containers []corev1.Container, selector string) (int32, bool) {
+	if number, err := strconv.Atoi(selector); err == nil {
+		return int32(number), number > 0 && number < 65536
+	}
+	for _, container := range containers {
+		for _, port := range container.Ports {
+			if port.Name == selector {
+				return port.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// isPodReady reports whether a pod has an IP and its Ready condition is true
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.PodIP == "" {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// workloadKey returns the registry key of a workload
+func workloadKey(source fetchfyv1alpha2.DiscoverySource, name types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{Name: mcp.WorkloadName(source, name.Name), Namespace: name.Namespace}
+}
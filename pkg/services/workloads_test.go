@@ -0,0 +1,124 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fetchfy/fetchfy-operator/pkg/mcp"
+)
+
+var _ = Describe("Workload watchers", func() {
+	var (
+		ctx      context.Context
+		c        client.Client
+		registry *mcp.Registry
+	)
+
+	newPod := func(name, ip string, ready bool, labels map[string]string) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "tools",
+				Labels:      labels,
+				Annotations: map[string]string{MCPPortAnnotation: "mcp"},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8000}}},
+				{Name: "mcp-sidecar", Ports: []corev1.ContainerPort{{Name: "mcp", ContainerPort: 9000}}},
+			}},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				PodIP:      ip,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		registry = mcp.NewRegistry(logr.Discard())
+	})
+
+	It("should register MCP-enabled pods by their port annotation", func() {
+		watcher := NewPodWatcher(c, registry, logr.Discard())
+		pod := newPod("weather", "10.0.0.7", true, map[string]string{MCPEnabledLabel: "true"})
+		Expect(c.Create(ctx, pod)).To(Succeed())
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "weather", Namespace: "tools"}}
+		name := types.NamespacedName{Name: "pod/weather", Namespace: "tools"}
+
+		_, err := watcher.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		registered, ok := registry.GetService(name)
+		Expect(ok).To(BeTrue())
+		Expect(registered.Status).To(Equal(mcp.ServiceStatusAvailable))
+		Expect(registered.Target.URL().String()).To(Equal("http://10.0.0.7:9000"))
+
+		By("deregistering the pod once the label is removed")
+		pod.Labels[MCPEnabledLabel] = "false"
+		Expect(c.Update(ctx, pod)).To(Succeed())
+		_, err = watcher.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		_, ok = registry.GetService(name)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should route deployments to a ready pod", func() {
+		watcher := NewDeploymentWatcher(c, c, registry, logr.Discard())
+		podLabels := map[string]string{"app": "weather"}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "weather",
+				Namespace:   "tools",
+				Labels:      map[string]string{MCPEnabledLabel: "true"},
+				Annotations: map[string]string{MCPPortAnnotation: "9000"},
+			},
+			Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: podLabels}},
+		}
+		Expect(c.Create(ctx, deployment)).To(Succeed())
+		Expect(c.Create(ctx, newPod("weather-a", "10.0.0.7", false, podLabels))).To(Succeed())
+		Expect(c.Create(ctx, newPod("weather-b", "10.0.0.8", true, podLabels))).To(Succeed())
+
+		_, err := watcher.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{
+			Name: "weather", Namespace: "tools"}})
+		Expect(err).NotTo(HaveOccurred())
+		registered, ok := registry.GetService(types.NamespacedName{Name: "deployment/weather", Namespace: "tools"})
+		Expect(ok).To(BeTrue())
+		Expect(registered.Endpoints).To(Equal(&mcp.Endpoints{Ready: 1, Total: 2}))
+		Expect(registered.Target.URL().String()).To(Equal("http://10.0.0.8:9000"))
+	})
+})